@@ -0,0 +1,153 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	flag "github.com/spf13/pflag"
+	"gopkg.in/yaml.v2"
+
+	"github.com/signalfx/splunk-otel-collector/internal/confmapprovider/discovery"
+	"github.com/signalfx/splunk-otel-collector/internal/settings"
+)
+
+const discoverCommand = "discover"
+
+// errRequiredReceiversFailed is returned when a --required receiver was evaluated with a failed status.
+var errRequiredReceiversFailed = errors.New("required receivers failed discovery")
+
+type discoverSettings struct {
+	configDir      string
+	propertiesFile string
+	endpoints      string
+	output         string
+	properties     []string
+	required       []string
+	duration       time.Duration
+}
+
+// runDiscover handles the "discover" command: it runs the discovery observers and receivers for
+// the configured duration and writes a JSON or YAML report of the discovered endpoints to out.
+func runDiscover(ctx context.Context, args []string, out io.Writer) error {
+	ds, err := parseDiscoverArgs(args)
+	if err != nil {
+		return err
+	}
+
+	provider, err := discovery.New()
+	if err != nil {
+		return fmt.Errorf("failed to create discovery provider: %w", err)
+	}
+
+	reportSettings := discovery.ReportSettings{
+		ConfigDir:      ds.configDir,
+		PropertiesFile: ds.propertiesFile,
+		Properties:     ds.properties,
+		Duration:       ds.duration,
+	}
+	var report *discovery.Report
+	if ds.endpoints != "" {
+		var fixtures discovery.EndpointFixtures
+		if fixtures, err = discovery.LoadEndpointFixtures(ds.endpoints); err != nil {
+			return err
+		}
+		report, err = provider.Simulate(ctx, reportSettings, fixtures)
+	} else {
+		report, err = provider.Report(ctx, reportSettings)
+	}
+	if err != nil {
+		return fmt.Errorf("discovery failed: %w", err)
+	}
+
+	if err = writeDiscoveryReport(out, ds.output, report); err != nil {
+		return err
+	}
+
+	if failed := report.FailedReceivers(ds.required); len(failed) > 0 {
+		return fmt.Errorf("%w: %s", errRequiredReceiversFailed, strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+func parseDiscoverArgs(args []string) (*discoverSettings, error) {
+	flagSet := flag.NewFlagSet(discoverCommand, flag.ContinueOnError)
+	ds := &discoverSettings{}
+
+	configDir := settings.DefaultConfigDir
+	if envConfigDir, ok := os.LookupEnv(settings.ConfigDirEnvVar); ok {
+		configDir = envConfigDir
+	}
+	flagSet.StringVar(&ds.configDir, "config-dir", configDir, "The root config.d directory containing .discovery.yaml components.")
+	flagSet.StringVar(&ds.propertiesFile, "discovery-properties", "",
+		"Location to a single discovery properties file. If set, default <config.d>/properties.discovery.yaml content will be disregarded.")
+	flagSet.StringArrayVar(&ds.properties, "set", nil, "Set a discovery property. Example --set=splunk.discovery.receivers.mysql.config.username=admin")
+	flagSet.StringVar(&ds.endpoints, "endpoints", "",
+		"Location to a YAML file of synthetic observer endpoints. If set, receiver rules are evaluated against them instead of running the observers.")
+	flagSet.DurationVar(&ds.duration, "duration", 0, "How long to run the observers. Defaults to SPLUNK_DISCOVERY_DURATION or 10s.")
+	flagSet.StringVarP(&ds.output, "output", "o", "json", "The report format, one of json or yaml.")
+	flagSet.StringSliceVar(&ds.required, "required", nil,
+		"Receivers that must not be evaluated with a failed status. The command exits non-zero if any of them failed.")
+	flagSet.Usage = func() {
+		fmt.Fprintf(flagSet.Output(), `Usage:
+  otelcol %s [flags]
+
+Runs the discovery observers and receivers and reports the discovered endpoints,
+their matching receivers, evaluated statuses, and redacted receiver configs.
+With --endpoints, no observers or receivers are run and the report lists the
+receivers that would be instantiated for the synthetic endpoints with their
+expanded configs.
+
+Flags:
+%s`, discoverCommand, flagSet.FlagUsages())
+	}
+
+	if err := flagSet.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if ds.output != "json" && ds.output != "yaml" {
+		return nil, fmt.Errorf("unsupported --output %q. Must be one of json or yaml", ds.output)
+	}
+	for _, property := range ds.properties {
+		if !strings.HasPrefix(property, "splunk.discovery") {
+			return nil, fmt.Errorf("invalid --set %q: only splunk.discovery properties are supported", property)
+		}
+	}
+	return ds, nil
+}
+
+func writeDiscoveryReport(out io.Writer, format string, report *discovery.Report) error {
+	var content []byte
+	var err error
+	if format == "yaml" {
+		content, err = yaml.Marshal(report)
+	} else {
+		content, err = json.MarshalIndent(report, "", "  ")
+		content = append(content, '\n')
+	}
+	if err != nil {
+		return fmt.Errorf("failed marshaling discovery report: %w", err)
+	}
+	_, err = out.Write(content)
+	return err
+}
@@ -0,0 +1,109 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/signalfx/splunk-otel-collector/internal/confmapprovider/discovery"
+)
+
+func TestParseDiscoverArgs(t *testing.T) {
+	t.Setenv("SPLUNK_CONFIG_DIR", "/from/env/config.d")
+
+	ds, err := parseDiscoverArgs(nil)
+	require.NoError(t, err)
+	assert.Equal(t, &discoverSettings{configDir: "/from/env/config.d", output: "json"}, ds)
+
+	ds, err = parseDiscoverArgs([]string{
+		"--config-dir", "/some/config.d",
+		"--discovery-properties", "/some/properties.yaml",
+		"--endpoints", "/some/endpoints.yaml",
+		"--set", "splunk.discovery.receivers.mysql.config.username=admin",
+		"--duration", "3s",
+		"-o", "yaml",
+		"--required", "mysql,postgresql",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &discoverSettings{
+		configDir:      "/some/config.d",
+		propertiesFile: "/some/properties.yaml",
+		endpoints:      "/some/endpoints.yaml",
+		output:         "yaml",
+		properties:     []string{"splunk.discovery.receivers.mysql.config.username=admin"},
+		required:       []string{"mysql", "postgresql"},
+		duration:       3 * time.Second,
+	}, ds)
+
+	_, err = parseDiscoverArgs([]string{"-o", "xml"})
+	require.EqualError(t, err, `unsupported --output "xml". Must be one of json or yaml`)
+
+	_, err = parseDiscoverArgs([]string{"--set", "processors.batch.timeout=2s"})
+	require.EqualError(t, err, `invalid --set "processors.batch.timeout=2s": only splunk.discovery properties are supported`)
+}
+
+func TestWriteDiscoveryReport(t *testing.T) {
+	report := &discovery.Report{Endpoints: []discovery.EndpointReport{
+		{
+			ID:       "container-id:3306",
+			Observer: "docker_observer",
+			Type:     "container",
+			Target:   "172.17.0.2:3306",
+			Receivers: []discovery.ReceiverReport{
+				{
+					Receiver: "mysql",
+					Status:   "partial",
+					Message:  "Make sure your user credentials are correctly specified.",
+					Config:   map[string]any{"username": "<redacted>"},
+				},
+			},
+		},
+	}}
+
+	out := &bytes.Buffer{}
+	require.NoError(t, writeDiscoveryReport(out, "json", report))
+	assert.JSONEq(t, `{"endpoints": [{
+  "id": "container-id:3306",
+  "observer": "docker_observer",
+  "type": "container",
+  "target": "172.17.0.2:3306",
+  "receivers": [{
+    "receiver": "mysql",
+    "status": "partial",
+    "message": "Make sure your user credentials are correctly specified.",
+    "config": {"username": "<redacted>"}
+  }]
+}]}`, out.String())
+
+	out.Reset()
+	require.NoError(t, writeDiscoveryReport(out, "yaml", report))
+	assert.YAMLEq(t, `endpoints:
+- id: container-id:3306
+  observer: docker_observer
+  type: container
+  target: 172.17.0.2:3306
+  receivers:
+  - receiver: mysql
+    status: partial
+    message: Make sure your user credentials are correctly specified.
+    config:
+      username: <redacted>
+`, out.String())
+}
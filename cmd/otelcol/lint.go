@@ -0,0 +1,141 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	flag "github.com/spf13/pflag"
+	"go.opentelemetry.io/collector/confmap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configconverter"
+	"github.com/signalfx/splunk-otel-collector/internal/settings"
+)
+
+const lintCommand = "lint"
+
+// errLintErrors is returned when the lint pass reported findings with the error severity.
+var errLintErrors = errors.New("config lint reported errors")
+
+type lintSettings struct {
+	output  string
+	configs []string
+}
+
+// runLint handles the "lint" command: it lints the merged, unresolved content of the provided
+// config files and writes the findings as text or JSON to out.
+func runLint(args []string, out io.Writer) error {
+	ls, err := parseLintArgs(args)
+	if err != nil {
+		return err
+	}
+
+	conf := confmap.New()
+	for _, config := range ls.configs {
+		var content []byte
+		if content, err = os.ReadFile(strings.TrimPrefix(config, "file:")); err != nil {
+			return fmt.Errorf("failed reading config %q: %w", config, err)
+		}
+		var retrieved *confmap.Retrieved
+		if retrieved, err = confmap.NewRetrievedFromYAML(content); err != nil {
+			return fmt.Errorf("failed parsing config %q: %w", config, err)
+		}
+		var configConf *confmap.Conf
+		if configConf, err = retrieved.AsConf(); err != nil {
+			return fmt.Errorf("failed parsing config %q: %w", config, err)
+		}
+		if err = conf.Merge(configConf); err != nil {
+			return fmt.Errorf("failed merging config %q: %w", config, err)
+		}
+	}
+
+	findings := configconverter.Lint(conf)
+	if err = writeLintFindings(out, ls.output, findings); err != nil {
+		return err
+	}
+	for _, finding := range findings {
+		if finding.Severity == configconverter.LintSeverityError {
+			return errLintErrors
+		}
+	}
+	return nil
+}
+
+func parseLintArgs(args []string) (*lintSettings, error) {
+	flagSet := flag.NewFlagSet(lintCommand, flag.ContinueOnError)
+	ls := &lintSettings{}
+
+	flagSet.StringArrayVar(&ls.configs, "config", nil, "Location to a config file, can be repeated. Defaults to SPLUNK_CONFIG.")
+	flagSet.StringVarP(&ls.output, "output", "o", "text", "The findings format, one of text or json.")
+	flagSet.Usage = func() {
+		fmt.Fprintf(flagSet.Output(), `Usage:
+  otelcol %s [flags]
+
+Reports components that aren't used in any pipeline, pipelines without a
+memory_limiter or batch processor, deprecated components and endpoints, and
+ingest tokens set as plain text literals. Env vars and config sources aren't
+resolved. The command exits non-zero if any finding has the error severity.
+
+Flags:
+%s`, lintCommand, flagSet.FlagUsages())
+	}
+
+	if err := flagSet.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if ls.output != "text" && ls.output != "json" {
+		return nil, fmt.Errorf("unsupported --output %q. Must be one of text or json", ls.output)
+	}
+	if len(ls.configs) == 0 {
+		if envConfig := os.Getenv(settings.ConfigEnvVar); envConfig != "" {
+			ls.configs = []string{envConfig}
+		}
+	}
+	if len(ls.configs) == 0 {
+		return nil, fmt.Errorf("no config to lint: set --config or the %s env var", settings.ConfigEnvVar)
+	}
+	return ls, nil
+}
+
+func writeLintFindings(out io.Writer, format string, findings []configconverter.LintFinding) error {
+	if format == "json" {
+		if findings == nil {
+			findings = []configconverter.LintFinding{}
+		}
+		content, err := json.MarshalIndent(findings, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed marshaling lint findings: %w", err)
+		}
+		_, err = out.Write(append(content, '\n'))
+		return err
+	}
+
+	if len(findings) == 0 {
+		_, err := fmt.Fprintln(out, "No issues found.")
+		return err
+	}
+	for _, finding := range findings {
+		if _, err := fmt.Fprintln(out, finding.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configconverter"
+)
+
+func TestParseLintArgs(t *testing.T) {
+	t.Setenv("SPLUNK_CONFIG", "/from/env/config.yaml")
+
+	ls, err := parseLintArgs(nil)
+	require.NoError(t, err)
+	assert.Equal(t, &lintSettings{configs: []string{"/from/env/config.yaml"}, output: "text"}, ls)
+
+	ls, err = parseLintArgs([]string{"--config", "/some/config.yaml", "--config", "file:/some/other.yaml", "-o", "json"})
+	require.NoError(t, err)
+	assert.Equal(t, &lintSettings{configs: []string{"/some/config.yaml", "file:/some/other.yaml"}, output: "json"}, ls)
+
+	_, err = parseLintArgs([]string{"-o", "yaml"})
+	require.EqualError(t, err, `unsupported --output "yaml". Must be one of text or json`)
+
+	t.Setenv("SPLUNK_CONFIG", "")
+	_, err = parseLintArgs(nil)
+	require.EqualError(t, err, "no config to lint: set --config or the SPLUNK_CONFIG env var")
+}
+
+func TestRunLint(t *testing.T) {
+	dir := t.TempDir()
+	config := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(config, []byte(`receivers:
+  otlp:
+exporters:
+  splunk_hec:
+    token: ${SPLUNK_HEC_TOKEN}
+service:
+  pipelines:
+    logs:
+      receivers: [otlp]
+      exporters: [splunk_hec]
+`), 0o600))
+	override := filepath.Join(dir, "override.yaml")
+	require.NoError(t, os.WriteFile(override, []byte(`processors:
+  memory_limiter:
+  batch:
+service:
+  pipelines:
+    logs:
+      processors: [memory_limiter, batch]
+`), 0o600))
+
+	out := &bytes.Buffer{}
+	require.NoError(t, runLint([]string{"--config", config, "--config", "file:" + override}, out))
+	assert.Equal(t, "No issues found.\n", out.String())
+
+	require.NoError(t, os.WriteFile(override, []byte(`exporters:
+  splunk_hec:
+    token: 00000000-0000-0000-0000-000000000000
+`), 0o600))
+	out.Reset()
+	require.ErrorIs(t, runLint([]string{"--config", config, "--config", override}, out), errLintErrors)
+	assert.Equal(t, `error [plaintext-token] exporters::splunk_hec::token: ingest token is set as a plain text literal, use an env var or config source instead
+warning [missing-batch-processor] service::pipelines::logs: pipeline has no batch processor to reduce the number of outgoing requests
+warning [missing-memory-limiter] service::pipelines::logs: pipeline has no memory_limiter processor to prevent out of memory situations
+`, out.String())
+
+	err := runLint([]string{"--config", filepath.Join(dir, "missing.yaml")}, out)
+	require.ErrorContains(t, err, "failed reading config")
+}
+
+func TestWriteLintFindings(t *testing.T) {
+	findings := []configconverter.LintFinding{
+		{
+			Rule:     configconverter.LintRuleUnusedComponent,
+			Severity: configconverter.LintSeverityWarning,
+			Path:     "receivers::hostmetrics",
+			Message:  "hostmetrics is defined but not used in any pipeline",
+		},
+	}
+
+	out := &bytes.Buffer{}
+	require.NoError(t, writeLintFindings(out, "text", findings))
+	assert.Equal(t, "warning [unused-component] receivers::hostmetrics: hostmetrics is defined but not used in any pipeline\n", out.String())
+
+	out.Reset()
+	require.NoError(t, writeLintFindings(out, "json", findings))
+	assert.JSONEq(t, `[{
+  "rule": "unused-component",
+  "severity": "warning",
+  "path": "receivers::hostmetrics",
+  "message": "hostmetrics is defined but not used in any pipeline"
+}]`, out.String())
+
+	out.Reset()
+	require.NoError(t, writeLintFindings(out, "json", nil))
+	assert.JSONEq(t, `[]`, out.String())
+}
@@ -42,6 +42,8 @@ import (
 
 const modularinputStanzaPrefix = "Splunk_TA_otel://"
 
+// ta_scheme.xml is generated from ta_schema.yaml with cmd/ta-inputs-from-schema
+//
 //go:embed ta_scheme.xml
 var modularInputSchemeXML string
 
@@ -61,7 +63,9 @@ func runFromCmdLine(args []string) {
 	log.SetFlags(log.Ldate | log.Ltime | log.Lshortfile)
 
 	// Handle the cases of running as a TA
-	args, taRunMode, err := modularinput.HandleLaunchAsTA(args, stdinReader, modularinputStanzaPrefix, modularInputSchemeXML, validateTAArguments)
+	args, taRunMode, err := modularinput.HandleLaunchAsTA(
+		args, stdinReader, modularinputStanzaPrefix, modularInputSchemeXML, validateTAArguments, taLaunchOptions...,
+	)
 	if taRunMode != modularinput.NotTARunMode {
 		log.SetFlags(0)
 		log.SetOutput(os.Stderr)
@@ -76,6 +80,30 @@ func runFromCmdLine(args []string) {
 		return
 	}
 
+	if len(args) > 1 && args[1] == discoverCommand {
+		if err = runDiscover(context.Background(), args[2:], stdoutWriter); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				exitFn(0)
+				return
+			}
+			log.Printf("ERROR: %v", err)
+			exitFn(1)
+		}
+		return
+	}
+
+	if len(args) > 1 && args[1] == lintCommand {
+		if err = runLint(args[2:], stdoutWriter); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				exitFn(0)
+				return
+			}
+			log.Printf("ERROR: %v", err)
+			exitFn(1)
+		}
+		return
+	}
+
 	collectorSettings, err := settings.New(args[1:])
 	if err != nil {
 		if taRunMode == modularinput.ValidationTARunMode {
@@ -99,17 +127,31 @@ func runFromCmdLine(args []string) {
 		Version: version.Version,
 	}
 
+	if err = configconverter.SetRedactionPolicyFromEnv(); err != nil {
+		log.Fatalf("invalid redaction policy: %v", err)
+	}
+
 	telemetryHook := configsource.NewTelemetryHook()
 	confMapConverterFactories := collectorSettings.ConfMapConverterFactories()
 	dryRun := configconverter.NewDryRun(collectorSettings.IsDryRun(), confMapConverterFactories)
+	explain := configconverter.NewExplain(collectorSettings.IsExplain(), confMapConverterFactories)
+	smartAgentMigration := configconverter.NewSmartAgentMigrationReport(collectorSettings.IsMigrateSmartAgent(), confMapConverterFactories)
+	lintWarnings := configconverter.NewLintWarnings(collectorSettings.IsLintWarnings())
 	expvarConverter := configconverter.GetExpvarConverter()
 	confMapConverterFactories = append(confMapConverterFactories,
 		configconverter.ConverterFactoryFromConverter(dryRun),
+		configconverter.ConverterFactoryFromConverter(explain),
+		configconverter.ConverterFactoryFromConverter(smartAgentMigration),
+		lintWarnings,
 		configconverter.ConverterFactoryFromFunc(configconverter.InjectConfigSourceTelemetryExtension),
 		configconverter.ConverterFactoryFromFunc(configconverter.RemoveSplunkOpAMPIfFeatureGateDisabled),
+		configconverter.ConverterFactoryFromFunc(configconverter.MigrateSmartAgentReceivers),
 		configconverter.ConverterFactoryFromConverter(expvarConverter)) // `expvarConverter` must be last to expose the effective config correctly
 
-	configSourceProvider := configsource.New(zap.NewNop(), []configsource.Hook{expvarConverter, dryRun, telemetryHook})
+	configSourceProvider := configsource.New(zap.NewNop(), []configsource.Hook{expvarConverter, dryRun, explain, smartAgentMigration, lintWarnings, telemetryHook})
+	if err = configSourceProvider.EnableCacheFromEnv(); err != nil {
+		log.Fatalf("invalid config source cache settings: %v", err)
+	}
 
 	var providerFactories []confmap.ProviderFactory
 	for _, pf := range collectorSettings.ConfMapProviderFactories() {
@@ -144,9 +186,14 @@ func runFromCmdLine(args []string) {
 }
 
 // validateTAArguments validates the modular input parameters sent by Splunk
-// when running as a TA with the argument '--validate-arguments'.
+// when running as a TA with the argument '--validate-arguments' against the
+// ta_schema.yaml specs, see ta_validator.go.
 // It sets args[1] to "validate" so the collector runs in validate sub-command mode.
-func validateTAArguments(_ *modularinput.ValidationItems, args []string) ([]string, error) {
+func validateTAArguments(items *modularinput.ValidationItems, args []string) ([]string, error) {
+	args, err := validateTAParams(items, args)
+	if err != nil {
+		return nil, err
+	}
 	args[1] = "validate"
 	return args, nil
 }
@@ -21,6 +21,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/signalfx/splunk-otel-collector/pkg/modularinput"
 )
 
 // validationModeXML is a minimal <items> XML document sent by Splunk on stdin
@@ -132,3 +134,25 @@ service:
 	assert.Contains(t, output, "<message>", "expected XML message element in stdout")
 	assert.NotContains(t, output, "<message></message>", "error message must not be empty")
 }
+
+func TestValidateTAArguments(t *testing.T) {
+	items := &modularinput.ValidationItems{Item: []modularinput.ValidationItem{{
+		Name: "Splunk_TA_otel://default",
+		Param: []modularinput.Param{
+			{Name: "splunk_realm", Value: "us0"},
+			{Name: "splunk_access_token", Value: "test_token"},
+		},
+	}}}
+	args, err := validateTAArguments(items, []string{"otelcol", "--validate-arguments"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"otelcol", "validate"}, args)
+
+	// The realm and log level values aren't restricted, any zap level is accepted.
+	items.Item[0].Param = append(items.Item[0].Param, modularinput.Param{Name: "splunk_collector_log_level", Value: "DPANIC"})
+	_, err = validateTAArguments(items, []string{"otelcol", "--validate-arguments"})
+	require.NoError(t, err)
+
+	items.Item[0].Param[1].Value = ""
+	_, err = validateTAArguments(items, []string{"otelcol", "--validate-arguments"})
+	require.EqualError(t, err, `stanza 'Splunk_TA_otel://default': parameter 'splunk_access_token' is required`)
+}
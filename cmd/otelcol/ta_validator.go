@@ -0,0 +1,46 @@
+// Code generated by ta-inputs-from-schema from ta_schema.yaml. DO NOT EDIT.
+
+package main
+
+import "github.com/signalfx/splunk-otel-collector/pkg/modularinput"
+
+// taParamSpecs are the arguments of the Splunk_TA_otel modular input.
+var taParamSpecs = []modularinput.ParamSpec{
+	{
+		Name:     "splunk_access_token",
+		Type:     modularinput.ParamTypeString,
+		Required: true,
+	},
+	{
+		Name:     "splunk_realm",
+		Type:     modularinput.ParamTypeString,
+		Required: true,
+	},
+	{
+		Name:     "splunk_config",
+		Type:     modularinput.ParamTypeString,
+		Default:  "$SPLUNK_HOME/etc/apps/$SPLUNK_MODINPUT_BASE_DIR_NAME/configs/agent_config.yaml",
+		Required: true,
+	},
+	{
+		Name:    "splunk_collector_log_level",
+		Type:    modularinput.ParamTypeString,
+		Default: "error",
+	},
+	{
+		Name: "splunk_collector_env_vars",
+		Type: modularinput.ParamTypeString,
+	},
+	{
+		Name: "splunk_collector_cmd_args",
+		Type: modularinput.ParamTypeString,
+	},
+}
+
+// validateTAParams validates the arguments sent by Splunk in --validate-arguments mode.
+var validateTAParams = modularinput.NewParamsValidator(taParamSpecs)
+
+// taLaunchOptions are the modularinput.HandleLaunchAsTA options of the argument mappings.
+var taLaunchOptions = []modularinput.LaunchOption{
+	modularinput.WithStanzaArg("splunk_config", "--config"),
+}
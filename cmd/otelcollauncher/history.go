@@ -0,0 +1,71 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/signalfx/splunk-otel-collector/internal/opampsupervisor/launcher"
+)
+
+const (
+	configHistoryFlag = "--config-history"
+	rollbackFlag      = "--rollback"
+	clearRollbackFlag = "--clear-rollback"
+)
+
+// runHistoryCommand runs the supervisor config history command of args, if any,
+// and reports whether args were a history command.
+func runHistoryCommand(args []string, paths launcher.Paths, out io.Writer) (bool, error) {
+	if len(args) == 0 {
+		return false, nil
+	}
+	switch args[0] {
+	case configHistoryFlag:
+		snapshots, err := launcher.ConfigHistory(paths)
+		if err != nil {
+			return true, err
+		}
+		if len(snapshots) == 0 {
+			_, err = fmt.Fprintln(out, "no effective config recorded")
+			return true, err
+		}
+		for _, snapshot := range snapshots {
+			if _, err = fmt.Fprintf(out, "%s\t%s\t%s\n", snapshot.Name, snapshot.Time.Format(time.RFC3339), snapshot.Path); err != nil {
+				return true, err
+			}
+		}
+		return true, nil
+	case rollbackFlag:
+		if len(args) != 2 {
+			return true, errors.New("usage: otelcollauncher --rollback <name listed by --config-history>")
+		}
+		if err := launcher.Rollback(paths, args[1]); err != nil {
+			return true, err
+		}
+		_, err := fmt.Fprintf(out, "rolled back to %s, restart the service to apply it\n", args[1])
+		return true, err
+	case clearRollbackFlag:
+		if err := launcher.ClearRollback(paths); err != nil {
+			return true, err
+		}
+		_, err := fmt.Fprintln(out, "rollback cleared, restart the service to apply it")
+		return true, err
+	}
+	return false, nil
+}
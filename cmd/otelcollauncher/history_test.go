@@ -0,0 +1,69 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/signalfx/splunk-otel-collector/internal/opampsupervisor/launcher"
+)
+
+func TestRunHistoryCommand(t *testing.T) {
+	dir := t.TempDir()
+	paths := launcher.Paths{
+		SupervisorConfig: filepath.Join(dir, "supervisor_config.yaml"),
+		StorageDirectory: dir,
+	}
+
+	handled, err := runHistoryCommand([]string{"--config", "agent_config.yaml"}, paths, nil)
+	require.NoError(t, err)
+	assert.False(t, handled)
+
+	var out strings.Builder
+	handled, err = runHistoryCommand([]string{configHistoryFlag}, paths, &out)
+	require.NoError(t, err)
+	assert.True(t, handled)
+	assert.Equal(t, "no effective config recorded\n", out.String())
+
+	historyDir := filepath.Join(dir, "config_history")
+	require.NoError(t, os.MkdirAll(historyDir, 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(historyDir, "20260102T030405.000Z.yaml"), []byte("service: {}"), 0o600))
+
+	out.Reset()
+	_, err = runHistoryCommand([]string{configHistoryFlag}, paths, &out)
+	require.NoError(t, err)
+	assert.Equal(t, "20260102T030405.000Z\t2026-01-02T03:04:05Z\t"+filepath.Join(historyDir, "20260102T030405.000Z.yaml")+"\n", out.String())
+
+	_, err = runHistoryCommand([]string{rollbackFlag}, paths, &out)
+	require.EqualError(t, err, "usage: otelcollauncher --rollback <name listed by --config-history>")
+
+	out.Reset()
+	_, err = runHistoryCommand([]string{rollbackFlag, "20260102T030405.000Z"}, paths, &out)
+	require.NoError(t, err)
+	assert.Equal(t, "rolled back to 20260102T030405.000Z, restart the service to apply it\n", out.String())
+	assert.FileExists(t, filepath.Join(dir, "rollback_config.yaml"))
+
+	out.Reset()
+	_, err = runHistoryCommand([]string{clearRollbackFlag}, paths, &out)
+	require.NoError(t, err)
+	assert.Equal(t, "rollback cleared, restart the service to apply it\n", out.String())
+	assert.NoFileExists(t, filepath.Join(dir, "rollback_config.yaml"))
+}
@@ -17,12 +17,31 @@ package main
 import (
 	"log"
 	"os"
+	"slices"
 
 	"github.com/signalfx/splunk-otel-collector/internal/opampsupervisor/launcher"
 )
 
+// checkFlag makes the launcher validate its inputs and print the command it
+// would run instead of running it.
+const checkFlag = "--check"
+
 func main() {
-	if err := run(os.Args[1:], os.Environ(), launcher.DefaultPaths()); err != nil {
+	args := os.Args[1:]
+	if handled, err := runHistoryCommand(args, launcher.DefaultPaths(), os.Stdout); handled {
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if i := slices.Index(args, checkFlag); i >= 0 {
+		args = slices.Delete(slices.Clone(args), i, i+1)
+		if err := launcher.Check(args, os.Environ(), launcher.DefaultPaths(), os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if err := run(args, os.Environ(), launcher.DefaultPaths()); err != nil {
 		log.Fatal(err)
 	}
 }
@@ -17,17 +17,67 @@
 package main
 
 import (
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+
 	"golang.org/x/sys/unix"
 
 	"github.com/signalfx/splunk-otel-collector/internal/opampsupervisor/launcher"
 )
 
 // run replaces the launcher process with the selected child process on Linux
-// so systemd tracks the collector or supervisor directly.
+// so systemd tracks the collector or supervisor directly. With the watchdog
+// enabled, the launcher instead stays the service main process and restarts
+// the child, falling back to the collector with the default agent config when
+// the supervisor keeps failing.
 func run(args, env []string, paths launcher.Paths) error {
 	cmd, err := launcher.PrepareCommand(args, env, paths)
 	if err != nil {
 		return err
 	}
-	return unix.Exec(cmd.Path, append([]string{cmd.Path}, cmd.Args...), cmd.Env)
+
+	watchdog, err := launcher.NewWatchdog(args, env, paths, startChild)
+	if err != nil {
+		return err
+	}
+	if watchdog == nil {
+		return unix.Exec(cmd.Path, append([]string{cmd.Path}, cmd.Args...), cmd.Env)
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT, syscall.SIGHUP)
+	defer signal.Stop(signals)
+	return watchdog.Run(cmd, signals)
+}
+
+// startChild starts the child process with the launcher stdio so its output
+// keeps going to the service journal.
+func startChild(cmdSpec launcher.Command) (launcher.Child, error) {
+	cmd := exec.Command(cmdSpec.Path, cmdSpec.Args...)
+	cmd.Env = cmdSpec.Env
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return execChild{cmd: cmd}, nil
+}
+
+type execChild struct {
+	cmd *exec.Cmd
+}
+
+func (c execChild) Pid() int {
+	return c.cmd.Process.Pid
+}
+
+func (c execChild) Signal(sig os.Signal) error {
+	return c.cmd.Process.Signal(sig)
+}
+
+func (c execChild) Wait() error {
+	return c.cmd.Wait()
 }
@@ -15,10 +15,14 @@
 package main
 
 import (
+	"encoding/xml"
 	"fmt"
+	"go/format"
 	"strings"
 )
 
+const generatedNotice = "Code generated by ta-inputs-from-schema from %s. DO NOT EDIT."
+
 // generateInputsConf generates the inputs.conf file content
 func generateInputsConf(scheme *Scheme, globalSettings, inputName string) string {
 	var sb strings.Builder
@@ -86,3 +90,158 @@ func normalizeDescription(desc string) string {
 	// Replace multiple whitespace (including newlines) with single space
 	return strings.Join(strings.Fields(desc), " ")
 }
+
+// generateSchemeXML generates the scheme XML written by the modular input in introspection mode
+func generateSchemeXML(scheme *Scheme) (string, error) {
+	out, err := xml.MarshalIndent(scheme, "", "    ")
+	if err != nil {
+		return "", err
+	}
+	return unescapeQuotes(string(out)) + "\n", nil
+}
+
+// unescapeQuotes reverts the escaping of the quotes by the XML encoder, which isn't required
+// outside of the attribute values, to keep the descriptions readable
+func unescapeQuotes(s string) string {
+	var sb strings.Builder
+	inTag := false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '<':
+			inTag = true
+		case s[i] == '>':
+			inTag = false
+		case !inTag && strings.HasPrefix(s[i:], "&#34;"):
+			sb.WriteByte('"')
+			i += len("&#34;") - 1
+			continue
+		case strings.HasPrefix(s[i:], "&#39;"):
+			sb.WriteByte('\'')
+			i += len("&#39;") - 1
+			continue
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
+
+// generateMarkdown generates the Markdown reference of the modular input arguments
+func generateMarkdown(schema *Schema, schemaFile string) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("<!-- "+generatedNotice+" -->\n\n", schemaFile))
+	sb.WriteString(fmt.Sprintf("# %s inputs reference\n\n", schema.Name))
+	if desc := normalizeDescription(schema.Description); desc != "" {
+		sb.WriteString(desc + ".\n\n")
+	}
+	sb.WriteString(fmt.Sprintf("The `[%s://<name>]` stanzas of `inputs.conf` support the following arguments.\n\n", schema.Name))
+
+	sb.WriteString("| Argument | Type | Required | Default | Passed as |\n")
+	sb.WriteString("|----------|------|----------|---------|-----------|\n")
+	for _, arg := range schema.Args {
+		required := "no"
+		if arg.Required {
+			required = "yes"
+		}
+		sb.WriteString(fmt.Sprintf("| [`%s`](#%s) | %s | %s | %s | %s |\n",
+			arg.Name, arg.Name, arg.Type, required, markdownCode(arg.Default), passedAs(arg)))
+	}
+
+	for _, arg := range schema.Args {
+		sb.WriteString(fmt.Sprintf("\n## `%s`\n\n", arg.Name))
+		if arg.Title != "" {
+			sb.WriteString("**" + arg.Title + "**: ")
+		}
+		sb.WriteString(normalizeDescription(arg.Description) + "\n")
+		if values := arg.Validation.AllowedValues; len(values) > 0 {
+			codes := make([]string, 0, len(values))
+			for _, value := range values {
+				codes = append(codes, markdownCode(value))
+			}
+			sb.WriteString("\nAllowed values: " + strings.Join(codes, ", ") + ".\n")
+		}
+		if arg.Validation.Pattern != "" {
+			sb.WriteString("\nMust match the " + markdownCode(arg.Validation.Pattern) + " regular expression.\n")
+		}
+	}
+
+	return sb.String()
+}
+
+// passedAs describes how the argument value is passed to the modular input process
+func passedAs(arg SchemaArg) string {
+	switch arg.Mapping {
+	case mappingEnvVars:
+		return "Environment variables, from the `KEY1=VALUE1,KEY2=VALUE2` pairs"
+	case mappingCmdArgs:
+		return "Command line arguments"
+	}
+	passed := markdownCode(arg.EnvVar) + " environment variable"
+	if arg.Flag != "" {
+		passed += ", or a " + markdownCode(arg.Flag) + " flag per value when the stanzas set different values"
+	}
+	return passed
+}
+
+func markdownCode(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "`" + strings.ReplaceAll(s, "|", "\\|") + "`"
+}
+
+// generateValidator generates the Go source of the argument specs, validator and launch
+// options of the modular input to pass to modularinput.HandleLaunchAsTA
+func generateValidator(schema *Schema, schemaFile, pkg string) ([]byte, error) {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("// "+generatedNotice+"\n\n", schemaFile))
+	sb.WriteString("package " + pkg + "\n\n")
+	sb.WriteString("import \"github.com/signalfx/splunk-otel-collector/pkg/modularinput\"\n\n")
+
+	sb.WriteString(fmt.Sprintf("// taParamSpecs are the arguments of the %s modular input.\n", schema.Name))
+	sb.WriteString("var taParamSpecs = []modularinput.ParamSpec{\n")
+	for _, arg := range schema.Args {
+		sb.WriteString("{\n")
+		sb.WriteString(fmt.Sprintf("Name: %q,\n", arg.Name))
+		sb.WriteString(fmt.Sprintf("Type: modularinput.ParamType%s,\n", strings.ToUpper(arg.Type[:1])+arg.Type[1:]))
+		if arg.Default != "" {
+			sb.WriteString(fmt.Sprintf("Default: %q,\n", arg.Default))
+		}
+		if arg.Validation.Pattern != "" {
+			sb.WriteString(fmt.Sprintf("Pattern: %q,\n", arg.Validation.Pattern))
+		}
+		if len(arg.Validation.AllowedValues) > 0 {
+			sb.WriteString(fmt.Sprintf("AllowedValues: %#v,\n", arg.Validation.AllowedValues))
+		}
+		if arg.Required {
+			sb.WriteString("Required: true,\n")
+		}
+		sb.WriteString("},\n")
+	}
+	sb.WriteString("}\n\n")
+
+	sb.WriteString("// validateTAParams validates the arguments sent by Splunk in --validate-arguments mode.\n")
+	sb.WriteString("var validateTAParams = modularinput.NewParamsValidator(taParamSpecs)\n\n")
+
+	var options []string
+	for _, arg := range schema.Args {
+		if arg.Mapping != mappingEnv {
+			continue
+		}
+		if arg.EnvVar != strings.ToUpper(arg.Name) {
+			options = append(options, fmt.Sprintf("modularinput.WithStanzaEnvVar(%q, %q),\n", arg.Name, arg.EnvVar))
+		}
+		if arg.Flag != "" {
+			options = append(options, fmt.Sprintf("modularinput.WithStanzaArg(%q, %q),\n", arg.Name, arg.Flag))
+		}
+	}
+	sb.WriteString("// taLaunchOptions are the modularinput.HandleLaunchAsTA options of the argument mappings.\n")
+	if len(options) == 0 {
+		sb.WriteString("var taLaunchOptions []modularinput.LaunchOption\n")
+	} else {
+		sb.WriteString("var taLaunchOptions = []modularinput.LaunchOption{\n" + strings.Join(options, "") + "}\n")
+	}
+
+	return format.Source([]byte(sb.String()))
+}
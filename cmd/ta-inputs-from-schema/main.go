@@ -23,22 +23,42 @@ import (
 )
 
 func main() {
-	schemeFile := flag.String("scheme", "", "Path to the XML scheme file (required)")
+	schemaFile := flag.String("schema", "", "Path to the YAML schema file, required unless -scheme is set")
+	schemeFile := flag.String("scheme", "", "Path to the XML scheme file to read instead of the YAML schema")
 	globalSettings := flag.String("global-settings", "", "Path to the global settings file (required)")
-	inputName := flag.String("name", "", "Name of the modular input (required)")
+	inputName := flag.String("name", "", "Name of the modular input, required with -scheme")
 	assetsDir := flag.String("assets", "", "Path to the assets directory (required)")
+	schemeOut := flag.String("scheme-out", "", "Path of the XML scheme file to generate from the YAML schema")
+	docsOut := flag.String("docs-out", "", "Path of the Markdown reference to generate from the YAML schema")
+	validatorOut := flag.String("validator-out", "", "Path of the Go validator to generate from the YAML schema")
+	validatorPackage := flag.String("validator-package", "main", "Package name of the generated Go validator")
 	flag.Parse()
 
-	if *schemeFile == "" || *globalSettings == "" || *inputName == "" || *assetsDir == "" {
+	if (*schemaFile == "") == (*schemeFile == "") || *globalSettings == "" || *assetsDir == "" ||
+		(*schemeFile != "" && (*inputName == "" || *schemeOut != "" || *docsOut != "" || *validatorOut != "")) {
 		flag.Usage()
 		os.Exit(1)
 	}
 
-	if err := run(*schemeFile, *globalSettings, *inputName, *assetsDir); err != nil {
+	if *schemeFile != "" {
+		if err := run(*schemeFile, *globalSettings, *inputName, *assetsDir); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		fmt.Println("Successfully generated inputs.conf and inputs.conf.spec")
+		return
+	}
+
+	outputs := schemaOutputs{
+		schemeFile:       *schemeOut,
+		docsFile:         *docsOut,
+		validatorFile:    *validatorOut,
+		validatorPackage: *validatorPackage,
+	}
+	if err := runFromSchema(*schemaFile, *globalSettings, *inputName, *assetsDir, outputs); err != nil {
 		log.Fatalf("Error: %v", err)
 	}
 
-	fmt.Println("Successfully generated inputs.conf and inputs.conf.spec")
+	fmt.Println("Successfully generated the modular input files from the schema")
 }
 
 func run(schemeFile, globalSettingsFile, inputName, assetsDir string) error {
@@ -48,6 +68,64 @@ func run(schemeFile, globalSettingsFile, inputName, assetsDir string) error {
 		return fmt.Errorf("failed to parse scheme XML: %w", err)
 	}
 
+	return writeInputsConfs(scheme, globalSettingsFile, inputName, assetsDir)
+}
+
+// schemaOutputs are the optional files generated from the YAML schema
+type schemaOutputs struct {
+	schemeFile       string
+	docsFile         string
+	validatorFile    string
+	validatorPackage string
+}
+
+func runFromSchema(schemaFile, globalSettingsFile, inputName, assetsDir string, outputs schemaOutputs) error {
+	schema, err := loadSchema(schemaFile)
+	if err != nil {
+		return fmt.Errorf("failed to load schema: %w", err)
+	}
+	if inputName == "" {
+		inputName = schema.Name
+	}
+	scheme := schema.scheme()
+
+	if err := writeInputsConfs(scheme, globalSettingsFile, inputName, assetsDir); err != nil {
+		return err
+	}
+
+	// The generated files refer to the schema by its base name, not to depend on the working directory
+	schemaName := filepath.Base(schemaFile)
+
+	if outputs.schemeFile != "" {
+		schemeXML, err := generateSchemeXML(scheme)
+		if err != nil {
+			return fmt.Errorf("failed to generate scheme XML: %w", err)
+		}
+		if err := writeFile(outputs.schemeFile, []byte(schemeXML)); err != nil {
+			return fmt.Errorf("failed to write scheme XML: %w", err)
+		}
+	}
+
+	if outputs.docsFile != "" {
+		if err := writeFile(outputs.docsFile, []byte(generateMarkdown(schema, schemaName))); err != nil {
+			return fmt.Errorf("failed to write Markdown reference: %w", err)
+		}
+	}
+
+	if outputs.validatorFile != "" {
+		validator, err := generateValidator(schema, schemaName, outputs.validatorPackage)
+		if err != nil {
+			return fmt.Errorf("failed to generate Go validator: %w", err)
+		}
+		if err := writeFile(outputs.validatorFile, validator); err != nil {
+			return fmt.Errorf("failed to write Go validator: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func writeInputsConfs(scheme *Scheme, globalSettingsFile, inputName, assetsDir string) error {
 	// Read global settings
 	globalSettings, err := os.ReadFile(globalSettingsFile)
 	if err != nil {
@@ -80,3 +158,10 @@ func run(schemeFile, globalSettingsFile, inputName, assetsDir string) error {
 
 	return nil
 }
+
+func writeFile(filename string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0o600)
+}
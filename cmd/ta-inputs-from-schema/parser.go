@@ -21,10 +21,13 @@ import (
 
 // Scheme represents the XML scheme structure
 type Scheme struct {
-	XMLName     xml.Name `xml:"scheme"`
-	Title       string   `xml:"title"`
-	Description string   `xml:"description"`
-	Endpoint    Endpoint `xml:"endpoint"`
+	XMLName               xml.Name `xml:"scheme"`
+	Title                 string   `xml:"title"`
+	Description           string   `xml:"description"`
+	StreamingMode         string   `xml:"streaming_mode,omitempty"`
+	UseSingleInstance     string   `xml:"use_single_instance,omitempty"`
+	UseExternalValidation string   `xml:"use_external_validation,omitempty"`
+	Endpoint              Endpoint `xml:"endpoint"`
 }
 
 // Endpoint represents the endpoint section
@@ -34,12 +37,13 @@ type Endpoint struct {
 
 // Arg represents an argument
 type Arg struct {
-	Name           string `xml:"name,attr"`
-	DefaultValue   string `xml:"defaultValue,attr"`
-	Title          string `xml:"title"`
-	Description    string `xml:"description"`
-	DataType       string `xml:"data_type"`
-	RequiredOnEdit string `xml:"required_on_edit"`
+	Name             string `xml:"name,attr"`
+	DefaultValue     string `xml:"defaultValue,attr"`
+	Title            string `xml:"title"`
+	Description      string `xml:"description"`
+	DataType         string `xml:"data_type"`
+	RequiredOnEdit   string `xml:"required_on_edit"`
+	RequiredOnCreate string `xml:"required_on_create,omitempty"`
 }
 
 // parseSchemeXML parses the XML scheme file
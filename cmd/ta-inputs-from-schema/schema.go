@@ -0,0 +1,187 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// mappingEnv sets the argument value as an environment variable
+	mappingEnv = "env"
+	// mappingEnvVars sets the "KEY1=VALUE1,KEY2=VALUE2" pairs of the argument value as environment variables
+	mappingEnvVars = "env_vars"
+	// mappingCmdArgs appends the argument value to the command line arguments
+	mappingCmdArgs = "cmd_args"
+)
+
+var dataTypes = []string{"string", "number", "boolean"}
+
+// Schema is the YAML description of a modular input from which all its files are generated
+type Schema struct {
+	Name                  string      `yaml:"name"`
+	Title                 string      `yaml:"title"`
+	Description           string      `yaml:"description"`
+	StreamingMode         string      `yaml:"streaming_mode"`
+	UseSingleInstance     bool        `yaml:"use_single_instance"`
+	UseExternalValidation bool        `yaml:"use_external_validation"`
+	Args                  []SchemaArg `yaml:"args"`
+}
+
+// SchemaArg describes an argument of the modular input
+type SchemaArg struct {
+	Name        string           `yaml:"name"`
+	Title       string           `yaml:"title"`
+	Description string           `yaml:"description"`
+	Type        string           `yaml:"type"`
+	Default     string           `yaml:"default"`
+	Validation  SchemaValidation `yaml:"validation"`
+	// Mapping is how the argument is passed to the process: env (default), env_vars or cmd_args
+	Mapping string `yaml:"mapping"`
+	// EnvVar is the environment variable of an env mapping, defaults to the uppercase name
+	EnvVar string `yaml:"env_var"`
+	// Flag is the command line flag used instead of EnvVar when stanzas set different values
+	Flag     string `yaml:"flag"`
+	Required bool   `yaml:"required"`
+}
+
+// SchemaValidation is the validation rule of an argument non-empty values
+type SchemaValidation struct {
+	Pattern       string   `yaml:"pattern"`
+	AllowedValues []string `yaml:"allowed_values"`
+}
+
+// loadSchema reads, applies the defaults of and validates the YAML schema file
+func loadSchema(filename string) (*Schema, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var schema Schema
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&schema); err != nil {
+		return nil, err
+	}
+
+	for i := range schema.Args {
+		arg := &schema.Args[i]
+		if arg.Type == "" {
+			arg.Type = "string"
+		}
+		if arg.Mapping == "" {
+			arg.Mapping = mappingEnv
+		}
+		if arg.Mapping == mappingEnv && arg.EnvVar == "" {
+			arg.EnvVar = strings.ToUpper(arg.Name)
+		}
+	}
+
+	if err := schema.validate(); err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
+
+func (s *Schema) validate() error {
+	if s.Name == "" {
+		return errors.New("'name' must be specified")
+	}
+
+	var errs []error
+	names := make(map[string]bool)
+	for _, arg := range s.Args {
+		if names[arg.Name] {
+			errs = append(errs, fmt.Errorf("argument '%s' is defined more than once", arg.Name))
+		}
+		names[arg.Name] = true
+		if err := arg.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("argument '%s': %w", arg.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (a *SchemaArg) validate() error {
+	// Only the "splunk_" arguments are handled by modularinput.HandleLaunchAsTA
+	if !strings.HasPrefix(a.Name, "splunk_") {
+		return errors.New("name must start with 'splunk_'")
+	}
+	if !slices.Contains(dataTypes, a.Type) {
+		return fmt.Errorf("type must be one of %s, got '%s'", strings.Join(dataTypes, ", "), a.Type)
+	}
+
+	// modularinput.HandleLaunchAsTA identifies the special mappings by the argument name suffix
+	isEnvVars, isCmdArgs := strings.HasSuffix(a.Name, "_env_vars"), strings.HasSuffix(a.Name, "_cmd_args")
+	switch a.Mapping {
+	case mappingEnv:
+		if isEnvVars || isCmdArgs {
+			return fmt.Errorf("names ending with '_env_vars' or '_cmd_args' require the mapping of the same name")
+		}
+	case mappingEnvVars, mappingCmdArgs:
+		if !strings.HasSuffix(a.Name, "_"+a.Mapping) {
+			return fmt.Errorf("mapping '%s' requires the name to end with '_%s'", a.Mapping, a.Mapping)
+		}
+		if a.EnvVar != "" || a.Flag != "" {
+			return fmt.Errorf("'env_var' and 'flag' are only supported by the '%s' mapping", mappingEnv)
+		}
+	default:
+		return fmt.Errorf("mapping must be one of %s, %s, %s, got '%s'", mappingEnv, mappingEnvVars, mappingCmdArgs, a.Mapping)
+	}
+
+	if a.Validation.Pattern != "" {
+		if _, err := regexp.Compile(a.Validation.Pattern); err != nil {
+			return fmt.Errorf("invalid validation pattern: %w", err)
+		}
+	}
+	if a.Default != "" && len(a.Validation.AllowedValues) > 0 && !slices.Contains(a.Validation.AllowedValues, a.Default) {
+		return fmt.Errorf("default '%s' is not an allowed value", a.Default)
+	}
+	return nil
+}
+
+// scheme returns the scheme XML structure of the schema
+func (s *Schema) scheme() *Scheme {
+	scheme := &Scheme{
+		Title:                 s.Title,
+		Description:           normalizeDescription(s.Description),
+		StreamingMode:         s.StreamingMode,
+		UseSingleInstance:     strconv.FormatBool(s.UseSingleInstance),
+		UseExternalValidation: strconv.FormatBool(s.UseExternalValidation),
+	}
+	for _, arg := range s.Args {
+		required := strconv.FormatBool(arg.Required)
+		scheme.Endpoint.Args = append(scheme.Endpoint.Args, Arg{
+			Name:             arg.Name,
+			DefaultValue:     arg.Default,
+			Title:            arg.Title,
+			Description:      normalizeDescription(arg.Description),
+			DataType:         arg.Type,
+			RequiredOnEdit:   required,
+			RequiredOnCreate: required,
+		})
+	}
+	return scheme
+}
@@ -0,0 +1,201 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadSchema(t *testing.T) {
+	schema, err := loadSchema(filepath.Join("testdata", "schema.yaml"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "Test_TA", schema.Name)
+	require.Len(t, schema.Args, 6)
+	assert.Equal(t, SchemaArg{
+		Name:        "splunk_realm",
+		Title:       "Realm",
+		Description: "The realm to send data to\n",
+		Type:        "string",
+		Validation:  SchemaValidation{Pattern: "^[a-z0-9]+$"},
+		Mapping:     mappingEnv,
+		EnvVar:      "SPLUNK_REALM",
+		Required:    true,
+	}, schema.Args[0])
+	assert.Equal(t, "OTELCOL_CONFIG", schema.Args[1].EnvVar)
+	assert.Equal(t, "boolean", schema.Args[2].Type)
+	assert.Empty(t, schema.Args[4].EnvVar)
+}
+
+func TestLoadSchema_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   string
+		expected string
+	}{
+		{
+			name:     "unknown field",
+			schema:   "name: Test_TA\nargs:\n  - name: splunk_realm\n    requried: true\n",
+			expected: "yaml: unmarshal errors:\n  line 4: field requried not found in type main.SchemaArg",
+		},
+		{
+			name:     "missing name",
+			schema:   "args: []\n",
+			expected: "'name' must be specified",
+		},
+		{
+			name:     "duplicate",
+			schema:   "name: Test_TA\nargs:\n  - name: splunk_realm\n  - name: splunk_realm\n",
+			expected: "argument 'splunk_realm' is defined more than once",
+		},
+		{
+			name:     "prefix",
+			schema:   "name: Test_TA\nargs:\n  - name: realm\n",
+			expected: "argument 'realm': name must start with 'splunk_'",
+		},
+		{
+			name:     "type",
+			schema:   "name: Test_TA\nargs:\n  - name: splunk_realm\n    type: int\n",
+			expected: "argument 'splunk_realm': type must be one of string, number, boolean, got 'int'",
+		},
+		{
+			name:     "special name with env mapping",
+			schema:   "name: Test_TA\nargs:\n  - name: splunk_collector_env_vars\n",
+			expected: "argument 'splunk_collector_env_vars': names ending with '_env_vars' or '_cmd_args' require the mapping of the same name",
+		},
+		{
+			name:     "mapping name",
+			schema:   "name: Test_TA\nargs:\n  - name: splunk_args\n    mapping: cmd_args\n",
+			expected: "argument 'splunk_args': mapping 'cmd_args' requires the name to end with '_cmd_args'",
+		},
+		{
+			name:     "flag with special mapping",
+			schema:   "name: Test_TA\nargs:\n  - name: splunk_collector_cmd_args\n    mapping: cmd_args\n    flag: --set\n",
+			expected: "argument 'splunk_collector_cmd_args': 'env_var' and 'flag' are only supported by the 'env' mapping",
+		},
+		{
+			name:     "unknown mapping",
+			schema:   "name: Test_TA\nargs:\n  - name: splunk_realm\n    mapping: file\n",
+			expected: "argument 'splunk_realm': mapping must be one of env, env_vars, cmd_args, got 'file'",
+		},
+		{
+			name:     "pattern",
+			schema:   "name: Test_TA\nargs:\n  - name: splunk_realm\n    validation:\n      pattern: '['\n",
+			expected: "argument 'splunk_realm': invalid validation pattern: error parsing regexp: missing closing ]: `[`",
+		},
+		{
+			name:     "default not allowed",
+			schema:   "name: Test_TA\nargs:\n  - name: splunk_level\n    default: trace\n    validation:\n      allowed_values: [debug]\n",
+			expected: "argument 'splunk_level': default 'trace' is not an allowed value",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schemaFile := filepath.Join(t.TempDir(), "schema.yaml")
+			require.NoError(t, os.WriteFile(schemaFile, []byte(tt.schema), 0o600))
+			_, err := loadSchema(schemaFile)
+			require.EqualError(t, err, tt.expected)
+		})
+	}
+}
+
+func TestSchemeXMLRoundTrip(t *testing.T) {
+	schema, err := loadSchema(filepath.Join("testdata", "schema.yaml"))
+	require.NoError(t, err)
+	scheme := schema.scheme()
+
+	schemeXML, err := generateSchemeXML(scheme)
+	require.NoError(t, err)
+	assert.Contains(t, schemeXML, `<arg name="splunk_realm" defaultValue="">`)
+	assert.Contains(t, schemeXML, "<description>The realm to send data to</description>")
+	assert.Contains(t, schemeXML, "<use_single_instance>false</use_single_instance>")
+
+	schemeFile := filepath.Join(t.TempDir(), "scheme.xml")
+	require.NoError(t, os.WriteFile(schemeFile, []byte(schemeXML), 0o600))
+	parsed, err := parseSchemeXML(schemeFile)
+	require.NoError(t, err)
+	parsed.XMLName = scheme.XMLName
+	assert.Equal(t, scheme, parsed)
+}
+
+func TestUnescapeQuotes(t *testing.T) {
+	assert.Equal(t,
+		`<arg name="a" defaultValue="&#34;quoted&#34; 'single'"><description>"quoted" 'single' &amp; &lt;more&gt;</description></arg>`,
+		unescapeQuotes(`<arg name="a" defaultValue="&#34;quoted&#34; &#39;single&#39;"><description>&#34;quoted&#34; &#39;single&#39; &amp; &lt;more&gt;</description></arg>`))
+}
+
+func TestGenerateValidator(t *testing.T) {
+	schema, err := loadSchema(filepath.Join("testdata", "schema.yaml"))
+	require.NoError(t, err)
+
+	validator, err := generateValidator(schema, "schema.yaml", "tavalidator")
+	require.NoError(t, err)
+	source := string(validator)
+	assert.Contains(t, source, "// Code generated by ta-inputs-from-schema from schema.yaml. DO NOT EDIT.")
+	assert.Contains(t, source, "package tavalidator")
+	assert.Contains(t, source, `		Pattern:  "^[a-z0-9]+$",`)
+	assert.Contains(t, source, `		Type:    modularinput.ParamTypeBoolean,`)
+	assert.Contains(t, source, `		AllowedValues: []string{"debug", "info"},`)
+	assert.Contains(t, source, `	modularinput.WithStanzaEnvVar("splunk_config", "OTELCOL_CONFIG"),`)
+	assert.Contains(t, source, `	modularinput.WithStanzaArg("splunk_config", "--config"),`)
+	assert.NotContains(t, source, "WithStanzaEnvVar(\"splunk_realm\"")
+}
+
+func TestGenerateMarkdown(t *testing.T) {
+	schema, err := loadSchema(filepath.Join("testdata", "schema.yaml"))
+	require.NoError(t, err)
+
+	markdown := generateMarkdown(schema, "schema.yaml")
+	assert.Contains(t, markdown, "# Test_TA inputs reference")
+	assert.Contains(t, markdown, "| [`splunk_realm`](#splunk_realm) | string | yes |  | `SPLUNK_REALM` environment variable |")
+	assert.Contains(t, markdown, "| [`splunk_config`](#splunk_config) | string | no | `/etc/otel/config.yaml` | `OTELCOL_CONFIG` environment variable, or a `--config` flag per value when the stanzas set different values |")
+	assert.Contains(t, markdown, "**Realm**: The realm to send data to\n\nMust match the `^[a-z0-9]+$` regular expression.")
+	assert.Contains(t, markdown, "Allowed values: `debug`, `info`.")
+}
+
+// TestGeneratedFilesUpToDate makes sure the Splunk_TA_otel files were regenerated after changing its schema
+func TestGeneratedFilesUpToDate(t *testing.T) {
+	repoRoot := filepath.Join("..", "..")
+	assetsDir := t.TempDir()
+	outputs := schemaOutputs{
+		schemeFile:       filepath.Join(t.TempDir(), "ta_scheme.xml"),
+		docsFile:         filepath.Join(t.TempDir(), "INPUTS.md"),
+		validatorFile:    filepath.Join(t.TempDir(), "ta_validator.go"),
+		validatorPackage: "main",
+	}
+	require.NoError(t, runFromSchema(
+		filepath.Join(repoRoot, "cmd", "otelcol", "ta_schema.yaml"),
+		filepath.Join(repoRoot, "packaging", "ta-v2", "inputs_conf_global_settings.txt"),
+		"Splunk_TA_otel", assetsDir, outputs))
+
+	for generated, committed := range map[string]string{
+		filepath.Join(assetsDir, "default", "inputs.conf"):     filepath.Join(repoRoot, "packaging", "ta-v2", "assets", "default", "inputs.conf"),
+		filepath.Join(assetsDir, "README", "inputs.conf.spec"): filepath.Join(repoRoot, "packaging", "ta-v2", "assets", "README", "inputs.conf.spec"),
+		outputs.schemeFile:    filepath.Join(repoRoot, "cmd", "otelcol", "ta_scheme.xml"),
+		outputs.docsFile:      filepath.Join(repoRoot, "packaging", "ta-v2", "INPUTS.md"),
+		outputs.validatorFile: filepath.Join(repoRoot, "cmd", "otelcol", "ta_validator.go"),
+	} {
+		expected, err := os.ReadFile(generated)
+		require.NoError(t, err)
+		actual, err := os.ReadFile(committed)
+		require.NoError(t, err)
+		assert.Equal(t, string(expected), string(actual), "%s is out of date, run 'make -C packaging/ta-v2 ta-inputs-conf'", committed)
+	}
+}
@@ -22,13 +22,14 @@ import (
 )
 
 const (
-	EndpointIDAttr     = "discovery.endpoint.id"
-	ObserverIDAttr     = "discovery.observer.id"
-	ReceiverConfigAttr = "discovery.receiver.config"
-	ReceiverNameAttr   = "discovery.receiver.name"
-	ReceiverTypeAttr   = "discovery.receiver.type"
-	StatusAttr         = "discovery.status"
-	MessageAttr        = "discovery.message"
+	EndpointIDAttr        = "discovery.endpoint.id"
+	ObserverIDAttr        = "discovery.observer.id"
+	ReceiverConfigAttr    = "discovery.receiver.config"
+	ReceiverNameAttr      = "discovery.receiver.name"
+	ReceiverTypeAttr      = "discovery.receiver.type"
+	ReceiverCandidateAttr = "discovery.receiver.candidate"
+	StatusAttr            = "discovery.status"
+	MessageAttr           = "discovery.message"
 
 	OtelEntityTypeAttr        = "otel.entity.type"
 	OtelEntityAttributesAttr  = "otel.entity.attributes"
@@ -71,3 +72,13 @@ func IsValidStatus(status StatusType) (bool, error) {
 	}
 	return true, nil
 }
+
+// ComponentIDString returns the "<type>(/<name>)" form of the provided entity attribute
+// values, or an empty string if the type is invalid.
+func ComponentIDString(typeStr, name string) string {
+	typ, err := component.NewType(typeStr)
+	if err != nil {
+		return ""
+	}
+	return component.NewIDWithName(typ, name).String()
+}
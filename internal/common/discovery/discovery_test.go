@@ -43,3 +43,10 @@ func TestIsValidStatusType(t *testing.T) {
 func TestNoTypeIsEmpty(t *testing.T) {
 	require.Equal(t, "SENTINEL_FOR_DISCOVERY_RECEIVER___", NoType.String())
 }
+
+func TestComponentIDString(t *testing.T) {
+	require.Equal(t, "redis", ComponentIDString("redis", ""))
+	require.Equal(t, "redis/primary", ComponentIDString("redis", "primary"))
+	require.Empty(t, ComponentIDString("", "primary"))
+	require.Empty(t, ComponentIDString("not a type", ""))
+}
@@ -197,6 +197,7 @@ func TestDefaultComponents(t *testing.T) {
 		"google_cloud_storage",
 		"kafka",
 		"load_balancing",
+		"modular_input",
 		"nop",
 		"otlp_grpc",
 		"otlp_http",
@@ -75,6 +75,7 @@ func SetupDiscovery(_ context.Context, in *confmap.Conf) error {
 	}
 
 	setEntitiesPipelineReceivers(pipelines, discoReceivers)
+	setDiscoveryReceiversOpAMP(out, service, discoReceivers)
 
 	AddDeclarativeTelemetryResourceAttribute(service, "splunk_autodiscovery", "true")
 
@@ -164,6 +165,45 @@ func setEntitiesPipelineReceivers(pipelines map[string]any, discoReceivers []any
 	ep["receivers"] = receivers
 }
 
+// setDiscoveryReceiversOpAMP configures the discovery receivers to report their discovered endpoints
+// through the first enabled opamp extension, if any, unless they already set one.
+func setDiscoveryReceiversOpAMP(out, service map[string]any, discoReceivers []any) {
+	var opampID string
+	serviceExtensions, _ := toAnySlice(service["extensions"])
+	for _, e := range serviceExtensions {
+		id, ok := e.(string)
+		if !ok || (id != "opamp" && !strings.HasPrefix(id, "opamp/")) {
+			continue
+		}
+		if isSplunkOpAMPExtension(id) && !opampFeatureGate.IsEnabled() {
+			continue
+		}
+		opampID = id
+		break
+	}
+	if opampID == "" {
+		return
+	}
+
+	receivers, ok := out["receivers"].(map[string]any)
+	if !ok {
+		return
+	}
+	for _, r := range discoReceivers {
+		id, ok := r.(string)
+		if !ok || !strings.HasPrefix(id, "discovery") {
+			continue
+		}
+		receiver, ok := receivers[id].(map[string]any)
+		if !ok {
+			continue
+		}
+		if _, isSet := receiver["opamp"]; !isSet {
+			receiver["opamp"] = opampID
+		}
+	}
+}
+
 func appendUnique(serviceComponents, discoComponents []any) []any {
 	existing := map[any]struct{}{}
 	for _, e := range serviceComponents {
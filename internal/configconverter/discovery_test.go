@@ -323,6 +323,46 @@ func TestContinuousDiscoveryWithEntitiesPipeline(t *testing.T) {
 	require.Equal(t, expected.ToStringMap(), in.ToStringMap())
 }
 
+func TestContinuousDiscoveryOpAMP(t *testing.T) {
+	in := confFromYaml(t, `receivers:
+  discovery/one:
+    watch_observers: [host_observer]
+  discovery/two:
+    watch_observers: [docker_observer]
+    opamp: opamp/custom
+  recv/one:
+service:
+  extensions: [health_check, opamp]
+  pipelines:
+    metrics:
+      receivers: [recv/one]
+  receivers/splunk.discovery: [discovery/one, discovery/two]
+`)
+
+	expected := confFromYaml(t, `receivers:
+  discovery/one:
+    watch_observers: [host_observer]
+    opamp: opamp
+  discovery/two:
+    watch_observers: [docker_observer]
+    opamp: opamp/custom
+  recv/one:
+service:
+  extensions: [health_check, opamp]
+  pipelines:
+    metrics:
+      receivers: [recv/one, discovery/one, discovery/two]
+  telemetry:
+    resource:
+      attributes:
+        - name: splunk_autodiscovery
+          value: "true"
+`)
+
+	require.NoError(t, SetupDiscovery(context.Background(), in))
+	require.Equal(t, expected.ToStringMap(), in.ToStringMap())
+}
+
 func confFromYaml(tb testing.TB, content string) *confmap.Conf {
 	var conf map[string]any
 	if err := yaml.Unmarshal([]byte(content), &conf); err != nil {
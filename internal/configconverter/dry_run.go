@@ -86,7 +86,7 @@ func (dr *DryRun) Convert(ctx context.Context, _ *confmap.Conf) error {
 		}
 	}
 	dr.Unlock() // not deferred because we are exiting
-	out, err := yaml.Marshal(cm.ToStringMap())
+	out, err := yaml.Marshal(Redact(cm.ToStringMap()))
 	if err != nil {
 		panic(fmt.Errorf("failed marshaling --dry-run config: %w", err))
 	}
@@ -0,0 +1,294 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configsource"
+	configsourceprovider "github.com/signalfx/splunk-otel-collector/internal/confmapprovider/configsource"
+)
+
+var (
+	_ confmap.Converter            = (*Explain)(nil)
+	_ configsourceprovider.Hook    = (*Explain)(nil)
+	_ configsourceprovider.URIHook = (*Explain)(nil)
+
+	// referencePattern matches ${scheme:selector} and ${ENV_VAR} references,
+	// disregarding the $${...} escaped ones.
+	referencePattern = regexp.MustCompile(`(^|[^$])\$\{([^{}]+)\}`)
+)
+
+// Explain is the --explain counterpart of DryRun. It prints the effective
+// configuration with each value annotated with its origin: the file and line
+// it was set in, the --set flag, the converter that set it, and the env vars
+// and config sources it references. Since values are shown before expansion,
+// the content retrieved from env vars and config sources isn't printed. Values
+// are redacted according to the RedactionPolicy like the --dry-run ones.
+type Explain struct {
+	*sync.Mutex
+	retrieved          []explainRetrieval
+	converterFactories []confmap.ConverterFactory
+	enabled            bool
+}
+
+type explainRetrieval struct {
+	config map[string]any
+	uri    string
+}
+
+func NewExplain(enabled bool, cf []confmap.ConverterFactory) *Explain {
+	return &Explain{
+		Mutex:              &sync.Mutex{},
+		enabled:            enabled,
+		converterFactories: cf,
+	}
+}
+
+func (e *Explain) OnNew() {}
+
+// OnRetrieve is a noop since the retrieved configs are accrued with their URI by OnRetrieveURI().
+func (e *Explain) OnRetrieve(string, map[string]any) {}
+
+func (e *Explain) OnRetrieveURI(uri string, retrieved map[string]any) {
+	if e == nil || !e.enabled {
+		return
+	}
+	e.Lock()
+	defer e.Unlock()
+	e.retrieved = append(e.retrieved, explainRetrieval{uri: uri, config: retrieved})
+}
+
+func (e *Explain) OnShutdown() {}
+
+// Convert disregards the provided *confmap.Conf so that it will use
+// unexpanded values (env vars, config source directives) as
+// accrued by OnRetrieveURI() calls.
+func (e *Explain) Convert(ctx context.Context, _ *confmap.Conf) error {
+	if e == nil || !e.enabled {
+		return nil
+	}
+	out, err := e.explain(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "%s", out)
+	os.Stdout.Sync()
+	os.Exit(0)
+	return nil
+}
+
+// explain merges the retrieved configs and runs the converters like the
+// service would, tracking which of them last set each leaf value.
+func (e *Explain) explain(ctx context.Context) ([]byte, error) {
+	e.Lock()
+	defer e.Unlock()
+
+	cm := confmap.New()
+	origins := map[string]string{}
+	for _, r := range e.retrieved {
+		label, lines := uriOrigin(r.uri)
+		for path := range configsource.Leaves(r.config) {
+			origin := label
+			if line, ok := lines[path]; ok {
+				origin = fmt.Sprintf("%s:%d", label, line)
+			}
+			origins[path] = origin
+		}
+		if err := cm.Merge(confmap.NewFromStringMap(r.config)); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, cf := range e.converterFactories {
+		// No need to provide a logger for the explanation.
+		c := cf.Create(confmap.ConverterSettings{Logger: zap.NewNop()})
+		before := configsource.Leaves(cm.ToStringMap())
+		if err := c.Convert(ctx, cm); err != nil {
+			return nil, fmt.Errorf("error finalizing --explain with converter %v: %w", c, err)
+		}
+		origin := converterOrigin(c)
+		for path, value := range configsource.Leaves(cm.ToStringMap()) {
+			if prev, ok := before[path]; !ok || !reflect.DeepEqual(prev, value) {
+				origins[path] = origin
+			}
+		}
+	}
+
+	final := cm.ToStringMap()
+	configSources := map[string]struct{}{}
+	if cs, ok := final["config_sources"].(map[string]any); ok {
+		for name := range cs {
+			configSources[name] = struct{}{}
+		}
+	}
+
+	// references are described from the unredacted values
+	finalLeaves := configsource.Leaves(final)
+	root, err := explainNode(Redact(final), "", func(path string) string {
+		var notes []string
+		if origin, ok := origins[path]; ok {
+			notes = append(notes, origin)
+		}
+		if s, ok := finalLeaves[path].(string); ok {
+			notes = append(notes, referenceOrigins(s, configSources)...)
+		}
+		return strings.Join(notes, "; ")
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed marshaling --explain config: %w", err)
+	}
+
+	buf := &bytes.Buffer{}
+	enc := yaml.NewEncoder(buf)
+	enc.SetIndent(2)
+	if err = enc.Encode(root); err != nil {
+		return nil, fmt.Errorf("failed marshaling --explain config: %w", err)
+	}
+	if err = enc.Close(); err != nil {
+		return nil, fmt.Errorf("failed marshaling --explain config: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// uriOrigin returns the label of a retrieved config URI and, when its content
+// can be read again, the line each leaf path was set on.
+func uriOrigin(uri string) (string, map[string]int) {
+	scheme, location, _ := strings.Cut(uri, ":")
+	switch scheme {
+	case "file":
+		content, err := os.ReadFile(location)
+		if err != nil {
+			return location, nil
+		}
+		return location, yamlLines(content)
+	case "env":
+		return "env var " + location, yamlLines([]byte(os.Getenv(location)))
+	default:
+		return uri, nil
+	}
+}
+
+// yamlLines returns the line of each leaf path set in the provided YAML document.
+func yamlLines(content []byte) map[string]int {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil || len(doc.Content) == 0 {
+		return nil
+	}
+	lines := map[string]int{}
+	var walk func(node *yaml.Node, prefix string)
+	walk = func(node *yaml.Node, prefix string) {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			path := configsource.JoinPath(prefix, key.Value)
+			if value.Kind == yaml.MappingNode && len(value.Content) > 0 {
+				walk(value, path)
+				continue
+			}
+			lines[path] = key.Line
+		}
+	}
+	if root := doc.Content[0]; root.Kind == yaml.MappingNode {
+		walk(root, "")
+	}
+	return lines
+}
+
+// converterOrigin describes the changes made by the provided converter.
+func converterOrigin(c confmap.Converter) string {
+	switch v := c.(type) {
+	case *converter:
+		return "--set flag"
+	case *conv:
+		name := runtime.FuncForPC(reflect.ValueOf(v.convertFunc).Pointer()).Name()
+		return "converter " + name[strings.LastIndex(name, ".")+1:]
+	default:
+		return fmt.Sprintf("converter %T", c)
+	}
+}
+
+// referenceOrigins describes the env vars, config sources, and providers
+// referenced by the provided value, whose resolved content is redacted.
+func referenceOrigins(value string, configSources map[string]struct{}) []string {
+	var origins []string
+	for _, match := range referencePattern.FindAllStringSubmatch(value, -1) {
+		reference := strings.TrimSpace(match[2])
+		scheme, selector, found := strings.Cut(reference, ":")
+		switch {
+		case !found:
+			origins = append(origins, "env var "+reference)
+		case scheme == "env":
+			origins = append(origins, "env var "+selector)
+		default:
+			kind := "provider"
+			if _, ok := configSources[scheme]; ok {
+				kind = "config source"
+			}
+			origins = append(origins, fmt.Sprintf("%s %s (value redacted)", kind, scheme))
+		}
+	}
+	return origins
+}
+
+// explainNode returns the YAML node of the provided value with sorted keys and
+// each leaf commented with its origin.
+func explainNode(value any, path string, origin func(path string) string) (*yaml.Node, error) {
+	m, ok := value.(map[string]any)
+	if !ok || len(m) == 0 {
+		node := &yaml.Node{}
+		if err := node.Encode(value); err != nil {
+			return nil, err
+		}
+		return node, nil
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, k := range keys {
+		childPath := configsource.JoinPath(path, k)
+		child, err := explainNode(m[k], childPath, origin)
+		if err != nil {
+			return nil, err
+		}
+		key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: k}
+		if child.Kind != yaml.MappingNode || len(child.Content) == 0 {
+			comment := origin(childPath)
+			if child.Kind == yaml.ScalarNode || len(child.Content) == 0 {
+				child.LineComment = comment
+			} else {
+				key.LineComment = comment
+			}
+		}
+		node.Content = append(node.Content, key, child)
+	}
+	return node, nil
+}
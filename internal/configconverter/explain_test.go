@@ -0,0 +1,98 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+)
+
+func addBatchProcessor(_ context.Context, cfg *confmap.Conf) error {
+	return cfg.Merge(confmap.NewFromStringMap(map[string]any{
+		"processors": map[string]any{"batch": map[string]any{}},
+		"service": map[string]any{
+			"pipelines": map[string]any{
+				"metrics": map[string]any{"processors": []any{"batch"}},
+			},
+		},
+	}))
+}
+
+func TestExplain(t *testing.T) {
+	configPath := filepath.Join("testdata", "explain", "config.yaml")
+	cfg, err := confmaptest.LoadConf(configPath)
+	require.NoError(t, err)
+
+	t.Setenv("SPLUNK_CONFIG_YAML", "exporters:\n  signalfx:\n    realm: eu0\n")
+	envCfg := confmap.NewFromStringMap(map[string]any{
+		"exporters": map[string]any{"signalfx": map[string]any{"realm": "eu0"}},
+	})
+
+	e := NewExplain(true, []confmap.ConverterFactory{
+		ConverterFactoryFromConverter(NewOverwritePropertiesConverter([]string{"receivers.otlp.protocols.grpc.endpoint=0.0.0.0:4317"})),
+		ConverterFactoryFromFunc(addBatchProcessor),
+	})
+	e.OnNew()
+	defer e.OnShutdown()
+	e.OnRetrieve("file", cfg.ToStringMap())
+	e.OnRetrieveURI("file:"+configPath, cfg.ToStringMap())
+	e.OnRetrieveURI("env:SPLUNK_CONFIG_YAML", envCfg.ToStringMap())
+
+	out, err := e.explain(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, `config_sources:
+  vault:
+    auth:
+      token: <redacted> # `+configPath+`:6; env var VAULT_TOKEN
+    endpoint: https://vault:8200 # `+configPath+`:3
+    path: secret/data/kv # `+configPath+`:4
+exporters:
+  signalfx:
+    access_token: <redacted> # `+configPath+`:14; config source vault (value redacted)
+    realm: eu0 # env var SPLUNK_CONFIG_YAML:3
+processors:
+  batch: {} # converter addBatchProcessor
+receivers:
+  otlp:
+    protocols:
+      grpc:
+        endpoint: 0.0.0.0:4317 # --set flag
+service:
+  pipelines:
+    metrics:
+      exporters: # `+configPath+`:20
+        - signalfx
+      processors: # converter addBatchProcessor
+        - batch
+      receivers: # `+configPath+`:19
+        - otlp
+`, string(out))
+}
+
+func TestExplainDisabled(t *testing.T) {
+	e := NewExplain(false, nil)
+	e.OnRetrieveURI("file:config.yaml", map[string]any{"key": "value"})
+	assert.Empty(t, e.retrieved)
+	require.NoError(t, e.Convert(context.Background(), confmap.New()))
+
+	var nilExplain *Explain
+	require.NoError(t, nilExplain.Convert(context.Background(), confmap.New()))
+}
@@ -17,10 +17,8 @@ package configconverter
 import (
 	"context"
 	"expvar"
-	"strings"
 	"sync"
 
-	"github.com/spf13/cast"
 	"go.opentelemetry.io/collector/confmap"
 	"gopkg.in/yaml.v2"
 
@@ -61,14 +59,23 @@ func GetExpvarConverter() *ExpvarConverter {
 			instance := expvarConverterInstance
 			instance.effectiveMutex.RLock()
 			defer instance.effectiveMutex.RUnlock()
-			configYAML, _ := yaml.Marshal(simpleRedact(instance.effective))
+			// the values resolved from the initial config directives are always redacted
+			instance.initialMutex.RLock()
+			unresolved := make([]map[string]any, 0, len(instance.initial))
+			for _, cfg := range instance.initial {
+				if m, ok := cfg.(map[string]any); ok {
+					unresolved = append(unresolved, m)
+				}
+			}
+			instance.initialMutex.RUnlock()
+			configYAML, _ := yaml.Marshal(redactResolved(instance.effective, unresolved...))
 			return string(configYAML)
 		}))
 		expvar.Publish("splunk.config.initial", expvar.Func(func() any {
 			instance := expvarConverterInstance
 			instance.initialMutex.RLock()
 			defer instance.initialMutex.RUnlock()
-			configYAML, _ := yaml.Marshal(simpleRedact(instance.initial))
+			configYAML, _ := yaml.Marshal(Redact(instance.initial))
 			return string(configYAML)
 		}))
 	})
@@ -92,50 +99,3 @@ func (e *ExpvarConverter) Convert(_ context.Context, conf *confmap.Conf) error {
 	e.effective = conf.ToStringMap()
 	return nil
 }
-
-func simpleRedact(config map[string]any) map[string]any {
-	redactedConfig := make(map[string]any)
-	for k, v := range config {
-		switch value := v.(type) {
-		case string:
-			if shouldRedactKey(k) {
-				v = "<redacted>"
-			}
-		case map[string]any:
-			v = simpleRedact(value)
-		case map[any]any:
-			v = simpleRedact(cast.ToStringMap(value))
-		}
-
-		redactedConfig[k] = v
-	}
-
-	return redactedConfig
-}
-
-// shouldRedactKey applies a simple check to see if the contents of the given key
-// should be redacted or not.
-func shouldRedactKey(k string) bool {
-	keyFragments := []string{
-		"access",
-		"api_key",
-		"apikey",
-		"auth",
-		"credential",
-		"creds",
-		"login",
-		"password",
-		"pwd",
-		"token", // Any key containing "token" will be redacted
-		"user",
-	}
-
-	lowerCaseKey := strings.ToLower(k)
-	for _, fragment := range keyFragments {
-		if strings.Contains(lowerCaseKey, fragment) {
-			return true
-		}
-	}
-
-	return false
-}
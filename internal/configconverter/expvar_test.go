@@ -167,7 +167,7 @@ func TestExpvarConverter_SingletonPersistence(t *testing.T) {
 }
 
 func TestExpvarConverter_SimpleRedaction(t *testing.T) {
-	// Test that simpleRedact correctly redacts sensitive information
+	// Test that the default RedactionPolicy correctly redacts sensitive information
 	// uses all expected keys from the redactKeys map
 	anchorsToBeRedacted := []string{
 		"access",
@@ -194,7 +194,7 @@ func TestExpvarConverter_SimpleRedaction(t *testing.T) {
 	converter.initial = unredacted
 	converter.effective = unredacted
 
-	redacted := simpleRedact(unredacted)
+	redacted := Redact(unredacted)
 
 	initialConfigJSONStr := expvar.Get("splunk.config.initial")
 	var initialConfigYAMLStr string
@@ -210,3 +210,18 @@ func TestExpvarConverter_SimpleRedaction(t *testing.T) {
 	require.NoError(t, yaml.Unmarshal([]byte(effectiveConfigYAMLStr), &effectiveConfigMap))
 	assert.Equal(t, redacted, effectiveConfigMap)
 }
+
+func TestExpvarConverter_RedactsResolvedValues(t *testing.T) {
+	converter := GetExpvarConverter()
+	converter.initial = map[string]any{
+		"file": map[string]any{"endpoint": "${vault:secret/data/otlp[endpoint]}", "timeout": "10s"},
+	}
+	converter.effective = map[string]any{"endpoint": "collector:4317", "timeout": "10s"}
+
+	effectiveConfigJSONStr := expvar.Get("splunk.config.effective")
+	var effectiveConfigYAMLStr string
+	require.NoError(t, json.Unmarshal([]byte(effectiveConfigJSONStr.String()), &effectiveConfigYAMLStr))
+	var effectiveConfigMap map[string]any
+	require.NoError(t, yaml.Unmarshal([]byte(effectiveConfigYAMLStr), &effectiveConfigMap))
+	assert.Equal(t, map[string]any{"endpoint": "<redacted>", "timeout": "10s"}, effectiveConfigMap)
+}
@@ -0,0 +1,350 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configsource"
+	configsourceprovider "github.com/signalfx/splunk-otel-collector/internal/confmapprovider/configsource"
+)
+
+const (
+	LintSeverityWarning = "warning"
+	LintSeverityError   = "error"
+
+	LintRuleUnusedComponent      = "unused-component"
+	LintRuleMissingMemoryLimiter = "missing-memory-limiter"
+	LintRuleMissingBatch         = "missing-batch-processor"
+	LintRuleDeprecatedComponent  = "deprecated-component"
+	LintRuleDeprecatedURL        = "deprecated-signalfx-url"
+	LintRulePlaintextToken       = "plaintext-token"
+)
+
+var (
+	_ confmap.ConverterFactory     = (*LintWarnings)(nil)
+	_ configsourceprovider.Hook    = (*LintWarnings)(nil)
+	_ configsourceprovider.URIHook = (*LintWarnings)(nil)
+
+	lintComponentKinds = []string{"receivers", "processors", "exporters", "connectors", "extensions"}
+
+	// deprecatedComponents are the component types to migrate away from by kind.
+	deprecatedComponents = map[string]map[string]string{
+		"receivers": {
+			"smartagent":      "Smart Agent monitors are deprecated, use the equivalent native receiver when available",
+			"scripted_inputs": "the scripted_inputs receiver is deprecated and will be removed in a future release",
+		},
+	}
+
+	// deprecatedHostPattern matches the signalfx.com API, ingest and stream endpoint hosts, e.g. "ingest.us0.signalfx.com".
+	deprecatedHostPattern = regexp.MustCompile(`^(api|ingest|stream)(\.[a-z0-9]+)?\.signalfx\.com$`)
+
+	tokenKeyPattern = regexp.MustCompile(`(?i)token`)
+	// ingestTokenPattern matches Splunk Observability Cloud access tokens and Splunk HEC tokens.
+	ingestTokenPattern = regexp.MustCompile(`^([A-Za-z0-9_-]{22}|[0-9A-Fa-f]{8}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{12})$`)
+)
+
+// LintFinding is a risky or dead part of a config reported by Lint.
+type LintFinding struct {
+	Rule     string `json:"rule" yaml:"rule"`
+	Severity string `json:"severity" yaml:"severity"`
+	// Path is the confmap.KeyDelimiter separated path of the finding in the config.
+	Path    string `json:"path" yaml:"path"`
+	Message string `json:"message" yaml:"message"`
+}
+
+func (f LintFinding) String() string {
+	return fmt.Sprintf("%s [%s] %s: %s", f.Severity, f.Rule, f.Path, f.Message)
+}
+
+// Lint returns the findings for the provided config, sorted by path and rule. Plain text
+// ingest tokens are looked up in the unresolved configs when provided, since resolved
+// config source and env var values are expected to be secrets.
+func Lint(conf *confmap.Conf, unresolved ...map[string]any) []LintFinding {
+	if conf == nil {
+		return nil
+	}
+	config := conf.ToStringMap()
+	var findings []LintFinding
+	findings = append(findings, lintUnusedComponents(config)...)
+	findings = append(findings, lintPipelineProcessors(config)...)
+	findings = append(findings, lintDeprecatedComponents(config)...)
+	findings = append(findings, lintDeprecatedURLs(config, "")...)
+	if len(unresolved) == 0 {
+		unresolved = []map[string]any{config}
+	}
+	seen := map[string]struct{}{}
+	for _, u := range unresolved {
+		for _, finding := range lintPlaintextTokens(u, "") {
+			if _, ok := seen[finding.Path]; !ok {
+				seen[finding.Path] = struct{}{}
+				findings = append(findings, finding)
+			}
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Path != findings[j].Path {
+			return findings[i].Path < findings[j].Path
+		}
+		return findings[i].Rule < findings[j].Rule
+	})
+	return findings
+}
+
+func lintUnusedComponents(config map[string]any) []LintFinding {
+	service, _ := config["service"].(map[string]any)
+	used := map[string]map[string]struct{}{}
+	for _, kind := range lintComponentKinds {
+		used[kind] = map[string]struct{}{}
+	}
+	for _, id := range stringList(service["extensions"]) {
+		used["extensions"][id] = struct{}{}
+	}
+	pipelines, _ := service["pipelines"].(map[string]any)
+	for _, p := range pipelines {
+		pipeline, _ := p.(map[string]any)
+		for _, kind := range []string{"receivers", "processors", "exporters"} {
+			for _, id := range stringList(pipeline[kind]) {
+				used[kind][id] = struct{}{}
+				// connectors are used as both exporters and receivers
+				if kind != "processors" {
+					used["connectors"][id] = struct{}{}
+				}
+			}
+		}
+	}
+
+	var findings []LintFinding
+	for _, kind := range lintComponentKinds {
+		components, _ := config[kind].(map[string]any)
+		for id := range components {
+			if _, ok := used[kind][id]; ok {
+				continue
+			}
+			where := "any pipeline"
+			if kind == "extensions" {
+				where = "service::extensions"
+			}
+			findings = append(findings, LintFinding{
+				Rule:     LintRuleUnusedComponent,
+				Severity: LintSeverityWarning,
+				Path:     kind + confmap.KeyDelimiter + id,
+				Message:  fmt.Sprintf("%s is defined but not used in %s", id, where),
+			})
+		}
+	}
+	return findings
+}
+
+func lintPipelineProcessors(config map[string]any) []LintFinding {
+	service, _ := config["service"].(map[string]any)
+	pipelines, _ := service["pipelines"].(map[string]any)
+	var findings []LintFinding
+	for id, p := range pipelines {
+		pipeline, _ := p.(map[string]any)
+		var hasMemoryLimiter, hasBatch bool
+		for _, processor := range stringList(pipeline["processors"]) {
+			switch componentType(processor) {
+			case "memory_limiter":
+				hasMemoryLimiter = true
+			case "batch":
+				hasBatch = true
+			}
+		}
+		path := strings.Join([]string{"service", "pipelines", id}, confmap.KeyDelimiter)
+		if !hasMemoryLimiter {
+			findings = append(findings, LintFinding{
+				Rule:     LintRuleMissingMemoryLimiter,
+				Severity: LintSeverityWarning,
+				Path:     path,
+				Message:  "pipeline has no memory_limiter processor to prevent out of memory situations",
+			})
+		}
+		if !hasBatch {
+			findings = append(findings, LintFinding{
+				Rule:     LintRuleMissingBatch,
+				Severity: LintSeverityWarning,
+				Path:     path,
+				Message:  "pipeline has no batch processor to reduce the number of outgoing requests",
+			})
+		}
+	}
+	return findings
+}
+
+func lintDeprecatedComponents(config map[string]any) []LintFinding {
+	var findings []LintFinding
+	for kind, deprecated := range deprecatedComponents {
+		components, _ := config[kind].(map[string]any)
+		for id := range components {
+			if message, ok := deprecated[componentType(id)]; ok {
+				findings = append(findings, LintFinding{
+					Rule:     LintRuleDeprecatedComponent,
+					Severity: LintSeverityWarning,
+					Path:     kind + confmap.KeyDelimiter + id,
+					Message:  message,
+				})
+			}
+		}
+	}
+	return findings
+}
+
+func lintDeprecatedURLs(value any, path string) []LintFinding {
+	var findings []LintFinding
+	switch v := value.(type) {
+	case map[string]any:
+		for k, e := range v {
+			findings = append(findings, lintDeprecatedURLs(e, configsource.JoinPath(path, k))...)
+		}
+	case []any:
+		for _, e := range v {
+			findings = append(findings, lintDeprecatedURLs(e, path)...)
+		}
+	case string:
+		if host := endpointHost(v); deprecatedHostPattern.MatchString(host) {
+			findings = append(findings, LintFinding{
+				Rule:     LintRuleDeprecatedURL,
+				Severity: LintSeverityWarning,
+				Path:     path,
+				Message:  fmt.Sprintf(`the %q endpoint is deprecated, use the "observability.splunkcloud.com" one instead`, host),
+			})
+		}
+	}
+	return findings
+}
+
+// endpointHost returns the lowercase host of an endpoint set as a URL, a "host:port" or a host.
+func endpointHost(endpoint string) string {
+	endpoint = strings.TrimSpace(endpoint)
+	if u, err := url.Parse(endpoint); err == nil && u.Host != "" {
+		return strings.ToLower(u.Hostname())
+	}
+	if host, _, err := net.SplitHostPort(endpoint); err == nil {
+		return strings.ToLower(host)
+	}
+	return strings.ToLower(endpoint)
+}
+
+func lintPlaintextTokens(config map[string]any, path string) []LintFinding {
+	var findings []LintFinding
+	for k, v := range config {
+		childPath := configsource.JoinPath(path, k)
+		switch value := v.(type) {
+		case map[string]any:
+			findings = append(findings, lintPlaintextTokens(value, childPath)...)
+		case string:
+			if tokenKeyPattern.MatchString(k) && ingestTokenPattern.MatchString(strings.TrimSpace(value)) {
+				findings = append(findings, LintFinding{
+					Rule:     LintRulePlaintextToken,
+					Severity: LintSeverityError,
+					Path:     childPath,
+					Message:  "ingest token is set as a plain text literal, use an env var or config source instead",
+				})
+			}
+		}
+	}
+	return findings
+}
+
+func stringList(value any) []string {
+	list, _ := value.([]any)
+	out := make([]string, 0, len(list))
+	for _, e := range list {
+		if s, ok := e.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// componentType returns the type of a component ID in the "type" or "type/name" format.
+func componentType(id string) string {
+	t, _, _ := strings.Cut(id, "/")
+	return t
+}
+
+// LintWarnings is a converter factory logging the Lint findings of the effective config at startup
+// when enabled, i.e. with the --lint-warnings flag. The plain text ingest tokens are looked up in the
+// unresolved configs accrued by OnRetrieveURI() calls.
+type LintWarnings struct {
+	unresolved map[string]map[string]any
+	mutex      sync.Mutex
+	enabled    bool
+}
+
+func NewLintWarnings(enabled bool) *LintWarnings {
+	return &LintWarnings{unresolved: map[string]map[string]any{}, enabled: enabled}
+}
+
+func (l *LintWarnings) OnNew() {}
+
+// OnRetrieve is a noop since the retrieved configs are accrued with their URI by OnRetrieveURI().
+func (l *LintWarnings) OnRetrieve(string, map[string]any) {}
+
+func (l *LintWarnings) OnRetrieveURI(uri string, retrieved map[string]any) {
+	if !l.enabled {
+		return
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.unresolved[uri] = retrieved
+}
+
+func (l *LintWarnings) OnShutdown() {}
+
+func (l *LintWarnings) Create(settings confmap.ConverterSettings) confmap.Converter {
+	logger := settings.Logger
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &lintConverter{warnings: l, logger: logger}
+}
+
+type lintConverter struct {
+	warnings *LintWarnings
+	logger   *zap.Logger
+}
+
+func (c *lintConverter) Convert(_ context.Context, conf *confmap.Conf) error {
+	if !c.warnings.enabled {
+		return nil
+	}
+	c.warnings.mutex.Lock()
+	unresolved := make([]map[string]any, 0, len(c.warnings.unresolved))
+	for _, u := range c.warnings.unresolved {
+		unresolved = append(unresolved, u)
+	}
+	c.warnings.mutex.Unlock()
+
+	for _, finding := range Lint(conf, unresolved...) {
+		c.logger.Warn("Config lint finding",
+			zap.String("rule", finding.Rule),
+			zap.String("severity", finding.Severity),
+			zap.String("path", finding.Path),
+			zap.String("message", finding.Message))
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+var expectedLintFindings = []LintFinding{
+	{Rule: LintRuleUnusedComponent, Severity: LintSeverityWarning, Path: "connectors::forward", Message: "forward is defined but not used in any pipeline"},
+	{Rule: LintRulePlaintextToken, Severity: LintSeverityError, Path: "exporters::signalfx::access_token", Message: "ingest token is set as a plain text literal, use an env var or config source instead"},
+	{Rule: LintRuleDeprecatedURL, Severity: LintSeverityWarning, Path: "exporters::signalfx::api_url", Message: `the "api.us0.signalfx.com" endpoint is deprecated, use the "observability.splunkcloud.com" one instead`},
+	{Rule: LintRuleUnusedComponent, Severity: LintSeverityWarning, Path: "extensions::zpages", Message: "zpages is defined but not used in service::extensions"},
+	{Rule: LintRuleUnusedComponent, Severity: LintSeverityWarning, Path: "receivers::hostmetrics", Message: "hostmetrics is defined but not used in any pipeline"},
+	{Rule: LintRuleDeprecatedComponent, Severity: LintSeverityWarning, Path: "receivers::smartagent/cpu", Message: "Smart Agent monitors are deprecated, use the equivalent native receiver when available"},
+	{Rule: LintRuleMissingMemoryLimiter, Severity: LintSeverityWarning, Path: "service::pipelines::logs", Message: "pipeline has no memory_limiter processor to prevent out of memory situations"},
+}
+
+func TestLint(t *testing.T) {
+	conf, err := confmaptest.LoadConf(filepath.Join("testdata", "lint", "config.yaml"))
+	require.NoError(t, err)
+
+	findings := Lint(conf)
+	assert.Equal(t, expectedLintFindings, findings)
+	assert.Equal(t, "error [plaintext-token] exporters::signalfx::access_token: ingest token is set as a plain text literal, use an env var or config source instead", findings[1].String())
+
+	assert.Empty(t, Lint(nil))
+}
+
+func TestLintResolvedTokens(t *testing.T) {
+	resolved := confmap.NewFromStringMap(map[string]any{
+		"exporters": map[string]any{
+			"splunk_hec": map[string]any{"token": "00000000-0000-0000-0000-000000000000"},
+		},
+		"service": map[string]any{
+			"pipelines": map[string]any{
+				"logs": map[string]any{
+					"processors": []any{"memory_limiter", "batch/logs"},
+					"exporters":  []any{"splunk_hec"},
+				},
+			},
+		},
+	})
+	// tokens resolved from env vars and config sources aren't reported
+	unresolved := map[string]any{
+		"exporters": map[string]any{
+			"splunk_hec": map[string]any{"token": "${SPLUNK_HEC_TOKEN}"},
+		},
+	}
+	assert.Empty(t, Lint(resolved, unresolved))
+	assert.Equal(t, []LintFinding{
+		{Rule: LintRulePlaintextToken, Severity: LintSeverityError, Path: "exporters::splunk_hec::token", Message: "ingest token is set as a plain text literal, use an env var or config source instead"},
+	}, Lint(resolved))
+}
+
+func TestLintWarnings(t *testing.T) {
+	conf, err := confmaptest.LoadConf(filepath.Join("testdata", "lint", "config.yaml"))
+	require.NoError(t, err)
+
+	core, logs := observer.New(zapcore.WarnLevel)
+	lw := NewLintWarnings(true)
+	lw.OnNew()
+	defer lw.OnShutdown()
+	lw.OnRetrieveURI("file:"+filepath.Join("testdata", "lint", "config.yaml"), conf.ToStringMap())
+	require.NoError(t, lw.Create(confmap.ConverterSettings{Logger: zap.New(core)}).Convert(context.Background(), conf))
+
+	entries := logs.All()
+	require.Len(t, entries, len(expectedLintFindings))
+	for i, entry := range entries {
+		assert.Equal(t, "Config lint finding", entry.Message)
+		assert.Equal(t, map[string]any{
+			"rule":     expectedLintFindings[i].Rule,
+			"severity": expectedLintFindings[i].Severity,
+			"path":     expectedLintFindings[i].Path,
+			"message":  expectedLintFindings[i].Message,
+		}, entry.ContextMap())
+	}
+
+	require.NoError(t, lw.Create(confmap.ConverterSettings{}).Convert(context.Background(), conf))
+}
+
+func TestLintWarningsDisabled(t *testing.T) {
+	conf, err := confmaptest.LoadConf(filepath.Join("testdata", "lint", "config.yaml"))
+	require.NoError(t, err)
+
+	core, logs := observer.New(zapcore.WarnLevel)
+	lw := NewLintWarnings(false)
+	lw.OnRetrieveURI("file:"+filepath.Join("testdata", "lint", "config.yaml"), conf.ToStringMap())
+	require.NoError(t, lw.Create(confmap.ConverterSettings{Logger: zap.New(core)}).Convert(context.Background(), conf))
+	assert.Empty(t, logs.All())
+	assert.Empty(t, lw.unresolved)
+}
+
+func TestLintDeprecatedURLs(t *testing.T) {
+	for _, endpoint := range []string{
+		"https://ingest.us0.signalfx.com/v2/trace",
+		"https://API.signalfx.com",
+		"stream.eu0.signalfx.com:443",
+		"ingest.signalfx.com",
+	} {
+		assert.Len(t, lintDeprecatedURLs(map[string]any{"endpoint": endpoint}, ""), 1, endpoint)
+	}
+	for _, endpoint := range []string{
+		"https://ingest.us0.observability.splunkcloud.com",
+		"https://docs.signalfx.com/en/latest/",
+		"https://example.com/?redirect=api.signalfx.com",
+		"notsignalfx.com",
+		"support@signalfx.com",
+	} {
+		assert.Empty(t, lintDeprecatedURLs(map[string]any{"endpoint": endpoint}, ""), endpoint)
+	}
+}
+
+func TestLintWarningsMultipleConfigFiles(t *testing.T) {
+	first := map[string]any{
+		"exporters": map[string]any{
+			"splunk_hec": map[string]any{"token": "00000000-0000-0000-0000-000000000000"},
+		},
+	}
+	second := map[string]any{
+		"exporters": map[string]any{
+			"splunk_hec": map[string]any{"endpoint": "https://splunk:8088/services/collector"},
+		},
+	}
+	resolved := confmap.NewFromStringMap(map[string]any{
+		"exporters": map[string]any{
+			"splunk_hec": map[string]any{
+				"token":    "00000000-0000-0000-0000-000000000000",
+				"endpoint": "https://splunk:8088/services/collector",
+			},
+		},
+	})
+
+	core, logs := observer.New(zapcore.WarnLevel)
+	lw := NewLintWarnings(true)
+	lw.OnRetrieve("file", first)
+	lw.OnRetrieveURI("file:/etc/otel/collector/first.yaml", first)
+	lw.OnRetrieve("file", second)
+	lw.OnRetrieveURI("file:/etc/otel/collector/second.yaml", second)
+	require.NoError(t, lw.Create(confmap.ConverterSettings{Logger: zap.New(core)}).Convert(context.Background(), resolved))
+
+	tokenFindings := logs.FilterField(zap.String("rule", LintRulePlaintextToken)).All()
+	require.Len(t, tokenFindings, 1)
+	assert.Equal(t, "exporters::splunk_hec::token", tokenFindings[0].ContextMap()["path"])
+}
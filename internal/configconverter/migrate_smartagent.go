@@ -0,0 +1,302 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/featuregate"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+
+	"github.com/signalfx/splunk-otel-collector/internal/confmapprovider/configsource"
+)
+
+const (
+	migrateSmartAgentFeatureGateID = "splunk.smartagent.migrateToNativeReceivers"
+	smartagentReceiverType         = "smartagent"
+)
+
+var migrateSmartAgentFeatureGate = featuregate.GlobalRegistry().MustRegister(
+	migrateSmartAgentFeatureGateID,
+	featuregate.StageAlpha,
+	featuregate.WithRegisterDescription("When enabled, the smartagent receivers configured with a monitor that has a native receiver "+
+		"equivalent are replaced by that receiver at startup, provided all of their settings can be translated. "+
+		"Use the --migrate-smartagent flag to review the migration beforehand."),
+	featuregate.WithRegisterFromVersion("v0.159.0"),
+)
+
+var (
+	_ confmap.Converter = (*SmartAgentMigrationReport)(nil)
+	_ configsource.Hook = (*SmartAgentMigrationReport)(nil)
+)
+
+// SmartAgentMigration is the outcome of the migration of a smartagent receiver to its native receiver equivalent.
+type SmartAgentMigration struct {
+	Receiver       string `yaml:"receiver"`
+	Monitor        string `yaml:"monitor"`
+	NativeReceiver string `yaml:"native_receiver,omitempty"`
+	// ReplacedBy is the ID of the native receiver that replaced the smartagent receiver, if migrated.
+	ReplacedBy string `yaml:"replaced_by,omitempty"`
+	// Reason is why the receiver wasn't migrated.
+	Reason string `yaml:"reason,omitempty"`
+	// Untranslated are the monitor settings without a native receiver equivalent.
+	Untranslated []string `yaml:"untranslated,omitempty"`
+	// Metrics is the mapping of the monitor metric names to the native receiver ones.
+	Metrics []SmartAgentMetricMapping `yaml:"metrics,omitempty"`
+}
+
+// SmartAgentMetricMapping maps a monitor metric to its native receiver equivalent, if any. The native metric
+// attributes distinguishing it from other monitor metrics are set in braces, e.g. postgresql.rows{state=dead}.
+type SmartAgentMetricMapping struct {
+	SmartAgent string `yaml:"smartagent"`
+	Native     string `yaml:"native"`
+}
+
+// MigrateSmartAgentReceivers replaces the smartagent receivers with their native receiver equivalent
+// when the splunk.smartagent.migrateToNativeReceivers feature gate is enabled.
+func MigrateSmartAgentReceivers(_ context.Context, in *confmap.Conf) error {
+	if in == nil || !migrateSmartAgentFeatureGate.IsEnabled() {
+		return nil
+	}
+
+	out := in.ToStringMap()
+	migrations := migrateSmartAgent(out)
+	if len(migrations) == 0 {
+		return nil
+	}
+	for _, m := range migrations {
+		if m.ReplacedBy != "" {
+			log.Printf("INFO: Feature gate %q is enabled: replaced receiver %q with the %q monitor by %q", migrateSmartAgentFeatureGateID, m.Receiver, m.Monitor, m.ReplacedBy)
+			continue
+		}
+		log.Printf("WARNING: Feature gate %q is enabled but receiver %q with the %q monitor can't be migrated: %s", migrateSmartAgentFeatureGateID, m.Receiver, m.Monitor, m.Reason)
+	}
+
+	*in = *confmap.NewFromStringMap(out)
+	return nil
+}
+
+// migrateSmartAgent replaces the smartagent receivers of the provided config with their native
+// receiver equivalent in place and returns the outcome for each of them, sorted by receiver.
+func migrateSmartAgent(config map[string]any) []SmartAgentMigration {
+	receivers, ok := config["receivers"].(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	var ids []string
+	for id := range receivers {
+		if typ, _, _ := strings.Cut(id, "/"); typ == smartagentReceiverType {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	pipelines := map[string]any{}
+	if service, isMap := config["service"].(map[string]any); isMap {
+		if p, isPipelines := service["pipelines"].(map[string]any); isPipelines {
+			pipelines = p
+		}
+	}
+
+	var migrations []SmartAgentMigration
+	for _, id := range ids {
+		fields, _ := receivers[id].(map[string]any)
+		migration, native := migrateMonitor(id, fields)
+		if native == nil {
+			migrations = append(migrations, migration)
+			continue
+		}
+
+		for _, pipelineID := range receiverPipelines(pipelines, id) {
+			if typ, _, _ := strings.Cut(pipelineID, "/"); typ != "metrics" {
+				migration.Reason = fmt.Sprintf("it's used in the %q pipeline but %q only provides metrics", pipelineID, migration.NativeReceiver)
+				break
+			}
+		}
+		if migration.Reason == "" && len(migration.Untranslated) > 0 {
+			migration.Reason = fmt.Sprintf("settings can't be translated: %s", strings.Join(migration.Untranslated, ", "))
+		}
+
+		nativeID := nativeReceiverID(receivers, id, migration.NativeReceiver)
+		if migration.Reason == "" && nativeID == "" {
+			migration.Reason = fmt.Sprintf("there's already a %q receiver with its name", migration.NativeReceiver)
+		}
+		if migration.Reason != "" {
+			migrations = append(migrations, migration)
+			continue
+		}
+
+		delete(receivers, id)
+		receivers[nativeID] = native
+		for _, pipelineID := range receiverPipelines(pipelines, id) {
+			pipeline := pipelines[pipelineID].(map[string]any)
+			pipelineReceivers := pipeline["receivers"].([]any)
+			for i, r := range pipelineReceivers {
+				if r == id {
+					pipelineReceivers[i] = nativeID
+				}
+			}
+		}
+		migration.ReplacedBy = nativeID
+		migrations = append(migrations, migration)
+	}
+	return migrations
+}
+
+// receiverPipelines returns the sorted IDs of the pipelines using the provided receiver.
+func receiverPipelines(pipelines map[string]any, receiverID string) []string {
+	var ids []string
+	for pipelineID, p := range pipelines {
+		pipeline, ok := p.(map[string]any)
+		if !ok {
+			continue
+		}
+		pipelineReceivers, ok := pipeline["receivers"].([]any)
+		if !ok {
+			continue
+		}
+		for _, r := range pipelineReceivers {
+			if r == receiverID {
+				ids = append(ids, pipelineID)
+				break
+			}
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// nativeReceiverID returns the ID of the native receiver replacing the provided smartagent receiver:
+// <native>/<name> or <native>/smartagent_<name> if already in use, and an empty string if both are.
+func nativeReceiverID(receivers map[string]any, smartagentID, nativeType string) string {
+	_, name, _ := strings.Cut(smartagentID, "/")
+	candidates := []string{fmt.Sprintf("%s/%s", nativeType, smartagentReceiverType)}
+	if name != "" {
+		candidates = []string{
+			fmt.Sprintf("%s/%s", nativeType, name),
+			fmt.Sprintf("%s/%s_%s", nativeType, smartagentReceiverType, name),
+		}
+	}
+	for _, candidate := range candidates {
+		if _, exists := receivers[candidate]; !exists {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// SmartAgentMigrationReport is the --migrate-smartagent counterpart of DryRun. It prints the outcome of
+// the migration of the smartagent receivers to their native receiver equivalent and the resulting
+// config, regardless of the splunk.smartagent.migrateToNativeReceivers feature gate. Like the --dry-run
+// config, it's redacted according to the RedactionPolicy.
+type SmartAgentMigrationReport struct {
+	*sync.Mutex
+	configs            []map[string]any
+	converterFactories []confmap.ConverterFactory
+	enabled            bool
+}
+
+type smartAgentMigrationReport struct {
+	Migrations []SmartAgentMigration `yaml:"migrations"`
+	Config     map[string]any        `yaml:"config"`
+}
+
+func NewSmartAgentMigrationReport(enabled bool, cf []confmap.ConverterFactory) *SmartAgentMigrationReport {
+	return &SmartAgentMigrationReport{
+		Mutex:              &sync.Mutex{},
+		enabled:            enabled,
+		configs:            []map[string]any{},
+		converterFactories: cf,
+	}
+}
+
+func (r *SmartAgentMigrationReport) OnNew() {}
+
+func (r *SmartAgentMigrationReport) OnRetrieve(_ string, retrieved map[string]any) {
+	if r == nil || !r.enabled {
+		return
+	}
+	r.Lock()
+	defer r.Unlock()
+	r.configs = append(r.configs, retrieved)
+}
+
+func (r *SmartAgentMigrationReport) OnShutdown() {}
+
+// Convert disregards the provided *confmap.Conf so that it will use
+// unexpanded values (env vars, config source directives) as
+// accrued by OnRetrieve() calls.
+func (r *SmartAgentMigrationReport) Convert(ctx context.Context, _ *confmap.Conf) error {
+	if r == nil || !r.enabled {
+		return nil
+	}
+	out, err := r.report(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "%s", out)
+	os.Stdout.Sync()
+	os.Exit(0)
+	return nil
+}
+
+func (r *SmartAgentMigrationReport) report(ctx context.Context) ([]byte, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	cm := confmap.New()
+	for _, cfg := range r.configs {
+		if err := cm.Merge(confmap.NewFromStringMap(cfg)); err != nil {
+			return nil, err
+		}
+	}
+	// need to run through other converters since their modifications
+	// are only available via reruns (we disregard confmap.Conf arg)
+	for _, cf := range r.converterFactories {
+		// No need to provide a logger for the report.
+		c := cf.Create(confmap.ConverterSettings{Logger: zap.NewNop()})
+		if err := c.Convert(ctx, cm); err != nil {
+			return nil, fmt.Errorf("error finalizing --migrate-smartagent with converter %v: %w", c, err)
+		}
+	}
+
+	config := cm.ToStringMap()
+	report := smartAgentMigrationReport{Migrations: migrateSmartAgent(config)}
+	if report.Migrations == nil {
+		report.Migrations = []SmartAgentMigration{}
+	}
+	report.Config = Redact(config)
+
+	buf := &bytes.Buffer{}
+	enc := yaml.NewEncoder(buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(report); err != nil {
+		return nil, fmt.Errorf("failed marshaling --migrate-smartagent report: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("failed marshaling --migrate-smartagent report: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,498 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// smartagentMonitor is a monitor type with a native receiver equivalent.
+type smartagentMonitor struct {
+	// translate sets the native receiver config from the monitor specific settings.
+	translate func(c *monitorConfig, native map[string]any)
+	// metrics maps the monitor metrics to the native receiver ones, empty when there's no equivalent.
+	metrics      map[string]string
+	receiverType string
+}
+
+var smartagentMonitors = map[string]smartagentMonitor{
+	"collectd/redis": {
+		receiverType: "redis",
+		translate:    translateRedis,
+		metrics: map[string]string{
+			"bytes.used_memory":                 "redis.memory.used",
+			"bytes.used_memory_lua":             "redis.memory.lua",
+			"bytes.used_memory_peak":            "redis.memory.peak",
+			"bytes.used_memory_rss":             "redis.memory.rss",
+			"counter.commands_processed":        "redis.commands.processed",
+			"counter.connections_received":      "redis.connections.received",
+			"counter.evicted_keys":              "redis.keys.evicted",
+			"counter.expired_keys":              "redis.keys.expired",
+			"counter.rejected_connections":      "redis.connections.rejected",
+			"derive.keyspace_hits":              "redis.keyspace.hits",
+			"derive.keyspace_misses":            "redis.keyspace.misses",
+			"gauge.blocked_clients":             "redis.clients.blocked",
+			"gauge.connected_clients":           "redis.clients.connected",
+			"gauge.connected_slaves":            "redis.slaves.connected",
+			"gauge.instantaneous_ops_per_sec":   "redis.commands",
+			"gauge.key_llen":                    "",
+			"gauge.master_repl_offset":          "redis.replication.offset",
+			"gauge.mem_fragmentation_ratio":     "redis.memory.fragmentation_ratio",
+			"gauge.rdb_changes_since_last_save": "redis.rdb.changes_since_last_save",
+			"gauge.uptime_in_seconds":           "redis.uptime",
+		},
+	},
+	"elasticsearch": {
+		receiverType: "elasticsearch",
+		translate:    translateElasticsearch,
+		metrics: map[string]string{
+			"elasticsearch.cluster.active-primary-shards":     "elasticsearch.cluster.shards{state=active_primary}",
+			"elasticsearch.cluster.active-shards":             "elasticsearch.cluster.shards{state=active}",
+			"elasticsearch.cluster.delayed-unassigned-shards": "elasticsearch.cluster.shards{state=unassigned_delayed}",
+			"elasticsearch.cluster.in-flight-fetches":         "elasticsearch.cluster.in_flight_fetch",
+			"elasticsearch.cluster.initializing-shards":       "elasticsearch.cluster.shards{state=initializing}",
+			"elasticsearch.cluster.number-of-data_nodes":      "elasticsearch.cluster.data_nodes",
+			"elasticsearch.cluster.number-of-nodes":           "elasticsearch.cluster.nodes",
+			"elasticsearch.cluster.pending-tasks":             "elasticsearch.cluster.pending_tasks",
+			"elasticsearch.cluster.relocating-shards":         "elasticsearch.cluster.shards{state=relocating}",
+			"elasticsearch.cluster.status":                    "elasticsearch.cluster.health",
+			"elasticsearch.cluster.unassigned-shards":         "elasticsearch.cluster.shards{state=unassigned}",
+			"elasticsearch.http.current_open":                 "elasticsearch.node.http.connections",
+			"elasticsearch.indices.docs.count":                "elasticsearch.node.documents{state=active}",
+			"elasticsearch.indices.docs.deleted":              "elasticsearch.node.documents{state=deleted}",
+			"elasticsearch.indices.get.total":                 "elasticsearch.node.operations.completed{operation=get}",
+			"elasticsearch.indices.indexing.index-total":      "elasticsearch.node.operations.completed{operation=index}",
+			"elasticsearch.indices.merges.current":            "elasticsearch.node.operations.current{operation=merge}",
+			"elasticsearch.indices.merges.total":              "elasticsearch.node.operations.completed{operation=merge}",
+			"elasticsearch.indices.search.query-time":         "elasticsearch.node.operations.time{operation=query}",
+			"elasticsearch.indices.search.query-total":        "elasticsearch.node.operations.completed{operation=query}",
+			"elasticsearch.indices.segments.count":            "",
+			"elasticsearch.jvm.gc.count":                      "jvm.gc.collections.count",
+			"elasticsearch.jvm.gc.time":                       "jvm.gc.collections.elapsed",
+			"elasticsearch.jvm.mem.heap-committed":            "jvm.memory.heap.committed",
+			"elasticsearch.jvm.mem.heap-max":                  "jvm.memory.heap.max",
+			"elasticsearch.jvm.mem.heap-used":                 "jvm.memory.heap.used",
+			"elasticsearch.jvm.mem.non-heap-committed":        "jvm.memory.nonheap.committed",
+			"elasticsearch.jvm.mem.non-heap-used":             "jvm.memory.nonheap.used",
+			"elasticsearch.jvm.threads.count":                 "jvm.threads.count",
+			"elasticsearch.process.open_file_descriptors":     "elasticsearch.node.open_files",
+			"elasticsearch.thread_pool.active":                "elasticsearch.node.thread_pool.threads{state=active}",
+			"elasticsearch.thread_pool.completed":             "elasticsearch.node.thread_pool.tasks.finished{state=completed}",
+			"elasticsearch.thread_pool.queue":                 "elasticsearch.node.thread_pool.tasks.queued",
+			"elasticsearch.thread_pool.rejected":              "elasticsearch.node.thread_pool.tasks.finished{state=rejected}",
+			"elasticsearch.thread_pool.threads":               "elasticsearch.node.thread_pool.threads",
+			"elasticsearch.transport.server_open":             "elasticsearch.node.cluster.connections",
+		},
+	},
+	"http": {
+		receiverType: "httpcheck",
+		translate:    translateHTTP,
+		metrics: map[string]string{
+			"http.cert_expiry":    "httpcheck.tls.cert_remaining",
+			"http.cert_valid":     "",
+			"http.code_matched":   "",
+			"http.content_length": "",
+			"http.regex_matched":  "",
+			"http.response_time":  "httpcheck.duration",
+			"http.status_code":    "httpcheck.status",
+		},
+	},
+	"postgresql": {
+		receiverType: "postgresql",
+		translate:    translatePostgreSQL,
+		metrics: map[string]string{
+			"postgres_block_hit_ratio":  "",
+			"postgres_database_size":    "postgresql.db_size",
+			"postgres_dead_rows":        "postgresql.rows{state=dead}",
+			"postgres_deadlocks":        "postgresql.deadlocks",
+			"postgres_index_scans":      "postgresql.index.scans",
+			"postgres_live_rows":        "postgresql.rows{state=live}",
+			"postgres_query_count":      "",
+			"postgres_query_time":       "",
+			"postgres_rows_deleted":     "postgresql.operations{operation=del}",
+			"postgres_rows_inserted":    "postgresql.operations{operation=ins}",
+			"postgres_rows_updated":     "postgresql.operations{operation=upd}",
+			"postgres_sequential_scans": "postgresql.sequential_scans",
+			"postgres_sessions":         "postgresql.backends",
+			"postgres_table_size":       "postgresql.table.size",
+			"postgres_xact_commits":     "postgresql.commits",
+			"postgres_xact_rollbacks":   "postgresql.rollbacks",
+		},
+	},
+}
+
+// unsupportedMonitorReason returns why a monitor type can't be migrated.
+func unsupportedMonitorReason(monitorType string) string {
+	switch {
+	case monitorType == "":
+		return "the monitor type isn't set"
+	case monitorType == "expvar":
+		return "there's no native expvar receiver"
+	case strings.HasPrefix(monitorType, "prometheus"):
+		return "Prometheus exporters are to be scraped with the prometheus receiver, whose metrics differ from the monitor ones"
+	default:
+		return fmt.Sprintf("there's no supported native receiver equivalent of the %q monitor", monitorType)
+	}
+}
+
+// migrateMonitor returns the outcome of the translation of a smartagent receiver config and
+// the resulting native receiver config, nil if the monitor has no native receiver equivalent.
+func migrateMonitor(id string, fields map[string]any) (SmartAgentMigration, map[string]any) {
+	monitorType, _ := fields["type"].(string)
+	migration := SmartAgentMigration{Receiver: id, Monitor: monitorType}
+	monitor, ok := smartagentMonitors[monitorType]
+	if !ok {
+		migration.Reason = unsupportedMonitorReason(monitorType)
+		return migration, nil
+	}
+	migration.NativeReceiver = monitor.receiverType
+	for from, to := range monitor.metrics {
+		migration.Metrics = append(migration.Metrics, SmartAgentMetricMapping{SmartAgent: from, Native: to})
+	}
+	sort.Slice(migration.Metrics, func(i, j int) bool {
+		return migration.Metrics[i].SmartAgent < migration.Metrics[j].SmartAgent
+	})
+
+	c := newMonitorConfig(fields)
+	c.get("type")
+	native := map[string]any{}
+	if interval, found := c.get("intervalSeconds"); found {
+		native["collection_interval"] = fmt.Sprintf("%vs", interval)
+	}
+	monitor.translate(c, native)
+	metrics := map[string]any{}
+	c.translateExtraMetrics(monitor, metrics)
+	c.translateDatapointsToExclude(monitor, metrics)
+	if len(metrics) > 0 {
+		native["metrics"] = metrics
+	}
+	migration.Untranslated = c.untranslatedSettings()
+	return migration, native
+}
+
+// monitorConfig tracks the monitor settings that have been translated.
+type monitorConfig struct {
+	fields       map[string]any
+	used         map[string]struct{}
+	untranslated []string
+}
+
+func newMonitorConfig(fields map[string]any) *monitorConfig {
+	return &monitorConfig{fields: fields, used: map[string]struct{}{}}
+}
+
+// get returns the value of a setting and marks it as translated.
+func (c *monitorConfig) get(key string) (any, bool) {
+	v, ok := c.fields[key]
+	if ok {
+		c.used[key] = struct{}{}
+	}
+	return v, ok && !isZeroSetting(v)
+}
+
+func (c *monitorConfig) str(key string) (string, bool) {
+	v, ok := c.get(key)
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprint(v), true
+}
+
+// boolean returns the value of a boolean setting, or the provided default when it's not set or not
+// a boolean, in which case it's reported as untranslated.
+func (c *monitorConfig) boolean(key string, defaultValue bool) bool {
+	v, ok := c.fields[key]
+	c.used[key] = struct{}{}
+	if !ok {
+		return defaultValue
+	}
+	b, isBool := v.(bool)
+	if !isBool {
+		c.untranslate(key)
+		return defaultValue
+	}
+	return b
+}
+
+// copy sets the value of a monitor setting, if set, to the provided native receiver setting.
+func (c *monitorConfig) copy(native map[string]any, from, to string) {
+	if v, ok := c.get(from); ok {
+		native[to] = v
+	}
+}
+
+func (c *monitorConfig) untranslate(setting string) {
+	c.untranslated = append(c.untranslated, setting)
+}
+
+// hostPort returns the address of the monitored service, from the receiver endpoint or the monitor host and port.
+func (c *monitorConfig) hostPort(defaultPort int) string {
+	host, hasHost := c.str("host")
+	port, hasPort := c.str("port")
+	if endpoint, ok := c.str("endpoint"); ok {
+		return endpoint
+	}
+	if !hasHost {
+		host = "localhost"
+	}
+	if !hasPort {
+		port = strconv.Itoa(defaultPort)
+	}
+	return net.JoinHostPort(host, port)
+}
+
+// translateHTTPClient translates the common HTTP client settings of the monitors.
+func (c *monitorConfig) translateHTTPClient(native map[string]any) {
+	tls := map[string]any{}
+	if c.boolean("skipVerify", false) {
+		tls["insecure_skip_verify"] = true
+	}
+	c.copy(tls, "caCertPath", "ca_file")
+	c.copy(tls, "clientCertPath", "cert_file")
+	c.copy(tls, "clientKeyPath", "key_file")
+	c.copy(tls, "sniServerName", "server_name_override")
+	if len(tls) > 0 {
+		native["tls"] = tls
+	}
+	if timeout, ok := c.get("httpTimeout"); ok {
+		if _, isString := timeout.(string); !isString {
+			// integers are seconds
+			timeout = fmt.Sprintf("%vs", timeout)
+		}
+		native["timeout"] = timeout
+	}
+	c.copy(native, "httpHeaders", "headers")
+}
+
+// translateExtraMetrics enables the native receiver equivalents of the extra monitor metrics.
+func (c *monitorConfig) translateExtraMetrics(monitor smartagentMonitor, metrics map[string]any) {
+	v, ok := c.get("extraMetrics")
+	if !ok {
+		return
+	}
+	extraMetrics, isList := v.([]any)
+	if !isList {
+		c.untranslate("extraMetrics")
+		return
+	}
+	for _, m := range extraMetrics {
+		name := fmt.Sprint(m)
+		native := monitor.metrics[name]
+		if native == "" {
+			c.untranslate(fmt.Sprintf("extraMetrics: %s", name))
+			continue
+		}
+		metrics[nativeMetricName(native)] = map[string]any{"enabled": true}
+	}
+}
+
+// translateDatapointsToExclude disables the native receiver equivalents of the excluded monitor
+// metrics. Filters on dimensions and metrics sharing a native metric with others can't be translated.
+func (c *monitorConfig) translateDatapointsToExclude(monitor smartagentMonitor, metrics map[string]any) {
+	v, ok := c.get("datapointsToExclude")
+	if !ok {
+		return
+	}
+	filters, isList := v.([]any)
+	if !isList {
+		c.untranslate("datapointsToExclude")
+		return
+	}
+	for i, f := range filters {
+		var names []string
+		filter, isMap := f.(map[string]any)
+		if isMap && isZeroSetting(filter["dimensions"]) && filter["negated"] != true {
+			if name, isString := filter["metricName"].(string); isString {
+				names = append(names, name)
+			}
+			if metricNames, isNames := filter["metricNames"].([]any); isNames {
+				for _, name := range metricNames {
+					names = append(names, fmt.Sprint(name))
+				}
+			}
+		}
+		var disabled []string
+		for _, name := range names {
+			native := monitor.metrics[name]
+			if native == "" || native != nativeMetricName(native) {
+				break
+			}
+			disabled = append(disabled, native)
+		}
+		if len(names) == 0 || len(disabled) != len(names) {
+			c.untranslate(fmt.Sprintf("datapointsToExclude[%d]", i))
+			continue
+		}
+		for _, native := range disabled {
+			metrics[native] = map[string]any{"enabled": false}
+		}
+	}
+}
+
+// untranslatedSettings returns the sorted settings that were set but not translated.
+func (c *monitorConfig) untranslatedSettings() []string {
+	untranslated := c.untranslated
+	for key, v := range c.fields {
+		if _, ok := c.used[key]; !ok && !isZeroSetting(v) {
+			untranslated = append(untranslated, key)
+		}
+	}
+	sort.Strings(untranslated)
+	return untranslated
+}
+
+// nativeMetricName returns the native metric name without the attributes of a mapping.
+func nativeMetricName(native string) string {
+	name, _, _ := strings.Cut(native, "{")
+	return name
+}
+
+// isZeroSetting returns whether a setting value is equivalent to it not being set.
+func isZeroSetting(v any) bool {
+	switch val := v.(type) {
+	case nil:
+		return true
+	case bool:
+		return !val
+	case string:
+		return val == ""
+	case int:
+		return val == 0
+	case float64:
+		return val == 0
+	case []any:
+		return len(val) == 0
+	case map[string]any:
+		return len(val) == 0
+	}
+	return false
+}
+
+func translateElasticsearch(c *monitorConfig, native map[string]any) {
+	scheme := "http"
+	if c.boolean("useHTTPS", false) {
+		scheme = "https"
+	}
+	native["endpoint"] = fmt.Sprintf("%s://%s", scheme, c.hostPort(9200))
+	c.copy(native, "username", "username")
+	c.copy(native, "password", "password")
+	c.translateHTTPClient(native)
+	// the monitor only reports the stats of the node it's connected to
+	native["nodes"] = []any{"_local"}
+	if !c.boolean("enableClusterHealth", true) {
+		native["skip_cluster_metrics"] = true
+	}
+	if !c.boolean("enableIndexStats", true) {
+		c.untranslate("enableIndexStats")
+	}
+	c.copy(native, "indexes", "indices")
+}
+
+func translateHTTP(c *monitorConfig, native map[string]any) {
+	var endpoints []string
+	if urls, ok := c.get("urls"); ok {
+		list, isList := urls.([]any)
+		if !isList {
+			c.untranslate("urls")
+		}
+		for _, u := range list {
+			endpoints = append(endpoints, fmt.Sprint(u))
+		}
+	}
+	if host, ok := c.str("host"); ok {
+		scheme, defaultPort := "http", 80
+		if c.boolean("useHTTPS", false) {
+			scheme, defaultPort = "https", 443
+		}
+		port, hasPort := c.str("port")
+		if !hasPort {
+			port = strconv.Itoa(defaultPort)
+		}
+		path, _ := c.str("path")
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path
+		}
+		u := url.URL{Scheme: scheme, Host: net.JoinHostPort(host, port), Path: path}
+		endpoints = append(endpoints, u.String())
+	}
+	if desiredCode, ok := c.str("desiredCode"); ok && desiredCode != "200" {
+		c.untranslate("desiredCode")
+	}
+
+	target := map[string]any{}
+	c.copy(target, "method", "method")
+	c.translateHTTPClient(target)
+	targets := make([]any, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		t := map[string]any{"endpoint": endpoint}
+		for k, v := range target {
+			t[k] = v
+		}
+		targets = append(targets, t)
+	}
+	native["targets"] = targets
+}
+
+func translatePostgreSQL(c *monitorConfig, native map[string]any) {
+	native["endpoint"] = c.hostPort(5432)
+	if v, ok := c.get("params"); ok {
+		params, _ := v.(map[string]any)
+		for key, value := range params {
+			switch key {
+			case "username", "password":
+				native[key] = value
+			default:
+				c.untranslate("params." + key)
+			}
+		}
+	}
+	if connectionString, ok := c.str("connectionString"); ok {
+		for _, option := range strings.Fields(connectionString) {
+			key, value, _ := strings.Cut(option, "=")
+			switch {
+			case key == "sslmode" && value == "disable":
+				native["tls"] = map[string]any{"insecure": true}
+			case (key == "user" || key == "password") && strings.HasPrefix(value, "{{"):
+				// templated with the params
+			default:
+				c.untranslate("connectionString: " + key)
+			}
+		}
+	}
+	if masterDBName, ok := c.str("masterDBName"); ok && masterDBName != "postgres" {
+		c.untranslate("masterDBName")
+	}
+	if v, ok := c.get("databases"); ok {
+		databases, _ := v.([]any)
+		for _, db := range databases {
+			if strings.ContainsAny(fmt.Sprint(db), "*?[!") {
+				// the monitor databases are glob patterns, the native receiver ones names
+				c.untranslate("databases")
+				return
+			}
+		}
+		native["databases"] = databases
+	}
+}
+
+func translateRedis(c *monitorConfig, native map[string]any) {
+	native["endpoint"] = c.hostPort(6379)
+	c.copy(native, "auth", "password")
+}
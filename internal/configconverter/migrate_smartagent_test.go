@@ -0,0 +1,190 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+	"go.opentelemetry.io/collector/featuregate"
+	"gopkg.in/yaml.v3"
+)
+
+func setMigrateSmartAgentGate(t *testing.T, enabled bool) {
+	t.Helper()
+	require.NoError(t, featuregate.GlobalRegistry().Set(migrateSmartAgentFeatureGateID, enabled))
+	t.Cleanup(func() {
+		_ = featuregate.GlobalRegistry().Set(migrateSmartAgentFeatureGateID, false)
+	})
+}
+
+func TestMigrateSmartAgent(t *testing.T) {
+	in, err := confmaptest.LoadConf(filepath.Join("testdata", "migrate_smartagent", "config.yaml"))
+	require.NoError(t, err)
+	expected, err := confmaptest.LoadConf(filepath.Join("testdata", "migrate_smartagent", "migrated.yaml"))
+	require.NoError(t, err)
+
+	config := in.ToStringMap()
+	migrations := migrateSmartAgent(config)
+	assert.Equal(t, expected.ToStringMap(), config)
+
+	require.Len(t, migrations, 6)
+	for i := range migrations {
+		if migrations[i].NativeReceiver != "" {
+			require.NotEmpty(t, migrations[i].Metrics)
+			migrations[i].Metrics = nil
+		}
+	}
+	assert.Equal(t, []SmartAgentMigration{
+		{
+			Receiver:       "smartagent/elasticsearch",
+			Monitor:        "elasticsearch",
+			NativeReceiver: "elasticsearch",
+			ReplacedBy:     "elasticsearch/elasticsearch",
+		},
+		{
+			Receiver:       "smartagent/events",
+			Monitor:        "http",
+			NativeReceiver: "httpcheck",
+			Reason:         `it's used in the "logs" pipeline but "httpcheck" only provides metrics`,
+		},
+		{
+			Receiver: "smartagent/expvar",
+			Monitor:  "expvar",
+			Reason:   "there's no native expvar receiver",
+		},
+		{
+			Receiver:       "smartagent/http",
+			Monitor:        "http",
+			NativeReceiver: "httpcheck",
+			ReplacedBy:     "httpcheck/http",
+		},
+		{
+			Receiver:       "smartagent/postgresql",
+			Monitor:        "postgresql",
+			NativeReceiver: "postgresql",
+			ReplacedBy:     "postgresql/smartagent_postgresql",
+		},
+		{
+			Receiver:       "smartagent/redis",
+			Monitor:        "collectd/redis",
+			NativeReceiver: "redis",
+			Reason:         "settings can't be translated: datapointsToExclude[0], datapointsToExclude[1]",
+			Untranslated:   []string{"datapointsToExclude[0]", "datapointsToExclude[1]"},
+		},
+	}, migrations)
+}
+
+func TestMigrateSmartAgentUntranslatedSettings(t *testing.T) {
+	config := map[string]any{
+		"receivers": map[string]any{
+			"smartagent": map[string]any{
+				"type":            "http",
+				"urls":            []any{"http://localhost:8080/health"},
+				"regex":           "ok",
+				"desiredCode":     201,
+				"noRedirects":     false,
+				"extraDimensions": map[string]any{"env": "prod"},
+				"extraMetrics":    []any{"http.content_length", "http.status_code"},
+			},
+		},
+	}
+	migrations := migrateSmartAgent(config)
+	require.Len(t, migrations, 1)
+	assert.Empty(t, migrations[0].ReplacedBy)
+	assert.Equal(t, []string{"desiredCode", "extraDimensions", "extraMetrics: http.content_length", "regex"}, migrations[0].Untranslated)
+	assert.Contains(t, migrations[0].Metrics, SmartAgentMetricMapping{SmartAgent: "http.status_code", Native: "httpcheck.status"})
+	assert.Contains(t, migrations[0].Metrics, SmartAgentMetricMapping{SmartAgent: "http.content_length", Native: ""})
+	assert.Contains(t, config["receivers"], "smartagent")
+}
+
+func TestMigrateSmartAgentReceivers(t *testing.T) {
+	newConf := func() *confmap.Conf {
+		return confmap.NewFromStringMap(map[string]any{
+			"receivers": map[string]any{
+				"smartagent": map[string]any{"type": "collectd/redis", "host": "localhost"},
+			},
+			"service": map[string]any{
+				"pipelines": map[string]any{
+					"metrics": map[string]any{"receivers": []any{"smartagent"}},
+				},
+			},
+		})
+	}
+
+	setMigrateSmartAgentGate(t, false)
+	conf := newConf()
+	require.NoError(t, MigrateSmartAgentReceivers(context.Background(), conf))
+	assert.Equal(t, newConf().ToStringMap(), conf.ToStringMap())
+
+	setMigrateSmartAgentGate(t, true)
+	require.NoError(t, MigrateSmartAgentReceivers(context.Background(), conf))
+	assert.Equal(t, map[string]any{
+		"receivers": map[string]any{
+			"redis/smartagent": map[string]any{"endpoint": "localhost:6379"},
+		},
+		"service": map[string]any{
+			"pipelines": map[string]any{
+				"metrics": map[string]any{"receivers": []any{"redis/smartagent"}},
+			},
+		},
+	}, conf.ToStringMap())
+
+	require.NoError(t, MigrateSmartAgentReceivers(context.Background(), nil))
+}
+
+func TestSmartAgentMigrationReport(t *testing.T) {
+	report := NewSmartAgentMigrationReport(true, []confmap.ConverterFactory{ConverterFactoryFromFunc(SetupDiscovery)})
+	report.OnNew()
+	defer func() { require.NotPanics(t, report.OnShutdown) }()
+
+	report.OnRetrieve("file", map[string]any{
+		"receivers": map[string]any{
+			"smartagent/pg": map[string]any{
+				"type":   "postgresql",
+				"host":   "localhost",
+				"params": map[string]any{"username": "monitor", "password": "${env:PG_PASSWORD}"},
+			},
+		},
+		"service": map[string]any{
+			"pipelines": map[string]any{
+				"metrics": map[string]any{"receivers": []any{"smartagent/pg"}},
+			},
+		},
+	})
+
+	out, err := report.report(context.Background())
+	require.NoError(t, err)
+	var actual struct {
+		Config     map[string]any        `yaml:"config"`
+		Migrations []SmartAgentMigration `yaml:"migrations"`
+	}
+	require.NoError(t, yaml.Unmarshal(out, &actual))
+	require.Len(t, actual.Migrations, 1)
+	assert.Equal(t, "postgresql/pg", actual.Migrations[0].ReplacedBy)
+	assert.Contains(t, actual.Migrations[0].Metrics, SmartAgentMetricMapping{SmartAgent: "postgres_dead_rows", Native: "postgresql.rows{state=dead}"})
+	assert.Equal(t, map[string]any{
+		"postgresql/pg": map[string]any{
+			"endpoint": "localhost:5432",
+			"username": "<redacted>",
+			"password": "<redacted>",
+		},
+	}, actual.Config["receivers"])
+}
@@ -0,0 +1,33 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import "github.com/signalfx/splunk-otel-collector/internal/configsource"
+
+// Redact returns a copy of the provided config with its secret values replaced by "<redacted>"
+// according to the current configsource.RedactionPolicy.
+func Redact(config map[string]any) map[string]any {
+	return configsource.Redact(config)
+}
+
+// SetRedactionPolicyFromEnv sets the configsource.RedactionPolicy loaded from the
+// configsource.RedactionPolicyEnvVar file, if set, as the one used by Redact.
+func SetRedactionPolicyFromEnv() error {
+	return configsource.SetRedactionPolicyFromEnv()
+}
+
+func redactResolved(config map[string]any, unresolved ...map[string]any) map[string]any {
+	return configsource.CurrentRedactionPolicy().RedactResolved(config, unresolved...)
+}
@@ -0,0 +1,234 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configsource
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"go.opentelemetry.io/collector/confmap"
+	"gopkg.in/yaml.v2"
+)
+
+const cacheFileSuffix = ".cache"
+
+// CacheObserver is notified of the last-known-good Cache lookups made after a
+// ConfigSource failed to retrieve a value, and of the Cache failures. The Cache
+// doesn't log, its observers report these events.
+type CacheObserver interface {
+	// OnCacheHit is called when a cached value is used in place of a retrieval failed with retrieveErr.
+	OnCacheHit(cfgSrcName string, retrieveErr error)
+	// OnCacheMiss is called when there is no cached value for a failed retrieval.
+	OnCacheMiss(cfgSrcName string)
+	// OnCacheError is called when a value can't be stored in or loaded from the Cache.
+	OnCacheError(cfgSrcName string, err error)
+}
+
+// Cache is an encrypted on-disk store of the last successfully retrieved value for
+// each config source name, selector, and params combination. Values are encrypted
+// with AES-GCM so that secrets retrieved from backends like Vault aren't written in
+// plaintext.
+type Cache struct {
+	aead      cipher.AEAD
+	dir       string
+	observers []CacheObserver
+}
+
+// NewCache creates a Cache storing its entries in dir, which is created if needed.
+// The key must be 16, 24, or 32 bytes to select AES-128, AES-192, or AES-256.
+func NewCache(dir string, key []byte, observers ...CacheObserver) (*Cache, error) {
+	if dir == "" {
+		return nil, errors.New("cache directory must be specified")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cache key: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating cache cipher: %w", err)
+	}
+	if err = os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed creating cache directory: %w", err)
+	}
+	return &Cache{
+		aead:      aead,
+		dir:       dir,
+		observers: observers,
+	}, nil
+}
+
+// Wrap returns the provided config sources with their Retrieve() calls backed by the Cache.
+// A nil Cache returns the config sources as is.
+func (c *Cache) Wrap(configSources map[string]ConfigSource) map[string]ConfigSource {
+	if c == nil {
+		return configSources
+	}
+	wrapped := make(map[string]ConfigSource, len(configSources))
+	for name, cfgSrc := range configSources {
+		wrapped[name] = &cachedConfigSource{name: name, cfgSrc: cfgSrc, cache: c}
+	}
+	return wrapped
+}
+
+// cacheEntry is the plaintext content of a cache file. Values are wrapped to
+// support scalars at the document root.
+type cacheEntry struct {
+	Value any `yaml:"value"`
+}
+
+// key returns the cache file name and the additional data authenticated with its content,
+// which ensures a file can't be swapped for the entry of another invocation.
+func (c *Cache) key(cfgSrcName, selector string, params *confmap.Conf) (string, []byte, error) {
+	var paramsContent []byte
+	if params != nil {
+		var err error
+		if paramsContent, err = yaml.Marshal(params.ToStringMap()); err != nil {
+			return "", nil, fmt.Errorf("failed marshaling params: %w", err)
+		}
+	}
+	ad := []byte(fmt.Sprintf("%s\x00%s\x00%s", cfgSrcName, selector, paramsContent))
+	sum := sha256.Sum256(ad)
+	return hex.EncodeToString(sum[:]) + cacheFileSuffix, ad, nil
+}
+
+func (c *Cache) store(cfgSrcName, selector string, params *confmap.Conf, value any) error {
+	name, ad, err := c.key(cfgSrcName, selector, params)
+	if err != nil {
+		return err
+	}
+	plaintext, err := yaml.Marshal(cacheEntry{Value: value})
+	if err != nil {
+		return fmt.Errorf("failed marshaling value: %w", err)
+	}
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed generating nonce: %w", err)
+	}
+	content := c.aead.Seal(nonce, nonce, plaintext, ad)
+
+	// write to a temporary file first so an interrupted write can't corrupt the last-known-good entry
+	tmp, err := os.CreateTemp(c.dir, name+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed creating cache file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(content); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed writing cache file: %w", err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("failed writing cache file: %w", err)
+	}
+	if err = os.Rename(tmp.Name(), filepath.Join(c.dir, name)); err != nil {
+		return fmt.Errorf("failed writing cache file: %w", err)
+	}
+	return nil
+}
+
+func (c *Cache) load(cfgSrcName, selector string, params *confmap.Conf) (any, error) {
+	name, ad, err := c.key(cfgSrcName, selector, params)
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(filepath.Join(c.dir, name))
+	if err != nil {
+		return nil, err
+	}
+	nonceSize := c.aead.NonceSize()
+	if len(content) < nonceSize {
+		return nil, errors.New("cache file is truncated")
+	}
+	plaintext, err := c.aead.Open(nil, content[:nonceSize], content[nonceSize:], ad)
+	if err != nil {
+		return nil, fmt.Errorf("failed decrypting cache file: %w", err)
+	}
+	var entry cacheEntry
+	if err = yaml.Unmarshal(plaintext, &entry); err != nil {
+		return nil, fmt.Errorf("failed unmarshaling cache file: %w", err)
+	}
+	return stringKeyed(entry.Value), nil
+}
+
+// stringKeyed converts the map[any]any values unmarshaled by yaml.v2 to the
+// map[string]any ones supported by confmap.
+func stringKeyed(value any) any {
+	switch v := value.(type) {
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for k, val := range v {
+			m[fmt.Sprint(k)] = stringKeyed(val)
+		}
+		return m
+	case []any:
+		for i, val := range v {
+			v[i] = stringKeyed(val)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+var _ ConfigSource = (*cachedConfigSource)(nil)
+
+// cachedConfigSource stores successfully retrieved values and falls back to
+// them when the wrapped ConfigSource fails to retrieve.
+type cachedConfigSource struct {
+	cfgSrc ConfigSource
+	cache  *Cache
+	name   string
+}
+
+func (c *cachedConfigSource) Retrieve(ctx context.Context, selector string, params *confmap.Conf, watcher confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	retrieved, err := c.cfgSrc.Retrieve(ctx, selector, params, watcher)
+	if err == nil {
+		value, rErr := retrieved.AsRaw()
+		if rErr == nil {
+			rErr = c.cache.store(c.name, selector, params, value)
+		}
+		if rErr != nil {
+			for _, o := range c.cache.observers {
+				o.OnCacheError(c.name, fmt.Errorf("failed caching config source value: %w", rErr))
+			}
+		}
+		return retrieved, nil
+	}
+
+	value, lErr := c.cache.load(c.name, selector, params)
+	if lErr != nil {
+		for _, o := range c.cache.observers {
+			o.OnCacheMiss(c.name)
+		}
+		if !errors.Is(lErr, os.ErrNotExist) {
+			for _, o := range c.cache.observers {
+				o.OnCacheError(c.name, fmt.Errorf("failed loading cached config source value: %w", lErr))
+			}
+		}
+		return nil, err
+	}
+	for _, o := range c.cache.observers {
+		o.OnCacheHit(c.name, err)
+	}
+	return confmap.NewRetrieved(value)
+}
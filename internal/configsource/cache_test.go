@@ -0,0 +1,141 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configsource
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap"
+)
+
+type countingCacheObserver struct {
+	hits      map[string]int
+	misses    map[string]int
+	hitErrors []error
+	errors    []error
+}
+
+func (c *countingCacheObserver) OnCacheHit(cfgSrcName string, retrieveErr error) {
+	c.hits[cfgSrcName]++
+	c.hitErrors = append(c.hitErrors, retrieveErr)
+}
+
+func (c *countingCacheObserver) OnCacheMiss(cfgSrcName string) {
+	c.misses[cfgSrcName]++
+}
+
+func (c *countingCacheObserver) OnCacheError(_ string, err error) {
+	c.errors = append(c.errors, err)
+}
+
+func TestCacheFallsBackToLastKnownGood(t *testing.T) {
+	dir := t.TempDir()
+	key := []byte("0123456789abcdef0123456789abcdef")
+	observer := &countingCacheObserver{hits: map[string]int{}, misses: map[string]int{}}
+	cache, err := NewCache(dir, key, observer)
+	require.NoError(t, err)
+
+	tstCfgSrc := &TestConfigSource{
+		ValueMap: map[string]valueEntry{
+			"password": {Value: "s3cr3t"},
+			"nested":   {Value: map[string]any{"port": 1234, "hosts": []any{"a", "b"}}},
+		},
+	}
+	configSources := cache.Wrap(map[string]ConfigSource{"tstcfgsrc": tstCfgSrc})
+	conf := confmap.NewFromStringMap(map[string]any{
+		"password": "${tstcfgsrc:password}",
+		"nested":   "${tstcfgsrc:nested}",
+	})
+
+	res, closeFunc, err := ResolveWithConfigSources(context.Background(), configSources, nil, conf, nil)
+	require.NoError(t, err)
+	require.NoError(t, callClose(context.Background(), closeFunc))
+	expected := res.ToStringMap()
+	assert.Empty(t, observer.hits)
+	assert.Empty(t, observer.misses)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	for _, entry := range entries {
+		content, rErr := os.ReadFile(filepath.Join(dir, entry.Name()))
+		require.NoError(t, rErr)
+		assert.NotContains(t, string(content), "s3cr3t")
+	}
+
+	// the backend becomes unreachable
+	tstCfgSrc.ErrOnRetrieve = errors.New("connection refused")
+	res, closeFunc, err = ResolveWithConfigSources(context.Background(), configSources, nil, conf, nil)
+	require.NoError(t, err)
+	require.NoError(t, callClose(context.Background(), closeFunc))
+	assert.Equal(t, expected, res.ToStringMap())
+	assert.Equal(t, map[string]int{"tstcfgsrc": 2}, observer.hits)
+	require.Len(t, observer.hitErrors, 2)
+	assert.ErrorContains(t, observer.hitErrors[0], "connection refused")
+	assert.Empty(t, observer.errors)
+
+	// entries aren't readable with another key
+	otherCache, err := NewCache(dir, []byte(strings.Repeat("k", 32)), observer)
+	require.NoError(t, err)
+	_, _, err = ResolveWithConfigSources(context.Background(), otherCache.Wrap(map[string]ConfigSource{"tstcfgsrc": tstCfgSrc}), nil, conf, nil)
+	require.ErrorContains(t, err, "connection refused")
+	assert.Equal(t, map[string]int{"tstcfgsrc": 1}, observer.misses)
+	require.Len(t, observer.errors, 1)
+	assert.ErrorContains(t, observer.errors[0], "failed loading cached config source value")
+
+	// selectors without a cached value fail as before
+	_, _, err = ResolveWithConfigSources(context.Background(), configSources, nil, confmap.NewFromStringMap(map[string]any{
+		"other": "${tstcfgsrc:other}",
+	}), nil)
+	require.ErrorContains(t, err, "connection refused")
+	assert.Equal(t, map[string]int{"tstcfgsrc": 2}, observer.misses)
+}
+
+func TestCacheParamsAreKeyed(t *testing.T) {
+	cache, err := NewCache(t.TempDir(), []byte("0123456789abcdef"))
+	require.NoError(t, err)
+
+	first := confmap.NewFromStringMap(map[string]any{"p0": 1})
+	second := confmap.NewFromStringMap(map[string]any{"p0": 2})
+	require.NoError(t, cache.store("tstcfgsrc", "selector", first, "first"))
+	require.NoError(t, cache.store("tstcfgsrc", "selector", second, "second"))
+
+	value, err := cache.load("tstcfgsrc", "selector", first)
+	require.NoError(t, err)
+	assert.Equal(t, "first", value)
+	value, err = cache.load("tstcfgsrc", "selector", second)
+	require.NoError(t, err)
+	assert.Equal(t, "second", value)
+	_, err = cache.load("tstcfgsrc", "selector", nil)
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestNewCacheErrors(t *testing.T) {
+	_, err := NewCache("", []byte("0123456789abcdef"))
+	require.EqualError(t, err, "cache directory must be specified")
+	_, err = NewCache(t.TempDir(), []byte("short"))
+	require.EqualError(t, err, "invalid cache key: crypto/aes: invalid key size 5")
+
+	var nilCache *Cache
+	configSources := map[string]ConfigSource{"tstcfgsrc": &TestConfigSource{}}
+	assert.Equal(t, configSources, nilCache.Wrap(configSources))
+}
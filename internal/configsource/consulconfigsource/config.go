@@ -0,0 +1,55 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package consulconfigsource
+
+import (
+	"time"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configsource"
+)
+
+// Config holds the configuration for the creation of Consul config source objects.
+type Config struct {
+	configsource.SourceSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+	// Endpoint is the address of the Consul agent, typically it is set via the
+	// CONSUL_HTTP_ADDR environment variable for the Consul CLI. Defaults to
+	// "http://localhost:8500".
+	Endpoint string `mapstructure:"endpoint"`
+	// Token is the ACL token used to access the KV store, typically it is set via
+	// the CONSUL_HTTP_TOKEN environment variable for the Consul CLI.
+	Token string `mapstructure:"token"`
+	// Datacenter is the datacenter of the KV store to query. Defaults to the
+	// datacenter of the agent.
+	Datacenter string `mapstructure:"datacenter"`
+	// TLS holds the TLS settings used to connect to the Consul agent.
+	TLS TLSConfig `mapstructure:"tls"`
+	// WaitTime is the maximum duration of the blocking queries used to watch for
+	// changes on the retrieved keys. Defaults to 5 minutes if not specified.
+	WaitTime time.Duration `mapstructure:"wait_time"`
+}
+
+// TLSConfig holds the TLS settings used to connect to the Consul agent.
+type TLSConfig struct {
+	// CAFile is the path of the CA certificate used to verify the Consul agent certificate.
+	CAFile string `mapstructure:"ca_file"`
+	// CertFile is the path of the client certificate for mutual TLS.
+	CertFile string `mapstructure:"cert_file"`
+	// KeyFile is the path of the client certificate key for mutual TLS.
+	KeyFile string `mapstructure:"key_file"`
+	// ServerName is the server name used to verify the Consul agent certificate.
+	ServerName string `mapstructure:"server_name"`
+	// InsecureSkipVerify disables the verification of the Consul agent certificate.
+	InsecureSkipVerify bool `mapstructure:"insecure_skip_verify"`
+}
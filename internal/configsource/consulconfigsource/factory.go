@@ -0,0 +1,80 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package consulconfigsource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configsource"
+)
+
+const (
+	// The "type" of Consul config sources in configuration.
+	typeStr = "consul"
+
+	defaultEndpoint = "http://localhost:8500"
+	defaultWaitTime = 5 * time.Minute
+)
+
+// Private error types to help with testability.
+type (
+	errInvalidEndpoint     struct{ error }
+	errInvalidTLS          struct{ error }
+	errNonPositiveWaitTime struct{ error }
+)
+
+type consulFactory struct{}
+
+func (f *consulFactory) Type() component.Type {
+	return component.MustNewType(typeStr)
+}
+
+func (f *consulFactory) CreateDefaultConfig() configsource.Settings {
+	return &Config{
+		SourceSettings: configsource.NewSourceSettings(component.MustNewID(typeStr)),
+		Endpoint:       defaultEndpoint,
+		WaitTime:       defaultWaitTime,
+	}
+}
+
+func (f *consulFactory) CreateConfigSource(_ context.Context, settings configsource.Settings, logger *zap.Logger) (configsource.ConfigSource, error) {
+	consulCfg := settings.(*Config)
+
+	if _, err := url.ParseRequestURI(consulCfg.Endpoint); err != nil {
+		return nil, &errInvalidEndpoint{fmt.Errorf("invalid endpoint %q: %w", consulCfg.Endpoint, err)}
+	}
+
+	if (consulCfg.TLS.CertFile == "") != (consulCfg.TLS.KeyFile == "") {
+		return nil, &errInvalidTLS{errors.New("tls cert_file and key_file must be set together")}
+	}
+
+	if consulCfg.WaitTime <= 0 {
+		return nil, &errNonPositiveWaitTime{errors.New("wait_time must be positive")}
+	}
+
+	return newConfigSource(consulCfg, logger)
+}
+
+// NewFactory creates a factory for Consul ConfigSource objects.
+func NewFactory() configsource.Factory {
+	return &consulFactory{}
+}
@@ -0,0 +1,69 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package consulconfigsource
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+)
+
+func TestConsulFactory_CreateConfigSource(t *testing.T) {
+	factory := NewFactory()
+	assert.Equal(t, component.MustNewType("consul"), factory.Type())
+
+	tests := []struct {
+		config  *Config
+		wantErr error
+		name    string
+	}{
+		{
+			name:    "invalid_endpoint",
+			config:  &Config{Endpoint: "some\bad/endpoint", WaitTime: time.Minute},
+			wantErr: &errInvalidEndpoint{},
+		},
+		{
+			name:    "cert_without_key",
+			config:  &Config{Endpoint: defaultEndpoint, WaitTime: time.Minute, TLS: TLSConfig{CertFile: "cert.pem"}},
+			wantErr: &errInvalidTLS{},
+		},
+		{
+			name:    "non_positive_wait_time",
+			config:  &Config{Endpoint: defaultEndpoint},
+			wantErr: &errNonPositiveWaitTime{},
+		},
+		{
+			name:   "default",
+			config: factory.CreateDefaultConfig().(*Config),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := factory.CreateConfigSource(context.Background(), tt.config, zap.NewNop())
+			if tt.wantErr != nil {
+				require.IsType(t, tt.wantErr, err)
+				assert.Nil(t, actual)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotNil(t, actual)
+		})
+	}
+}
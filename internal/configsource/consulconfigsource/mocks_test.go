@@ -0,0 +1,135 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package consulconfigsource
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+)
+
+// fakeConsul is a fake Consul agent serving the KV store endpoints, including
+// blocking queries, for the keys in kv.
+type fakeConsul struct {
+	*httptest.Server
+	kv          map[string]string
+	changed     chan struct{}
+	token       string
+	datacenters []string
+	index       uint64
+	mutex       sync.Mutex
+}
+
+func newFakeConsul(t *testing.T, token string, kv map[string]string) *fakeConsul {
+	t.Helper()
+	f := &fakeConsul{
+		kv:      kv,
+		changed: make(chan struct{}),
+		token:   token,
+		index:   1,
+	}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.handle))
+	t.Cleanup(f.Close)
+	return f
+}
+
+func (f *fakeConsul) handle(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Consul-Token") != f.token {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte("ACL not found"))
+		return
+	}
+	if !strings.HasPrefix(r.URL.Path, "/v1/kv/") {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, "/v1/kv/")
+	query := r.URL.Query()
+
+	if query.Has("index") {
+		waitIndex, _ := strconv.ParseUint(query.Get("index"), 10, 64)
+		wait, err := time.ParseDuration(query.Get("wait"))
+		if err != nil {
+			wait = 5 * time.Minute
+		}
+		timeout := time.After(wait)
+	blocking:
+		for {
+			f.mutex.Lock()
+			index, changed := f.index, f.changed
+			f.mutex.Unlock()
+			if index > waitIndex {
+				break
+			}
+			select {
+			case <-changed:
+			case <-timeout:
+				break blocking
+			case <-r.Context().Done():
+				return
+			}
+		}
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.datacenters = append(f.datacenters, query.Get("dc"))
+	var pairs api.KVPairs
+	for k, v := range f.kv {
+		if k == key || (query.Has("recurse") && strings.HasPrefix(k, key)) {
+			pairs = append(pairs, &api.KVPair{Key: k, Value: []byte(v), ModifyIndex: f.index})
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].Key < pairs[j].Key })
+
+	w.Header().Set("X-Consul-Index", strconv.FormatUint(f.index, 10))
+	if len(pairs) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(pairs)
+}
+
+// update applies the changes to the KV store, deleting the keys with nil values,
+// and unblocks the pending blocking queries.
+func (f *fakeConsul) update(changes map[string]*string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	for k, v := range changes {
+		if v == nil {
+			delete(f.kv, k)
+		} else {
+			f.kv[k] = *v
+		}
+	}
+	f.index++
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+func (f *fakeConsul) getDatacenters() []string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return append([]string(nil), f.datacenters...)
+}
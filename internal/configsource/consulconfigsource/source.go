@@ -0,0 +1,208 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package consulconfigsource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/hashicorp/consul/api"
+	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configsource"
+)
+
+const maxBackoffTime = time.Second * 60
+
+// Private error types to help with testability.
+type (
+	errKeyNotFound     struct{ error }
+	errConflictingKeys struct{ error }
+)
+
+// consulConfigSource implements the configsource.ConfigSource interface.
+type consulConfigSource struct {
+	logger   *zap.Logger
+	kv       *api.KV
+	waitTime time.Duration
+}
+
+func newConfigSource(cfg *Config, logger *zap.Logger) (configsource.ConfigSource, error) {
+	// Client doesn't connect on creation and can't be closed.
+	client, err := api.NewClient(&api.Config{
+		Address:    cfg.Endpoint,
+		Token:      cfg.Token,
+		Datacenter: cfg.Datacenter,
+		TLSConfig: api.TLSConfig{
+			Address:            cfg.TLS.ServerName,
+			CAFile:             cfg.TLS.CAFile,
+			CertFile:           cfg.TLS.CertFile,
+			KeyFile:            cfg.TLS.KeyFile,
+			InsecureSkipVerify: cfg.TLS.InsecureSkipVerify,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &consulConfigSource{
+		logger:   logger,
+		kv:       client.KV(),
+		waitTime: cfg.WaitTime,
+	}, nil
+}
+
+// Retrieve returns the value of the selected key or, if the selector ends with a "/",
+// all the keys under the selected prefix as a map nested by their "/" separated segments.
+func (s *consulConfigSource) Retrieve(ctx context.Context, selector string, _ *confmap.Conf, watcher confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	value, index, err := s.get(ctx, selector, nil)
+	if err != nil {
+		return nil, err
+	}
+	if watcher == nil {
+		return confmap.NewRetrieved(value)
+	}
+	return confmap.NewRetrieved(value, confmap.WithRetrievedClose(s.newWatcher(selector, value, index, watcher)))
+}
+
+func (s *consulConfigSource) get(ctx context.Context, selector string, opts *api.QueryOptions) (any, uint64, error) {
+	opts = opts.WithContext(ctx)
+
+	if !strings.HasSuffix(selector, "/") {
+		pair, meta, err := s.kv.Get(selector, opts)
+		if err != nil {
+			return nil, 0, err
+		}
+		if pair == nil {
+			return nil, meta.LastIndex, &errKeyNotFound{fmt.Errorf("key %q not found", selector)}
+		}
+		return string(pair.Value), meta.LastIndex, nil
+	}
+
+	pairs, meta, err := s.kv.List(selector, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	value, err := pairsToMap(selector, pairs)
+	if err != nil {
+		return nil, meta.LastIndex, err
+	}
+	return value, meta.LastIndex, nil
+}
+
+// pairsToMap nests the values of the keys under the prefix by their "/" separated segments,
+// e.g. the value of the "prefix/a/b" key is returned as {"a": {"b": value}}.
+func pairsToMap(prefix string, pairs api.KVPairs) (map[string]any, error) {
+	value := map[string]any{}
+	for _, pair := range pairs {
+		relative := strings.TrimPrefix(pair.Key, prefix)
+		// Keys ending with a "/" are folders without a value.
+		if relative == "" || strings.HasSuffix(relative, "/") {
+			continue
+		}
+
+		segments := strings.Split(relative, "/")
+		current := value
+		for _, segment := range segments[:len(segments)-1] {
+			switch next := current[segment].(type) {
+			case nil:
+				nested := map[string]any{}
+				current[segment] = nested
+				current = nested
+			case map[string]any:
+				current = next
+			default:
+				return nil, &errConflictingKeys{fmt.Errorf("key %q conflicts with the value of another key under prefix %q", pair.Key, prefix)}
+			}
+		}
+		last := segments[len(segments)-1]
+		if _, ok := current[last]; ok {
+			return nil, &errConflictingKeys{fmt.Errorf("key %q conflicts with the keys nested under it for prefix %q", pair.Key, prefix)}
+		}
+		current[last] = string(pair.Value)
+	}
+
+	if len(value) == 0 {
+		return nil, &errKeyNotFound{fmt.Errorf("no keys found under prefix %q", prefix)}
+	}
+	return value, nil
+}
+
+// newWatcher uses blocking queries to wait for changes on the retrieved keys, see
+// https://developer.hashicorp.com/consul/api-docs/features/blocking. The watcher is
+// notified once the retrieved value differs from the latest one.
+func (s *consulConfigSource) newWatcher(selector string, value any, index uint64, watcherFunc confmap.WatcherFunc) confmap.CloseFunc {
+	watchCtx, cancel := context.WithCancel(context.Background())
+	ebo := backoff.NewExponentialBackOff()
+	ebo.MaxElapsedTime = maxBackoffTime
+
+	go func() {
+		for {
+			latest, latestIndex, err := s.get(watchCtx, selector, &api.QueryOptions{WaitIndex: index, WaitTime: s.waitTime})
+			if watchCtx.Err() != nil {
+				return
+			}
+
+			var notFound *errKeyNotFound
+			switch {
+			case errors.As(err, &notFound):
+				// Deleted keys are a change, the configuration must be re-fetched.
+				watcherFunc(&confmap.ChangeEvent{Error: nil})
+				return
+			case err != nil:
+				s.logger.Info("error watching", zap.String("selector", selector), zap.Error(err))
+				wait := ebo.NextBackOff()
+				if wait == backoff.Stop {
+					watcherFunc(&confmap.ChangeEvent{Error: err})
+					return
+				}
+				select {
+				case <-time.After(wait):
+					continue
+				case <-watchCtx.Done():
+					return
+				}
+			}
+			ebo.Reset()
+
+			// The index also changes when other keys are updated, so values are compared.
+			if !reflect.DeepEqual(value, latest) {
+				watcherFunc(&confmap.ChangeEvent{Error: nil})
+				return
+			}
+
+			// Indexes going backwards must be reset and must be greater than zero to block.
+			switch {
+			case latestIndex < index:
+				index = 0
+			case latestIndex == 0:
+				index = 1
+			default:
+				index = latestIndex
+			}
+		}
+	}()
+
+	return func(_ context.Context) error {
+		cancel()
+		return nil
+	}
+}
@@ -0,0 +1,193 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package consulconfigsource
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/zap"
+)
+
+func sPtr(s string) *string {
+	return &s
+}
+
+func newTestSource(t *testing.T, server *fakeConsul, token string) *consulConfigSource {
+	t.Helper()
+	source, err := newConfigSource(&Config{
+		Endpoint:   server.URL,
+		Token:      token,
+		Datacenter: "dc2",
+		WaitTime:   time.Second,
+	}, zap.NewNop())
+	require.NoError(t, err)
+	return source.(*consulConfigSource)
+}
+
+func TestConsulRetrieve(t *testing.T) {
+	server := newFakeConsul(t, "a_token", map[string]string{
+		"collector/token":               "t0k3n",
+		"collector/receivers/":          "",
+		"collector/receivers/redis/tls": "true",
+		"collector/receivers/redis/dsn": "redis://localhost:6379",
+		"collector/receivers/mysql":     "mysql",
+		"conflict/a":                    "a",
+		"conflict/a/b":                  "b",
+	})
+	source := newTestSource(t, server, "a_token")
+
+	tests := []struct {
+		expected any
+		wantErr  any
+		name     string
+		selector string
+	}{
+		{
+			name:     "key",
+			selector: "collector/token",
+			expected: "t0k3n",
+		},
+		{
+			name:     "prefix",
+			selector: "collector/receivers/",
+			expected: map[string]any{
+				"mysql": "mysql",
+				"redis": map[string]any{
+					"dsn": "redis://localhost:6379",
+					"tls": "true",
+				},
+			},
+		},
+		{
+			name:     "missing_key",
+			selector: "collector/missing",
+			wantErr:  &errKeyNotFound{},
+		},
+		{
+			name:     "missing_prefix",
+			selector: "missing/",
+			wantErr:  &errKeyNotFound{},
+		},
+		{
+			name:     "conflicting_keys",
+			selector: "conflict/",
+			wantErr:  &errConflictingKeys{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			retrieved, err := source.Retrieve(context.Background(), tt.selector, nil, nil)
+			if tt.wantErr != nil {
+				require.IsType(t, tt.wantErr, err)
+				assert.Nil(t, retrieved)
+				return
+			}
+			require.NoError(t, err)
+			actual, err := retrieved.AsRaw()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+			require.NoError(t, retrieved.Close(context.Background()))
+		})
+	}
+
+	for _, dc := range server.getDatacenters() {
+		assert.Equal(t, "dc2", dc)
+	}
+
+	_, err := newTestSource(t, server, "bad_token").Retrieve(context.Background(), "collector/token", nil, nil)
+	require.ErrorContains(t, err, "403")
+}
+
+func TestConsulWatcher(t *testing.T) {
+	tests := []struct {
+		changes  map[string]*string
+		name     string
+		selector string
+	}{
+		{
+			name:     "key_updated",
+			selector: "collector/token",
+			changes:  map[string]*string{"collector/token": sPtr("n3w t0k3n")},
+		},
+		{
+			name:     "key_deleted",
+			selector: "collector/token",
+			changes:  map[string]*string{"collector/token": nil},
+		},
+		{
+			name:     "prefix_key_added",
+			selector: "collector/receivers/",
+			changes:  map[string]*string{"collector/receivers/mysql": sPtr("mysql")},
+		},
+		{
+			name:     "prefix_key_updated",
+			selector: "collector/receivers/",
+			changes:  map[string]*string{"collector/receivers/redis/tls": sPtr("false")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakeConsul(t, "", map[string]string{
+				"collector/token":               "t0k3n",
+				"collector/receivers/redis/tls": "true",
+			})
+			source := newTestSource(t, server, "")
+
+			watchChannel := make(chan *confmap.ChangeEvent, 1)
+			retrieved, err := source.Retrieve(context.Background(), tt.selector, nil, func(ce *confmap.ChangeEvent) {
+				watchChannel <- ce
+			})
+			require.NoError(t, err)
+
+			// Updates of other keys unblock the blocking query without changing the value.
+			server.update(map[string]*string{"other/key": sPtr("other")})
+			select {
+			case <-watchChannel:
+				t.Fatal("watcher must not be called for unchanged values")
+			case <-time.After(200 * time.Millisecond):
+			}
+
+			server.update(tt.changes)
+			select {
+			case ce := <-watchChannel:
+				assert.NoError(t, ce.Error)
+			case <-time.After(5 * time.Second):
+				t.Fatal("watcher wasn't called for changed value")
+			}
+			require.NoError(t, retrieved.Close(context.Background()))
+		})
+	}
+}
+
+func TestConsulWatcherClose(t *testing.T) {
+	server := newFakeConsul(t, "", map[string]string{"collector/token": "t0k3n"})
+	source := newTestSource(t, server, "")
+
+	retrieved, err := source.Retrieve(context.Background(), "collector/token", nil, func(*confmap.ChangeEvent) {
+		panic("must not be called")
+	})
+	require.NoError(t, err)
+	require.NoError(t, retrieved.Close(context.Background()))
+
+	server.update(map[string]*string{"collector/token": sPtr("n3w t0k3n")})
+	time.Sleep(200 * time.Millisecond)
+}
@@ -0,0 +1,116 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configsource
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"go.opentelemetry.io/collector/confmap"
+)
+
+// ConfigChangeKind is the kind of change made to a leaf of a resolved configuration.
+type ConfigChangeKind string
+
+const (
+	ConfigChangeAdded   ConfigChangeKind = "added"
+	ConfigChangeRemoved ConfigChangeKind = "removed"
+	ConfigChangeChanged ConfigChangeKind = "changed"
+)
+
+// ConfigChange is a change made to a leaf of a resolved configuration. Lists are leaves
+// since they are replaced as a whole.
+type ConfigChange struct {
+	Old  any              `json:"old,omitempty"`
+	New  any              `json:"new,omitempty"`
+	Path string           `json:"path"`
+	Kind ConfigChangeKind `json:"kind"`
+}
+
+func (c ConfigChange) String() string {
+	switch c.Kind {
+	case ConfigChangeAdded:
+		return fmt.Sprintf("+ %s: %v", c.Path, c.New)
+	case ConfigChangeRemoved:
+		return fmt.Sprintf("- %s: %v", c.Path, c.Old)
+	default:
+		return fmt.Sprintf("~ %s: %v -> %v", c.Path, c.Old, c.New)
+	}
+}
+
+// DiffResolved returns the changes between the previous and current resolutions of the
+// unresolved configuration, sorted by their confmap.KeyDelimiter separated path. Their values
+// are redacted by the current RedactionPolicy, which redacts all the values set by config
+// sources and env vars directives in the unresolved configuration.
+func DiffResolved(unresolved *confmap.Conf, previous, current map[string]any) []ConfigChange {
+	var unresolvedMaps []map[string]any
+	if unresolved != nil {
+		unresolvedMaps = append(unresolvedMaps, unresolved.ToStringMap())
+	}
+	policy := CurrentRedactionPolicy()
+	previousLeaves, currentLeaves := Leaves(previous), Leaves(current)
+	redactedPrevious := Leaves(policy.RedactResolved(previous, unresolvedMaps...))
+	redactedCurrent := Leaves(policy.RedactResolved(current, unresolvedMaps...))
+
+	var changes []ConfigChange
+	for path, old := range previousLeaves {
+		value, ok := currentLeaves[path]
+		switch {
+		case !ok:
+			changes = append(changes, ConfigChange{Path: path, Kind: ConfigChangeRemoved, Old: redactedPrevious[path]})
+		case !reflect.DeepEqual(old, value):
+			changes = append(changes, ConfigChange{Path: path, Kind: ConfigChangeChanged, Old: redactedPrevious[path], New: redactedCurrent[path]})
+		}
+	}
+	for path := range currentLeaves {
+		if _, ok := previousLeaves[path]; !ok {
+			changes = append(changes, ConfigChange{Path: path, Kind: ConfigChangeAdded, New: redactedCurrent[path]})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes
+}
+
+// Leaves flattens the provided config into its non-map (or empty map) values by
+// confmap.KeyDelimiter separated path. Lists are leaves since they are replaced
+// rather than merged.
+func Leaves(config map[string]any) map[string]any {
+	out := map[string]any{}
+	var walk func(m map[string]any, prefix string)
+	walk = func(m map[string]any, prefix string) {
+		for k, v := range m {
+			path := JoinPath(prefix, k)
+			if sub, ok := v.(map[string]any); ok && len(sub) > 0 {
+				walk(sub, path)
+				continue
+			}
+			out[path] = v
+		}
+	}
+	walk(config, "")
+	return out
+}
+
+// JoinPath returns the confmap.KeyDelimiter separated path of the key under the prefix path.
+func JoinPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + confmap.KeyDelimiter + key
+}
@@ -0,0 +1,96 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configsource
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/confmap"
+)
+
+func TestDiffResolved(t *testing.T) {
+	unresolved := confmap.NewFromStringMap(map[string]any{
+		"exporters": map[string]any{
+			"otlp": map[string]any{
+				"endpoint": "${tstcfgsrc:endpoint}",
+				"headers":  map[string]any{"token": "Bearer ${tstcfgsrc:token}"},
+				"timeout":  "10s",
+			},
+		},
+		"receivers": "${include:receivers.yaml}",
+		"service": map[string]any{
+			"pipelines": map[string]any{
+				"metrics": map[string]any{
+					"receivers": []any{"otlp"},
+					"exporters": []any{"otlp", "${env:EXTRA_EXPORTER}"},
+				},
+			},
+		},
+	})
+	previous := map[string]any{
+		"exporters": map[string]any{
+			"otlp": map[string]any{
+				"endpoint": "collector:4317",
+				"headers":  map[string]any{"token": "Bearer old"},
+				"timeout":  "10s",
+			},
+		},
+		"receivers": map[string]any{"otlp": map[string]any{"protocols": map[string]any{"grpc": nil}}},
+		"service": map[string]any{
+			"pipelines": map[string]any{
+				"metrics": map[string]any{
+					"receivers": []any{"otlp"},
+					"exporters": []any{"otlp", "debug"},
+				},
+			},
+		},
+	}
+	current := map[string]any{
+		"exporters": map[string]any{
+			"otlp": map[string]any{
+				"endpoint": "collector:4317",
+				"headers":  map[string]any{"token": "Bearer new"},
+				"timeout":  "20s",
+			},
+		},
+		"receivers": map[string]any{"otlp": map[string]any{"protocols": map[string]any{"http": nil}}},
+		"service": map[string]any{
+			"pipelines": map[string]any{
+				"metrics": map[string]any{
+					"receivers": []any{"otlp"},
+					"exporters": []any{"otlp", "file"},
+				},
+			},
+		},
+	}
+
+	changes := DiffResolved(unresolved, previous, current)
+	assert.Equal(t, []ConfigChange{
+		{Path: "exporters::otlp::headers::token", Kind: ConfigChangeChanged, Old: RedactedValue, New: RedactedValue},
+		{Path: "exporters::otlp::timeout", Kind: ConfigChangeChanged, Old: "10s", New: "20s"},
+		{Path: "receivers::otlp::protocols::grpc", Kind: ConfigChangeRemoved},
+		{Path: "receivers::otlp::protocols::http", Kind: ConfigChangeAdded},
+		{Path: "service::pipelines::metrics::exporters", Kind: ConfigChangeChanged, Old: []any{RedactedValue, RedactedValue}, New: []any{RedactedValue, RedactedValue}},
+	}, changes)
+	assert.Equal(t, "~ exporters::otlp::timeout: 10s -> 20s", changes[1].String())
+	assert.Equal(t, "- receivers::otlp::protocols::grpc: <nil>", changes[2].String())
+
+	assert.Empty(t, DiffResolved(unresolved, previous, previous))
+	assert.Equal(t, []ConfigChange{
+		{Path: "key", Kind: ConfigChangeAdded, New: "value"},
+		{Path: "password", Kind: ConfigChangeAdded, New: RedactedValue},
+	}, DiffResolved(nil, nil, map[string]any{"key": "value", "password": "value"}))
+}
@@ -18,6 +18,7 @@ package includeconfigsource
 import (
 	"bytes"
 	"context"
+	"os"
 	"text/template"
 
 	"github.com/fsnotify/fsnotify"
@@ -42,7 +43,13 @@ func newConfigSource(config *Config, _ *zap.Logger) (configsource.ConfigSource,
 }
 
 func (is *includeConfigSource) Retrieve(_ context.Context, selector string, paramsConfigMap *confmap.Conf, watcher confmap.WatcherFunc) (*confmap.Retrieved, error) {
-	tmpl, err := template.ParseFiles(selector)
+	content, err := os.ReadFile(selector)
+	if err != nil {
+		return nil, err
+	}
+	// The template is named after the file path so that parsing and execution errors
+	// are reported with the included file path and line.
+	tmpl, err := template.New(selector).Funcs(templateFuncs()).Parse(string(content))
 	if err != nil {
 		return nil, err
 	}
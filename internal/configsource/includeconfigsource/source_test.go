@@ -17,6 +17,7 @@ package includeconfigsource
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path"
 	"testing"
@@ -51,6 +52,38 @@ func TestIncludeConfigSource_Session(t *testing.T) {
 			selector: "no_params_template",
 			expected: "bool_field: true",
 		},
+		{
+			name:     "funcs_template",
+			selector: "funcs_template",
+			params: map[string]any{
+				"name":     "cache",
+				"interval": "",
+				"tls": map[string]any{
+					"insecure":    false,
+					"ca_file":     "/etc/ssl/ca.pem",
+					"min_version": "1.3",
+				},
+			},
+			expected: "receivers:\n" +
+				"  redis/cache:\n" +
+				"    endpoint: localhost:6379\n" +
+				"    password: s3cr3t\n" +
+				"    collection_interval: 10s\n" +
+				"    tls:\n" +
+				"      ca_file: /etc/ssl/ca.pem\n" +
+				"      insecure: false\n" +
+				"      min_version: \"1.3\"\n",
+		},
+		{
+			name:     "invalid_template",
+			selector: "invalid_template",
+			wantErr:  errors.New(`template: testdata/invalid_template:3: function "unknown_func" not defined`),
+		},
+		{
+			name:     "invalid_exec_template",
+			selector: "invalid_exec_template",
+			wantErr:  errors.New(`template: testdata/invalid_exec_template:3:12: executing "testdata/invalid_exec_template" at <toYaml>: wrong number of args for toYaml: want 1 got 0`),
+		},
 		{
 			name:     "param_template",
 			selector: "param_template",
@@ -61,6 +94,7 @@ func TestIncludeConfigSource_Session(t *testing.T) {
 		},
 	}
 
+	t.Setenv("INCLUDE_TEST_REDIS_PASSWORD", "s3cr3t")
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s, err := newConfigSource(&Config{}, zap.NewNop())
@@ -71,6 +105,9 @@ func TestIncludeConfigSource_Session(t *testing.T) {
 			r, err := s.Retrieve(ctx, file, confmap.NewFromStringMap(tt.params), nil)
 			if tt.wantErr != nil {
 				require.Error(t, err)
+				if _, isPathErr := tt.wantErr.(*os.PathError); !isPathErr {
+					require.EqualError(t, err, tt.wantErr.Error())
+				}
 				assert.Nil(t, r)
 				return
 			}
@@ -0,0 +1,63 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package includeconfigsource
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v2"
+)
+
+// templateFuncs returns the functions available to included templates. They are limited
+// to environment variable lookups and formatting helpers so templates can't read files
+// or run commands.
+func templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"env":     os.Getenv,
+		"default": defaultValue,
+		"toYaml":  toYAML,
+		"indent":  indent,
+	}
+}
+
+// defaultValue returns value unless it is missing or empty, in which case it returns def.
+// It's meant to be used in pipelines, e.g. {{ .port | default 8080 }}.
+func defaultValue(def, value any) any {
+	if value == nil {
+		return def
+	}
+	if v := reflect.ValueOf(value); v.IsZero() || (v.Kind() == reflect.Map || v.Kind() == reflect.Slice) && v.Len() == 0 {
+		return def
+	}
+	return value
+}
+
+// toYAML returns the YAML representation of value without a trailing newline.
+func toYAML(value any) (string, error) {
+	out, err := yaml.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+// indent prefixes every line of s with the given number of spaces.
+func indent(spaces int, s string) string {
+	pad := strings.Repeat(" ", spaces)
+	return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+}
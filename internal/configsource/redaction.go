@@ -0,0 +1,319 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configsource
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"github.com/spf13/cast"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// RedactionPolicyEnvVar is the path of a YAML file extending the default RedactionPolicy.
+	RedactionPolicyEnvVar = "SPLUNK_REDACTION_POLICY_FILE"
+
+	// RedactedValue replaces the config values redacted by a RedactionPolicy.
+	RedactedValue = "<redacted>"
+)
+
+var (
+	// defaultRedactionPolicyConfig redacts the values of secret-looking keys and
+	// well-known credential formats.
+	defaultRedactionPolicyConfig = RedactionPolicyConfig{
+		KeyPatterns: []string{
+			"(?i)access",
+			"(?i)api_?key",
+			"(?i)auth",
+			"(?i)cred",
+			"(?i)login",
+			"(?i)password",
+			"(?i)pwd",
+			"(?i)secret",
+			"(?i)token",
+			"(?i)user",
+		},
+		ValuePatterns: []string{
+			// bearer and basic authorization header values
+			`(?i)\b(bearer|basic)\s+[a-z0-9\-._~+/]+=*`,
+			// AWS access key IDs and secret access keys
+			`\b(AKIA|ASIA)[0-9A-Z]{16}\b`,
+			`(?i)aws.{0,20}['"]?[0-9a-z/+]{40}['"]?`,
+			// PEM encoded private keys
+			`-----BEGIN [A-Z ]*PRIVATE KEY-----`,
+		},
+	}
+
+	redactionPolicy atomic.Pointer[RedactionPolicy]
+)
+
+func init() {
+	policy, err := NewRedactionPolicy(RedactionPolicyConfig{})
+	if err != nil {
+		panic(err)
+	}
+	redactionPolicy.Store(policy)
+}
+
+// RedactionPolicyConfig is the YAML content of a RedactionPolicyEnvVar file. Its patterns
+// are regular expressions added to the default ones.
+type RedactionPolicyConfig struct {
+	// KeyPatterns match the keys whose values are redacted.
+	KeyPatterns []string `yaml:"key_patterns"`
+	// ValuePatterns match the string values to redact regardless of their key.
+	ValuePatterns []string `yaml:"value_patterns"`
+	// AllowedKeys match the keys whose values aren't redacted by KeyPatterns and ValuePatterns.
+	AllowedKeys []string `yaml:"allowed_keys"`
+}
+
+// RedactionPolicy determines the config values replaced by RedactedValue in the
+// --dry-run, --explain, and expvar config output, and in the config source reload diffs.
+type RedactionPolicy struct {
+	keyPatterns   []*regexp.Regexp
+	valuePatterns []*regexp.Regexp
+	allowedKeys   []*regexp.Regexp
+}
+
+// NewRedactionPolicy creates a RedactionPolicy with the provided patterns in addition to the default ones.
+func NewRedactionPolicy(cfg RedactionPolicyConfig) (*RedactionPolicy, error) {
+	policy := &RedactionPolicy{}
+	var err error
+	if policy.keyPatterns, err = compilePatterns("key_patterns", defaultRedactionPolicyConfig.KeyPatterns, cfg.KeyPatterns); err != nil {
+		return nil, err
+	}
+	if policy.valuePatterns, err = compilePatterns("value_patterns", defaultRedactionPolicyConfig.ValuePatterns, cfg.ValuePatterns); err != nil {
+		return nil, err
+	}
+	if policy.allowedKeys, err = compilePatterns("allowed_keys", defaultRedactionPolicyConfig.AllowedKeys, cfg.AllowedKeys); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// LoadRedactionPolicy creates a RedactionPolicy from the RedactionPolicyConfig YAML file at the provided path.
+func LoadRedactionPolicy(path string) (*RedactionPolicy, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading redaction policy: %w", err)
+	}
+	var cfg RedactionPolicyConfig
+	if err = yaml.UnmarshalStrict(content, &cfg); err != nil {
+		return nil, fmt.Errorf("failed parsing redaction policy: %w", err)
+	}
+	return NewRedactionPolicy(cfg)
+}
+
+// SetRedactionPolicyFromEnv sets the RedactionPolicy loaded from the RedactionPolicyEnvVar
+// file, if set, as the one used by Redact, DiffResolved, and the config output.
+func SetRedactionPolicyFromEnv() error {
+	path, ok := os.LookupEnv(RedactionPolicyEnvVar)
+	if !ok || path == "" {
+		return nil
+	}
+	policy, err := LoadRedactionPolicy(path)
+	if err != nil {
+		return err
+	}
+	SetRedactionPolicy(policy)
+	return nil
+}
+
+// SetRedactionPolicy sets the RedactionPolicy used by Redact, DiffResolved, and the config output.
+func SetRedactionPolicy(policy *RedactionPolicy) {
+	redactionPolicy.Store(policy)
+}
+
+// CurrentRedactionPolicy returns the RedactionPolicy used by Redact, DiffResolved, and the config output.
+func CurrentRedactionPolicy() *RedactionPolicy {
+	return redactionPolicy.Load()
+}
+
+func compilePatterns(field string, patternLists ...[]string) ([]*regexp.Regexp, error) {
+	var compiled []*regexp.Regexp
+	for _, patterns := range patternLists {
+		for _, pattern := range patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s pattern %q: %w", field, pattern, err)
+			}
+			compiled = append(compiled, re)
+		}
+	}
+	return compiled, nil
+}
+
+// Redact returns a copy of the provided config with its secret values replaced by RedactedValue
+// according to the current RedactionPolicy.
+func Redact(config map[string]any) map[string]any {
+	return redactionPolicy.Load().Redact(config)
+}
+
+// Redact returns a copy of the provided config with the string values of keys matching the key
+// patterns, and those matching the value patterns, replaced by RedactedValue.
+func (p *RedactionPolicy) Redact(config map[string]any) map[string]any {
+	return p.RedactResolved(config)
+}
+
+// RedactResolved is like Redact for a config resolved from the provided unresolved ones. All values
+// set by a config source or env var directive in an unresolved config are redacted regardless of
+// their key since they are likely secrets.
+func (p *RedactionPolicy) RedactResolved(config map[string]any, unresolved ...map[string]any) map[string]any {
+	return p.redactMap(config, unresolved)
+}
+
+func (p *RedactionPolicy) redactMap(config map[string]any, unresolved []map[string]any) map[string]any {
+	redacted := make(map[string]any, len(config))
+	for k, v := range config {
+		var unresolvedValues []map[string]any
+		resolved := false
+		for _, u := range unresolved {
+			switch uv := u[k].(type) {
+			case map[string]any:
+				unresolvedValues = append(unresolvedValues, uv)
+			default:
+				resolved = resolved || ContainsDirective(uv)
+			}
+		}
+		if resolved {
+			redacted[k] = redactAll(v)
+			continue
+		}
+		redacted[k] = p.redactValue(k, v, unresolvedValues)
+	}
+	return redacted
+}
+
+func (p *RedactionPolicy) redactValue(key string, value any, unresolved []map[string]any) any {
+	switch v := value.(type) {
+	case string:
+		if !p.isAllowed(key) && (p.matchesKey(key) || p.matchesValue(v)) {
+			return RedactedValue
+		}
+		return v
+	case map[string]any:
+		return p.redactMap(v, unresolved)
+	case map[any]any:
+		return p.redactMap(cast.ToStringMap(v), unresolved)
+	case []any:
+		redacted := make([]any, 0, len(v))
+		for _, e := range v {
+			redacted = append(redacted, p.redactValue(key, e, nil))
+		}
+		return redacted
+	default:
+		return v
+	}
+}
+
+func (p *RedactionPolicy) isAllowed(key string) bool {
+	for _, re := range p.allowedKeys {
+		if re.MatchString(key) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *RedactionPolicy) matchesKey(key string) bool {
+	for _, re := range p.keyPatterns {
+		if re.MatchString(key) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *RedactionPolicy) matchesValue(value string) bool {
+	for _, re := range p.valuePatterns {
+		if re.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactAll replaces every scalar of the provided value by RedactedValue.
+func redactAll(value any) any {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case map[string]any:
+		redacted := make(map[string]any, len(v))
+		for k, e := range v {
+			redacted[k] = redactAll(e)
+		}
+		return redacted
+	case map[any]any:
+		return redactAll(cast.ToStringMap(v))
+	case []any:
+		redacted := make([]any, 0, len(v))
+		for _, e := range v {
+			redacted = append(redacted, redactAll(e))
+		}
+		return redacted
+	default:
+		return RedactedValue
+	}
+}
+
+// ContainsDirective reports whether the provided unresolved value references a config source or env var
+// with the ${...} or $name: syntax parsed by ResolveWithConfigSources. The $$ escaped and other '$' characters
+// aren't directives.
+func ContainsDirective(value any) bool {
+	switch v := value.(type) {
+	case string:
+		return containsStringDirective(v)
+	case []any:
+		for _, e := range v {
+			if ContainsDirective(e) {
+				return true
+			}
+		}
+	case map[string]any:
+		for _, e := range v {
+			if ContainsDirective(e) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func containsStringDirective(s string) bool {
+	for j := 0; j+1 < len(s); j++ {
+		if s[j] != expandPrefixChar {
+			continue
+		}
+		switch next := s[j+1]; {
+		case next == expandPrefixChar:
+			// $$ escapes the '$' character.
+			j++
+		case next == '{':
+			if content, _ := scanToClosingBracket(s[j+1:]); strings.TrimSpace(content) != "" {
+				return true
+			}
+		case 'a' <= next && next <= 'z' || 'A' <= next && next <= 'Z':
+			name, consumed := getTokenName(s[j+1:])
+			if name != "" && j+1+consumed < len(s) && s[j+1+consumed] == configSourceNameDelimChar {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secretfileconfigsource
+
+import (
+	"github.com/signalfx/splunk-otel-collector/internal/configsource"
+)
+
+// Config holds the configuration for the creation of secretfile config source objects.
+type Config struct {
+	configsource.SourceSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+	// Directory is the directory containing the secret files, e.g. a Kubernetes Secret
+	// volume mount or /run/secrets for Docker secrets. It is required.
+	Directory string `mapstructure:"directory"`
+	// Base64Decode is used to base64-decode the content of the secret files. It can be
+	// overridden for a specific reference with the "base64" parameter. The default value is 'false'.
+	Base64Decode bool `mapstructure:"base64_decode"`
+	// StrictPermissions is used to reject secret files that are world-readable.
+	// It isn't applied on Windows. The default value is 'false'.
+	StrictPermissions bool `mapstructure:"strict_permissions"`
+	// WatchFiles is used to control if the referenced files should be watched for
+	// rotation, triggering a configuration reload. The default value is 'true'.
+	WatchFiles bool `mapstructure:"watch_files"`
+}
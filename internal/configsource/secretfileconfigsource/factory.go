@@ -0,0 +1,74 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secretfileconfigsource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configsource"
+)
+
+const (
+	// The "type" of secretfile config sources in configuration.
+	typeStr = "secretfile"
+)
+
+// Private error types to help with testability.
+type (
+	errMissingDirectory struct{ error }
+	errInvalidDirectory struct{ error }
+)
+
+type secretFileFactory struct{}
+
+func (f *secretFileFactory) Type() component.Type {
+	return component.MustNewType(typeStr)
+}
+
+func (f *secretFileFactory) CreateDefaultConfig() configsource.Settings {
+	return &Config{
+		SourceSettings: configsource.NewSourceSettings(component.MustNewID(typeStr)),
+		WatchFiles:     true,
+	}
+}
+
+func (f *secretFileFactory) CreateConfigSource(_ context.Context, settings configsource.Settings, logger *zap.Logger) (configsource.ConfigSource, error) {
+	cfg := settings.(*Config)
+
+	if cfg.Directory == "" {
+		return nil, &errMissingDirectory{errors.New("directory cannot be empty")}
+	}
+
+	info, err := os.Stat(cfg.Directory)
+	if err != nil {
+		return nil, &errInvalidDirectory{fmt.Errorf("invalid directory %q: %w", cfg.Directory, err)}
+	}
+	if !info.IsDir() {
+		return nil, &errInvalidDirectory{fmt.Errorf("invalid directory %q: not a directory", cfg.Directory)}
+	}
+
+	return newConfigSource(cfg, logger), nil
+}
+
+// NewFactory creates a factory for secretfile ConfigSource objects.
+func NewFactory() configsource.Factory {
+	return &secretFileFactory{}
+}
@@ -0,0 +1,70 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secretfileconfigsource
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+)
+
+func TestSecretFileConfigSourceFactory_CreateConfigSource(t *testing.T) {
+	factory := NewFactory()
+	assert.Equal(t, component.MustNewType("secretfile"), factory.Type())
+
+	tests := []struct {
+		wantErr error
+		name    string
+		config  Config
+	}{
+		{
+			name:    "missing_directory",
+			wantErr: &errMissingDirectory{},
+		},
+		{
+			name:    "nonexistent_directory",
+			config:  Config{Directory: filepath.Join(t.TempDir(), "missing")},
+			wantErr: &errInvalidDirectory{},
+		},
+		{
+			name:    "file_as_directory",
+			config:  Config{Directory: filepath.Join("testdata", "config.yaml")},
+			wantErr: &errInvalidDirectory{},
+		},
+		{
+			name:   "success",
+			config: Config{Directory: t.TempDir()},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := factory.CreateConfigSource(context.Background(), &tt.config, zap.NewNop())
+			if tt.wantErr != nil {
+				require.IsType(t, tt.wantErr, err)
+				assert.Nil(t, actual)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NotNil(t, actual)
+		})
+	}
+}
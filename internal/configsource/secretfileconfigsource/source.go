@@ -0,0 +1,149 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secretfileconfigsource
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/cast"
+	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configsource"
+)
+
+// base64Param is the reference parameter overriding Config.Base64Decode.
+const base64Param = "base64"
+
+// Private error types to help with testability.
+type (
+	errInvalidSecretName  struct{ error }
+	errInsecurePermission struct{ error }
+)
+
+// secretFileConfigSource implements the configsource.ConfigSource interface.
+type secretFileConfigSource struct {
+	*Config
+	logger *zap.Logger
+}
+
+func newConfigSource(config *Config, logger *zap.Logger) configsource.ConfigSource {
+	return &secretFileConfigSource{
+		Config: config,
+		logger: logger,
+	}
+}
+
+func (s *secretFileConfigSource) Retrieve(_ context.Context, selector string, paramsConfigMap *confmap.Conf, watcher confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	if !filepath.IsLocal(selector) {
+		return nil, &errInvalidSecretName{fmt.Errorf("secret name %q must be a path local to the directory", selector)}
+	}
+	path := filepath.Join(s.Directory, selector)
+
+	decode := s.Base64Decode
+	if paramsConfigMap != nil && paramsConfigMap.IsSet(base64Param) {
+		var err error
+		if decode, err = cast.ToBoolE(paramsConfigMap.Get(base64Param)); err != nil {
+			return nil, fmt.Errorf("invalid %q parameter: %w", base64Param, err)
+		}
+	}
+
+	content, err := s.readFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	value := strings.TrimRight(string(content), "\r\n")
+	if decode {
+		decoded, decodeErr := base64.StdEncoding.DecodeString(value)
+		if decodeErr != nil {
+			return nil, fmt.Errorf("failed to base64-decode secret %q: %w", selector, decodeErr)
+		}
+		value = string(decoded)
+	}
+
+	if !s.WatchFiles || watcher == nil {
+		return confmap.NewRetrieved(value)
+	}
+
+	closeFunc, err := s.watchFile(path, content, watcher)
+	if err != nil {
+		return nil, err
+	}
+	return confmap.NewRetrieved(value, confmap.WithRetrievedClose(closeFunc))
+}
+
+func (s *secretFileConfigSource) readFile(path string) ([]byte, error) {
+	if s.StrictPermissions && runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if info.Mode().Perm()&0o004 != 0 {
+			return nil, &errInsecurePermission{fmt.Errorf("secret file %q must not be world-readable", path)}
+		}
+	}
+	return os.ReadFile(path)
+}
+
+// watchFile watches the directory of the secret file instead of the file itself since
+// secrets are typically rotated by replacing the file, or for Kubernetes Secret volumes
+// by swapping the symlink to its parent directory. The watcher is notified once the
+// file content differs from the retrieved content.
+func (s *secretFileConfigSource) watchFile(path string, content []byte, watcherFunc confmap.WatcherFunc) (confmap.CloseFunc, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err = watcher.Add(filepath.Dir(path)); err != nil {
+		_ = watcher.Close()
+		return nil, err
+	}
+
+	go func() {
+		for {
+			select {
+			case _, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if latest, readErr := os.ReadFile(path); readErr == nil && bytes.Equal(latest, content) {
+					continue
+				}
+				s.logger.Debug("secret file changed", zap.String("path", path))
+				watcherFunc(&confmap.ChangeEvent{Error: nil})
+				return
+			case watcherErr, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				watcherFunc(&confmap.ChangeEvent{Error: watcherErr})
+				return
+			}
+		}
+	}()
+
+	return func(_ context.Context) error {
+		return watcher.Close()
+	}, nil
+}
@@ -0,0 +1,201 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secretfileconfigsource
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/zap"
+)
+
+func TestSecretFileConfigSourceRetrieve(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "password"), []byte("s3cr3t\n\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "encoded"), []byte("czNjcjN0Cg==\n"), 0o600))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "nested"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "nested", "token"), []byte(" spaced token \r\n"), 0o600))
+
+	tests := []struct {
+		params   map[string]any
+		expected any
+		wantErr  any
+		name     string
+		selector string
+		config   Config
+	}{
+		{
+			name:     "trailing_newlines_trimmed",
+			selector: "password",
+			expected: "s3cr3t",
+		},
+		{
+			name:     "nested_file",
+			selector: "nested/token",
+			expected: " spaced token ",
+		},
+		{
+			name:     "base64_decode",
+			selector: "encoded",
+			config:   Config{Base64Decode: true},
+			expected: "s3cr3t\n",
+		},
+		{
+			name:     "base64_param",
+			selector: "encoded",
+			params:   map[string]any{"base64": "true"},
+			expected: "s3cr3t\n",
+		},
+		{
+			name:     "base64_param_override",
+			selector: "encoded",
+			config:   Config{Base64Decode: true},
+			params:   map[string]any{"base64": false},
+			expected: "czNjcjN0Cg==",
+		},
+		{
+			name:     "invalid_base64",
+			selector: "password",
+			config:   Config{Base64Decode: true},
+			wantErr:  "failed to base64-decode secret \"password\"",
+		},
+		{
+			name:     "invalid_base64_param",
+			selector: "password",
+			params:   map[string]any{"base64": "maybe"},
+			wantErr:  "invalid \"base64\" parameter",
+		},
+		{
+			name:     "missing_file",
+			selector: "missing",
+			wantErr:  "no such file or directory",
+		},
+		{
+			name:     "outside_directory",
+			selector: "../password",
+			wantErr:  &errInvalidSecretName{},
+		},
+		{
+			name:     "absolute_path",
+			selector: "/etc/passwd",
+			wantErr:  &errInvalidSecretName{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.Directory = dir
+			source := newConfigSource(&tt.config, zap.NewNop())
+			var params *confmap.Conf
+			if tt.params != nil {
+				params = confmap.NewFromStringMap(tt.params)
+			}
+			retrieved, err := source.Retrieve(context.Background(), tt.selector, params, nil)
+			switch wantErr := tt.wantErr.(type) {
+			case nil:
+				require.NoError(t, err)
+				actual, rawErr := retrieved.AsRaw()
+				require.NoError(t, rawErr)
+				assert.Equal(t, tt.expected, actual)
+			case string:
+				require.ErrorContains(t, err, wantErr)
+				assert.Nil(t, retrieved)
+			default:
+				require.IsType(t, wantErr, err)
+				assert.Nil(t, retrieved)
+			}
+		})
+	}
+}
+
+func TestSecretFileConfigSourceStrictPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file permissions aren't checked on windows")
+	}
+	dir := t.TempDir()
+	secretFile := filepath.Join(dir, "password")
+	require.NoError(t, os.WriteFile(secretFile, []byte("s3cr3t"), 0o600))
+	require.NoError(t, os.Chmod(secretFile, 0o644))
+
+	source := newConfigSource(&Config{Directory: dir}, zap.NewNop())
+	_, err := source.Retrieve(context.Background(), "password", nil, nil)
+	require.NoError(t, err)
+
+	source = newConfigSource(&Config{Directory: dir, StrictPermissions: true}, zap.NewNop())
+	_, err = source.Retrieve(context.Background(), "password", nil, nil)
+	require.IsType(t, &errInsecurePermission{}, err)
+
+	require.NoError(t, os.Chmod(secretFile, 0o640))
+	retrieved, err := source.Retrieve(context.Background(), "password", nil, nil)
+	require.NoError(t, err)
+	actual, err := retrieved.AsRaw()
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", actual)
+}
+
+func TestSecretFileConfigSourceWatchRotation(t *testing.T) {
+	dir := t.TempDir()
+	secretFile := filepath.Join(dir, "password")
+	require.NoError(t, os.WriteFile(secretFile, []byte("s3cr3t\n"), 0o600))
+
+	source := newConfigSource(&Config{Directory: dir, WatchFiles: true}, zap.NewNop())
+	watchChannel := make(chan *confmap.ChangeEvent, 1)
+	retrieved, err := source.Retrieve(context.Background(), "password", nil, func(event *confmap.ChangeEvent) {
+		watchChannel <- event
+	})
+	require.NoError(t, err)
+
+	// Unrelated changes in the directory don't trigger a reload.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "other"), []byte("other"), 0o600))
+	require.NoError(t, os.Chtimes(secretFile, time.Now(), time.Now()))
+	select {
+	case <-watchChannel:
+		t.Fatal("watcher must not be called for unchanged secret")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// Rotate the secret by atomically replacing the file.
+	rotated := filepath.Join(dir, ".password.tmp")
+	require.NoError(t, os.WriteFile(rotated, []byte("n3w s3cr3t\n"), 0o600))
+	require.NoError(t, os.Rename(rotated, secretFile))
+
+	select {
+	case event := <-watchChannel:
+		assert.NoError(t, event.Error)
+	case <-time.After(5 * time.Second):
+		t.Fatal("watcher wasn't called for rotated secret")
+	}
+	require.NoError(t, retrieved.Close(context.Background()))
+}
+
+func TestSecretFileConfigSourceWatchFilesDisabled(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "password"), []byte("s3cr3t"), 0o600))
+
+	source := newConfigSource(&Config{Directory: dir}, zap.NewNop())
+	retrieved, err := source.Retrieve(context.Background(), "password", nil, func(*confmap.ChangeEvent) {
+		panic("must not be called")
+	})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "password"), []byte("n3w s3cr3t"), 0o600))
+	require.NoError(t, retrieved.Close(context.Background()))
+}
@@ -0,0 +1,84 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vaultconfigsource
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/vault/api"
+)
+
+type AppRoleAuthentication struct {
+	// RoleID is the role ID of the AppRole. Exactly one of RoleID or RoleIDFile is required.
+	RoleID *string `mapstructure:"role_id"`
+	// RoleIDFile is the path of a file containing the role ID of the AppRole.
+	RoleIDFile *string `mapstructure:"role_id_file"`
+	// SecretID is the secret ID to log in with. It is optional for roles not binding secret IDs.
+	SecretID *string `mapstructure:"secret_id"`
+	// SecretIDFile is the path of a file containing the secret ID to log in with.
+	SecretIDFile *string `mapstructure:"secret_id_file"`
+	// Mount is the path where the AppRole auth method is mounted. The default value is "approle".
+	Mount *string `mapstructure:"mount"`
+}
+
+func (a *AppRoleAuthentication) Token(client *api.Client) (string, error) {
+	mount := "approle"
+	if a.Mount != nil {
+		mount = *a.Mount
+	}
+
+	// Files are read on every login so that rotated secret IDs are used.
+	roleID, err := valueOrFileContent(a.RoleID, a.RoleIDFile)
+	if err != nil {
+		return "", fmt.Errorf("failed reading role_id_file: %w", err)
+	}
+	secretID, err := valueOrFileContent(a.SecretID, a.SecretIDFile)
+	if err != nil {
+		return "", fmt.Errorf("failed reading secret_id_file: %w", err)
+	}
+
+	loginData := map[string]any{"role_id": roleID}
+	if secretID != "" {
+		loginData["secret_id"] = secretID
+	}
+
+	secret, err := client.Logical().Write(fmt.Sprintf("auth/%s/login", mount), loginData)
+	if err != nil {
+		return "", err
+	}
+	if secret == nil || secret.Auth == nil {
+		return "", errors.New("empty response from credential provider")
+	}
+
+	return secret.Auth.ClientToken, nil
+}
+
+// valueOrFileContent returns the value if set, otherwise the whitespace-trimmed content of the file if set.
+func valueOrFileContent(value, file *string) (string, error) {
+	if value != nil {
+		return *value, nil
+	}
+	if file == nil {
+		return "", nil
+	}
+	content, err := os.ReadFile(*file)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
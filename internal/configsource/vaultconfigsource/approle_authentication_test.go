@@ -0,0 +1,61 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vaultconfigsource
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppRoleAuthenticationToken(t *testing.T) {
+	server := newFakeVaultServer(t)
+	client, err := api.NewClient(&api.Config{Address: server.URL})
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	roleIDFile := filepath.Join(dir, "role_id")
+	require.NoError(t, os.WriteFile(roleIDFile, []byte("file-role-id\n"), 0o600))
+	secretIDFile := filepath.Join(dir, "secret_id")
+	require.NoError(t, os.WriteFile(secretIDFile, []byte("file-secret-id\n"), 0o600))
+	roleID := "a-role-id"
+	secretID := "a-secret-id"
+	mount := "custom-approle"
+
+	for _, authentication := range []AppRoleAuthentication{
+		{RoleID: &roleID, SecretID: &secretID, Mount: &mount},
+		{RoleIDFile: &roleIDFile, SecretIDFile: &secretIDFile},
+		{RoleIDFile: &roleIDFile},
+	} {
+		_, err = authentication.Token(client)
+		require.NoError(t, err)
+	}
+
+	logins := server.getLogins()
+	require.Len(t, logins, 3)
+	assert.Equal(t, "/v1/auth/custom-approle/login", logins[0].path)
+	assert.Equal(t, map[string]any{"role_id": "a-role-id", "secret_id": "a-secret-id"}, logins[0].data)
+	assert.Equal(t, "/v1/auth/approle/login", logins[1].path)
+	assert.Equal(t, map[string]any{"role_id": "file-role-id", "secret_id": "file-secret-id"}, logins[1].data)
+	assert.Equal(t, map[string]any{"role_id": "file-role-id"}, logins[2].data)
+
+	missing := filepath.Join(dir, "missing")
+	_, err = (&AppRoleAuthentication{RoleID: &roleID, SecretIDFile: &missing}).Token(client)
+	require.ErrorContains(t, err, "failed reading secret_id_file")
+}
@@ -48,4 +48,9 @@ type Authentication struct {
 	// GCPAuthentication holds the authentication options for GCP. The options
 	// are the same as the vault CLI tool, see https://github.com/hashicorp/vault-plugin-auth-gcp/blob/e1f6784b379d277038ca0661606aa8d23791e392/plugin/cli.go#L120.
 	GCPAuthentication *GCPAuthentication `mapstructure:"gcp"`
+	// KubernetesAuthentication holds the authentication options for the Kubernetes auth
+	// method, using the pod's service account JWT.
+	KubernetesAuthentication *KubernetesAuthentication `mapstructure:"kubernetes"`
+	// AppRoleAuthentication holds the authentication options for the AppRole auth method.
+	AppRoleAuthentication *AppRoleAuthentication `mapstructure:"approle"`
 }
@@ -38,10 +38,12 @@ const (
 type (
 	errEmptyAuth               struct{ error }
 	errEmptyToken              struct{ error }
+	errInvalidAppRole          struct{ error }
 	errInvalidEndpoint         struct{ error }
 	errMissingAuthentication   struct{ error }
 	errMissingEndpoint         struct{ error }
 	errMissingPath             struct{ error }
+	errMissingRole             struct{ error }
 	errMultipleAuthMethods     struct{ error }
 	errNonPositivePollInterval struct{ error }
 )
@@ -111,6 +113,23 @@ func validateAuth(auth *Authentication) error {
 		countMethods++
 	}
 
+	if k := auth.KubernetesAuthentication; k != nil {
+		countMethods++
+		if k.Role == nil || *k.Role == "" {
+			return &errMissingRole{errors.New("kubernetes auth role cannot be empty")}
+		}
+	}
+
+	if a := auth.AppRoleAuthentication; a != nil {
+		countMethods++
+		if (a.RoleID == nil) == (a.RoleIDFile == nil) {
+			return &errInvalidAppRole{errors.New("exactly one of approle auth role_id or role_id_file must be set")}
+		}
+		if a.SecretID != nil && a.SecretIDFile != nil {
+			return &errInvalidAppRole{errors.New("only one of approle auth secret_id or secret_id_file can be set")}
+		}
+	}
+
 	if countMethods == 0 {
 		return &errEmptyAuth{errors.New("auth cannot be empty, exactly one method must be used")}
 	}
@@ -88,6 +88,39 @@ func TestVaultFactory_CreateConfigSource(t *testing.T) {
 			},
 			wantErr: &errEmptyToken{},
 		},
+		{
+			name: "missing_kubernetes_role",
+			config: &Config{
+				Endpoint: "http://localhost:8200",
+				Path:     "some/path",
+				Authentication: &Authentication{
+					KubernetesAuthentication: &KubernetesAuthentication{},
+				},
+			},
+			wantErr: &errMissingRole{},
+		},
+		{
+			name: "missing_approle_role_id",
+			config: &Config{
+				Endpoint: "http://localhost:8200",
+				Path:     "some/path",
+				Authentication: &Authentication{
+					AppRoleAuthentication: &AppRoleAuthentication{},
+				},
+			},
+			wantErr: &errInvalidAppRole{},
+		},
+		{
+			name: "approle_role_id_and_role_id_file",
+			config: &Config{
+				Endpoint: "http://localhost:8200",
+				Path:     "some/path",
+				Authentication: &Authentication{
+					AppRoleAuthentication: &AppRoleAuthentication{RoleID: &emptyStr, RoleIDFile: &emptyStr},
+				},
+			},
+			wantErr: &errInvalidAppRole{},
+		},
 		{
 			name: "missing_path",
 			config: &Config{
@@ -126,6 +159,7 @@ func TestVaultFactory_CreateConfigSource(t *testing.T) {
 			actual, err := factory.CreateConfigSource(context.Background(), tt.config, zap.NewNop())
 			if tt.wantErr != nil {
 				require.Error(t, err)
+				require.IsType(t, tt.wantErr, err)
 			} else {
 				require.NoError(t, err)
 			}
@@ -0,0 +1,72 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vaultconfigsource
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/vault/api"
+)
+
+const defaultServiceAccountTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token" // #nosec G101 -- this is a file path
+
+type KubernetesAuthentication struct {
+	// Role is the name of the Vault role to request a token against. It is required.
+	Role *string `mapstructure:"role"`
+	// Mount is the path where the Kubernetes auth method is mounted. The default value is "kubernetes".
+	Mount *string `mapstructure:"mount"`
+	// TokenPath is the path of the service account JWT file. The default value is
+	// "/var/run/secrets/kubernetes.io/serviceaccount/token".
+	TokenPath *string `mapstructure:"token_path"`
+}
+
+func (k *KubernetesAuthentication) Token(client *api.Client) (string, error) {
+	mount := "kubernetes"
+	if k.Mount != nil {
+		mount = *k.Mount
+	}
+
+	role := ""
+	if k.Role != nil {
+		role = *k.Role
+	}
+
+	tokenPath := defaultServiceAccountTokenPath
+	if k.TokenPath != nil {
+		tokenPath = *k.TokenPath
+	}
+
+	// The JWT is read on every login since projected service account tokens are rotated by the kubelet.
+	jwt, err := os.ReadFile(tokenPath)
+	if err != nil {
+		return "", fmt.Errorf("failed reading service account token: %w", err)
+	}
+
+	secret, err := client.Logical().Write(fmt.Sprintf("auth/%s/login", mount), map[string]any{
+		"role": role,
+		"jwt":  strings.TrimSpace(string(jwt)),
+	})
+	if err != nil {
+		return "", err
+	}
+	if secret == nil || secret.Auth == nil {
+		return "", errors.New("empty response from credential provider")
+	}
+
+	return secret.Auth.ClientToken, nil
+}
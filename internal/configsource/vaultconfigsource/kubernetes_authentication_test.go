@@ -0,0 +1,65 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vaultconfigsource
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKubernetesAuthenticationToken(t *testing.T) {
+	server := newFakeVaultServer(t)
+	client, err := api.NewClient(&api.Config{Address: server.URL})
+	require.NoError(t, err)
+
+	tokenPath := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenPath, []byte("service.account.jwt\n"), 0o600))
+	role := "collector"
+	mount := "custom-kubernetes"
+
+	authentication := KubernetesAuthentication{Role: &role, Mount: &mount, TokenPath: &tokenPath}
+	token, err := authentication.Token(client)
+	require.NoError(t, err)
+	require.Equal(t, "token-1", token)
+
+	// The JWT is read again on each login to use rotated tokens.
+	require.NoError(t, os.WriteFile(tokenPath, []byte("rotated.service.account.jwt"), 0o600))
+	token, err = authentication.Token(client)
+	require.NoError(t, err)
+	require.Equal(t, "token-2", token)
+
+	logins := server.getLogins()
+	require.Len(t, logins, 2)
+	assert.Equal(t, "/v1/auth/custom-kubernetes/login", logins[0].path)
+	assert.Equal(t, map[string]any{"role": "collector", "jwt": "service.account.jwt"}, logins[0].data)
+	assert.Equal(t, map[string]any{"role": "collector", "jwt": "rotated.service.account.jwt"}, logins[1].data)
+}
+
+func TestKubernetesAuthenticationTokenMissingJWT(t *testing.T) {
+	server := newFakeVaultServer(t)
+	client, err := api.NewClient(&api.Config{Address: server.URL})
+	require.NoError(t, err)
+
+	role := "collector"
+	tokenPath := filepath.Join(t.TempDir(), "missing")
+	_, err = (&KubernetesAuthentication{Role: &role, TokenPath: &tokenPath}).Token(client)
+	require.ErrorContains(t, err, "failed reading service account token")
+	require.Empty(t, server.getLogins())
+}
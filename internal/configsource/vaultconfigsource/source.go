@@ -20,7 +20,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/hashicorp/vault/api"
@@ -45,10 +47,14 @@ type vaultConfigSource struct {
 	logger *zap.Logger
 	client *api.Client
 	secret *api.Secret
+	auth   Authentication
 
 	path string
 
 	pollInterval time.Duration
+
+	// loginMutex serializes re-logins from Retrieve and the watchers.
+	loginMutex sync.Mutex
 }
 
 func newConfigSource(cfg *Config, logger *zap.Logger) (configsource.ConfigSource, error) {
@@ -75,6 +81,7 @@ func newConfigSource(cfg *Config, logger *zap.Logger) (configsource.ConfigSource
 	return &vaultConfigSource{
 		logger:       logger,
 		client:       client,
+		auth:         *cfg.Authentication,
 		path:         cfg.Path,
 		pollInterval: cfg.PollInterval,
 	}, nil
@@ -126,7 +133,7 @@ func (v *vaultConfigSource) Retrieve(_ context.Context, selector string, _ *conf
 // readSecret reads the secret from the vaultConfigSource path and if successful
 // it stores the secret on the vaultConfigSource secret field.
 func (v *vaultConfigSource) readSecret() error {
-	secret, err := v.client.Logical().Read(v.path)
+	secret, err := v.read(v.path)
 	if err != nil {
 		return &errClientRead{err}
 	}
@@ -145,6 +152,50 @@ func (v *vaultConfigSource) readSecret() error {
 	return nil
 }
 
+// read reads the given Vault path. If the request is denied and the config source uses
+// a login auth method, the client token is assumed to have expired: it logs in again
+// and retries the read once.
+func (v *vaultConfigSource) read(path string) (*api.Secret, error) {
+	token := v.client.Token()
+	secret, err := v.client.Logical().Read(path)
+	if err == nil || v.auth.Token != nil || !isPermissionDenied(err) {
+		return secret, err
+	}
+
+	if reloginErr := v.relogin(token); reloginErr != nil {
+		return nil, fmt.Errorf("%w (failed to log in again: %w)", err, reloginErr)
+	}
+	return v.client.Logical().Read(path)
+}
+
+// relogin obtains a new client token unless the expired one was already replaced
+// by a concurrent relogin.
+func (v *vaultConfigSource) relogin(expiredToken string) error {
+	v.loginMutex.Lock()
+	defer v.loginMutex.Unlock()
+	if v.client.Token() != expiredToken {
+		return nil
+	}
+
+	v.logger.Info("Vault request was denied, logging in again", zap.String("path", v.path))
+	// Clone doesn't copy the expired token so it isn't sent with the login request.
+	loginClient, err := v.client.Clone()
+	if err != nil {
+		return err
+	}
+	token, err := getClientToken(loginClient, v.auth)
+	if err != nil {
+		return err
+	}
+	v.client.SetToken(token)
+	return nil
+}
+
+func isPermissionDenied(err error) bool {
+	var respErr *api.ResponseError
+	return errors.As(err, &respErr) && respErr.StatusCode == http.StatusForbidden
+}
+
 func (v *vaultConfigSource) buildWatcherFn(watcher confmap.WatcherFunc, doneCh chan struct{}) error {
 	switch {
 	case v.secret.Renewable:
@@ -248,7 +299,7 @@ func (v *vaultConfigSource) buildPollingWatcher(watcher confmap.WatcherFunc, don
 		for {
 			select {
 			case <-ticker.C:
-				metadataSecret, err := v.client.Logical().Read(metadataPath)
+				metadataSecret, err := v.read(metadataPath)
 				if err != nil {
 					// Docs are not clear about how to differentiate between temporary and permanent errors.
 					// Assume that the configuration needs to be re-fetched.
@@ -345,6 +396,10 @@ func getClientToken(client *api.Client, auth Authentication) (string, error) {
 		return auth.IAMAuthentication.Token(client)
 	case auth.GCPAuthentication != nil:
 		return auth.GCPAuthentication.Token(client)
+	case auth.KubernetesAuthentication != nil:
+		return auth.KubernetesAuthentication.Token(client)
+	case auth.AppRoleAuthentication != nil:
+		return auth.AppRoleAuthentication.Token(client)
 	}
 	return "", &errEmptyAuth{errors.New("auth cannot be empty, exactly one method must be used")}
 }
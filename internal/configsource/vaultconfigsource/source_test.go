@@ -20,9 +20,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -439,6 +443,111 @@ func Test_vaultSession_extractVersionMetadata(t *testing.T) {
 	}
 }
 
+func TestVaultReloginOnDeniedRequest(t *testing.T) {
+	server := newFakeVaultServer(t)
+	roleIDFile := filepath.Join(t.TempDir(), "role_id")
+	require.NoError(t, os.WriteFile(roleIDFile, []byte("a-role-id\n"), 0o600))
+
+	config := Config{
+		Endpoint: server.URL,
+		Authentication: &Authentication{
+			AppRoleAuthentication: &AppRoleAuthentication{RoleIDFile: &roleIDFile},
+		},
+		Path:         "secret/data/kv",
+		PollInterval: 2 * time.Second,
+	}
+	source, err := newConfigSource(&config, zap.NewNop())
+	require.NoError(t, err)
+	require.Len(t, server.getLogins(), 1)
+
+	server.expireToken()
+	retrieved, err := source.Retrieve(context.Background(), "data.k0", nil, nil)
+	require.NoError(t, err)
+	val, err := retrieved.AsRaw()
+	require.NoError(t, err)
+	require.Equal(t, "v0", val)
+
+	logins := server.getLogins()
+	require.Len(t, logins, 2)
+	assert.Equal(t, "/v1/auth/approle/login", logins[1].path)
+	assert.Equal(t, map[string]any{"role_id": "a-role-id"}, logins[1].data)
+	assert.Empty(t, logins[1].token, "the expired token must not be sent with the login request")
+
+	// Requests denied with a static token aren't retried.
+	staticToken := "not_a_valid_token"
+	config.Authentication = &Authentication{Token: &staticToken}
+	source, err = newConfigSource(&config, zap.NewNop())
+	require.NoError(t, err)
+	_, err = source.Retrieve(context.Background(), "data.k0", nil, nil)
+	require.ErrorAs(t, err, new(*errClientRead))
+	require.Len(t, server.getLogins(), 2)
+}
+
+type fakeVaultLogin struct {
+	data  map[string]any
+	path  string
+	token string
+}
+
+// fakeVaultServer issues a new client token on every auth method login and only
+// serves the "secret/data/kv" secret to requests using the latest token.
+type fakeVaultServer struct {
+	*httptest.Server
+	validToken string
+	logins     []fakeVaultLogin
+	mutex      sync.Mutex
+}
+
+func newFakeVaultServer(t *testing.T) *fakeVaultServer {
+	t.Helper()
+	f := &fakeVaultServer{}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.handle))
+	t.Cleanup(f.Close)
+	return f
+}
+
+func (f *fakeVaultServer) handle(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+
+	if strings.HasPrefix(r.URL.Path, "/v1/auth/") && strings.HasSuffix(r.URL.Path, "/login") {
+		login := fakeVaultLogin{path: r.URL.Path, token: r.Header.Get("X-Vault-Token")}
+		if err := json.NewDecoder(r.Body).Decode(&login.data); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.logins = append(f.logins, login)
+		f.validToken = fmt.Sprintf("token-%d", len(f.logins))
+		_, _ = fmt.Fprintf(w, `{"auth":{"client_token":%q,"lease_duration":60,"renewable":true}}`, f.validToken)
+		return
+	}
+
+	if f.validToken == "" || r.Header.Get("X-Vault-Token") != f.validToken {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+		return
+	}
+	if r.URL.Path != "/v1/secret/data/kv" {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"errors":[]}`))
+		return
+	}
+	_, _ = w.Write([]byte(`{"data":{"data":{"k0":"v0"},"metadata":{"version":1}}}`))
+}
+
+func (f *fakeVaultServer) expireToken() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.validToken = ""
+}
+
+func (f *fakeVaultServer) getLogins() []fakeVaultLogin {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return append([]fakeVaultLogin(nil), f.logins...)
+}
+
 func requireCmdRun(t *testing.T, cli string) {
 	skipCheck(t)
 	parts := strings.Split(cli, " ")
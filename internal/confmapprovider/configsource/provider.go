@@ -17,22 +17,33 @@ package configsource
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"os"
 	"sync"
 
 	"go.opentelemetry.io/collector/confmap"
 	"go.uber.org/zap"
 
 	"github.com/signalfx/splunk-otel-collector/internal/configsource"
+	"github.com/signalfx/splunk-otel-collector/internal/configsource/consulconfigsource"
 	"github.com/signalfx/splunk-otel-collector/internal/configsource/envvarconfigsource"
 	"github.com/signalfx/splunk-otel-collector/internal/configsource/etcd2configsource"
 	"github.com/signalfx/splunk-otel-collector/internal/configsource/includeconfigsource"
+	"github.com/signalfx/splunk-otel-collector/internal/configsource/secretfileconfigsource"
 	"github.com/signalfx/splunk-otel-collector/internal/configsource/splunksecretconfigsource"
 	"github.com/signalfx/splunk-otel-collector/internal/configsource/vaultconfigsource"
 	"github.com/signalfx/splunk-otel-collector/internal/configsource/zookeeperconfigsource"
 )
 
+const (
+	// CacheDirEnvVar enables the last-known-good config source cache, stored in the specified directory.
+	CacheDirEnvVar = "SPLUNK_CONFIG_SOURCES_CACHE_DIR"
+	// CacheKeyEnvVar is the base64-encoded 16, 24, or 32 byte AES key used to encrypt the cache.
+	CacheKeyEnvVar = "SPLUNK_CONFIG_SOURCES_CACHE_KEY" //nolint:gosec // the name of the env var holding the key, not a key
+)
+
 var configSourceFactories = func() configsource.Factories {
 	factories := make(configsource.Factories)
 	for _, f := range []configsource.Factory{
@@ -42,6 +53,8 @@ var configSourceFactories = func() configsource.Factories {
 		zookeeperconfigsource.NewFactory(),
 		etcd2configsource.NewFactory(),
 		splunksecretconfigsource.NewFactory(),
+		secretfileconfigsource.NewFactory(),
+		consulconfigsource.NewFactory(),
 	} {
 		if _, ok := factories[f.Type()]; ok {
 			panic(fmt.Sprintf("duplicate config source factory %q", f.Type()))
@@ -59,6 +72,27 @@ type Hook interface {
 	OnShutdown()
 }
 
+// URIHook can be implemented by a Hook to also be notified of the full URI of each
+// retrieved config, e.g. to report which file a value was set in.
+type URIHook interface {
+	OnRetrieveURI(uri string, retrieved map[string]any)
+}
+
+// ReloadObserver can be implemented by a Hook to be notified of config source watcher
+// events once the configuration they were retrieved for has been resolved again.
+type ReloadObserver interface {
+	OnReload(event ReloadEvent)
+}
+
+// ReloadEvent describes the redacted changes to a resolved configuration caused by
+// config source updates. Suppressed events are those without any change, for which
+// no reload is triggered.
+type ReloadEvent struct {
+	URI        string
+	Changes    []configsource.ConfigChange
+	Suppressed bool
+}
+
 // ProviderWrapper is the entrypoint for existing confmap.Providers to be provided with
 // configsource.ConfigSource retrieval functionality. Once Wrap()'ed, their
 // Retrieve() method as invoked by the service's confmap.Resolver will be
@@ -70,6 +104,7 @@ type ProviderWrapper struct {
 	providersLock     *sync.Mutex
 	logger            *zap.Logger
 	factories         configsource.Factories
+	cache             *configsource.Cache
 	hooks             []Hook
 }
 
@@ -83,6 +118,37 @@ func New(logger *zap.Logger, hooks []Hook) *ProviderWrapper {
 	}
 }
 
+// EnableCacheFromEnv enables the opt-in last-known-good config source cache when
+// SPLUNK_CONFIG_SOURCES_CACHE_DIR is set. Hooks implementing configsource.CacheObserver
+// are notified of its use and report it, e.g. the TelemetryHook with the service logger.
+func (pw *ProviderWrapper) EnableCacheFromEnv() error {
+	dir, ok := os.LookupEnv(CacheDirEnvVar)
+	if !ok || dir == "" {
+		return nil
+	}
+	encodedKey, ok := os.LookupEnv(CacheKeyEnvVar)
+	if !ok || encodedKey == "" {
+		return fmt.Errorf("%s must be set when %s is set", CacheKeyEnvVar, CacheDirEnvVar)
+	}
+	key, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", CacheKeyEnvVar, err)
+	}
+
+	var observers []configsource.CacheObserver
+	for _, h := range pw.hooks {
+		if o, isObserver := h.(configsource.CacheObserver); isObserver {
+			observers = append(observers, o)
+		}
+	}
+	cache, err := configsource.NewCache(dir, key, observers...)
+	if err != nil {
+		return err
+	}
+	pw.cache = cache
+	return nil
+}
+
 var (
 	_ confmap.Provider        = (*wrappedProvider)(nil)
 	_ confmap.ProviderFactory = (*wrappedProviderFactory)(nil)
@@ -165,8 +231,51 @@ func (pw *ProviderWrapper) ResolveForWrapped(ctx context.Context, uri string, on
 	scheme, stringMap := w.provider.Scheme(), conf.ToStringMap()
 	for _, h := range pw.hooks {
 		h.OnRetrieve(scheme, stringMap)
+		if uh, ok := h.(URIHook); ok {
+			uh.OnRetrieveURI(uri, stringMap)
+		}
+	}
+
+	resolution := &watchedResolution{
+		wrapper:  pw,
+		conf:     conf,
+		uri:      uri,
+		onChange: onChange,
+	}
+	// watcher events are handled once the initial resolution is recorded
+	resolution.mutex.Lock()
+	resolved, closeFunc, err := resolution.resolve(ctx)
+	if err != nil {
+		resolution.mutex.Unlock()
+		return nil, err
 	}
+	resolution.resolved, resolution.closeFunc = resolved.ToStringMap(), closeFunc
+	resolution.mutex.Unlock()
+
+	return confmap.NewRetrieved(
+		resolved.ToStringMap(), confmap.WithRetrievedClose(
+			configsource.MergeCloseFuncs([]confmap.CloseFunc{resolution.close, retrieved.Close}),
+		),
+	)
+}
+
+// watchedResolution is the config sources resolution of a retrieved configuration. Config
+// source watcher events cause it to be resolved again so that the changes can be reported
+// and events that don't affect the resolved configuration don't trigger a reload.
+type watchedResolution struct {
+	wrapper    *ProviderWrapper
+	conf       *confmap.Conf
+	onChange   confmap.WatcherFunc
+	closeFunc  confmap.CloseFunc
+	resolved   map[string]any
+	unresolved *confmap.Conf
+	uri        string
+	mutex      sync.Mutex
+	closed     bool
+}
 
+func (r *watchedResolution) resolve(ctx context.Context) (*confmap.Conf, confmap.CloseFunc, error) {
+	pw := r.wrapper
 	// copy providers map for downstream resolution
 	pw.providersLock.Lock()
 	providers := map[string]confmap.Provider{}
@@ -174,19 +283,90 @@ func (pw *ProviderWrapper) ResolveForWrapped(ctx context.Context, uri string, on
 		providers[s] = p
 	}
 	pw.providersLock.Unlock()
-	configSources, confToResolve, err := configsource.BuildConfigSourcesFromConf(ctx, conf, pw.logger, pw.factories, providers)
+	configSources, confToResolve, err := configsource.BuildConfigSourcesFromConf(ctx, r.conf, pw.logger, pw.factories, providers)
 	if err != nil {
-		return nil, fmt.Errorf("failed resolving latestConf: %w", err)
+		return nil, nil, fmt.Errorf("failed resolving latestConf: %w", err)
 	}
+	configSources = pw.cache.Wrap(configSources)
+	r.unresolved = confToResolve
 
-	resolved, closeFunc, err := configsource.ResolveWithConfigSources(ctx, configSources, nil, confToResolve, onChange)
+	var watcher confmap.WatcherFunc
+	if r.onChange != nil {
+		watcher = r.onWatcherEvent
+	}
+	resolved, closeFunc, err := configsource.ResolveWithConfigSources(ctx, configSources, nil, confToResolve, watcher)
 	if err != nil {
-		return nil, fmt.Errorf("failed resolving with config sources: %w", err)
+		return nil, nil, fmt.Errorf("failed resolving with config sources: %w", err)
 	}
+	return resolved, closeFunc, nil
+}
 
-	return confmap.NewRetrieved(
-		resolved.ToStringMap(), confmap.WithRetrievedClose(
-			configsource.MergeCloseFuncs([]confmap.CloseFunc{closeFunc, retrieved.Close}),
-		),
-	)
+func (r *watchedResolution) onWatcherEvent(event *confmap.ChangeEvent) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.closed {
+		return
+	}
+	logger := r.wrapper.logger.With(zap.String("uri", r.uri))
+	if event.Error != nil {
+		r.onChange(event)
+		return
+	}
+
+	resolved, closeFunc, err := r.resolve(context.Background())
+	if err != nil {
+		logger.Warn("failed resolving the configuration after a config source change, reloading", zap.Error(err))
+		r.onChange(event)
+		return
+	}
+
+	current := resolved.ToStringMap()
+	reloadEvent := ReloadEvent{
+		URI:     r.uri,
+		Changes: configsource.DiffResolved(r.unresolved, r.resolved, current),
+	}
+	if len(reloadEvent.Changes) == 0 {
+		reloadEvent.Suppressed = true
+		r.notify(reloadEvent)
+		// Keep watching with the new resolution. The previous one is closed asynchronously
+		// since this is called by one of its watchers.
+		previous := r.closeFunc
+		r.resolved, r.closeFunc = current, closeFunc
+		if previous != nil {
+			go func() {
+				if cErr := previous(context.Background()); cErr != nil {
+					logger.Warn("failed closing the previous config sources resolution", zap.Error(cErr))
+				}
+			}()
+		}
+		return
+	}
+
+	r.notify(reloadEvent)
+	// The new resolution is only used for the diff since the reload retrieves the configuration again.
+	if closeFunc != nil {
+		if cErr := closeFunc(context.Background()); cErr != nil {
+			logger.Warn("failed closing the config sources resolution", zap.Error(cErr))
+		}
+	}
+	r.onChange(event)
+}
+
+func (r *watchedResolution) notify(event ReloadEvent) {
+	for _, h := range r.wrapper.hooks {
+		if o, ok := h.(ReloadObserver); ok {
+			o.OnReload(event)
+		}
+	}
+}
+
+func (r *watchedResolution) close(ctx context.Context) error {
+	r.mutex.Lock()
+	r.closed = true
+	closeFunc := r.closeFunc
+	r.mutex.Unlock()
+	if closeFunc == nil {
+		return nil
+	}
+	return closeFunc(ctx)
 }
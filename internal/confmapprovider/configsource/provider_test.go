@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"path"
 	"testing"
+	"time"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/confmap/provider/googlesecretmanagerprovider"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/confmap/provider/secretsmanagerprovider"
@@ -347,3 +348,83 @@ func startWatch(watchForUpdateCh chan error, doneCh chan struct{}, watcher confm
 		}
 	}()
 }
+
+func TestEnableCacheFromEnv(t *testing.T) {
+	hook := NewTelemetryHook()
+	pw := New(zap.NewNop(), []Hook{hook})
+	require.NoError(t, pw.EnableCacheFromEnv())
+	assert.Nil(t, pw.cache)
+
+	t.Setenv(CacheDirEnvVar, t.TempDir())
+	require.EqualError(t, pw.EnableCacheFromEnv(), "SPLUNK_CONFIG_SOURCES_CACHE_KEY must be set when SPLUNK_CONFIG_SOURCES_CACHE_DIR is set")
+
+	t.Setenv(CacheKeyEnvVar, "c2hvcnQ=")
+	require.EqualError(t, pw.EnableCacheFromEnv(), "invalid cache key: crypto/aes: invalid key size 5")
+
+	t.Setenv(CacheKeyEnvVar, "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=")
+	require.NoError(t, pw.EnableCacheFromEnv())
+	require.NotNil(t, pw.cache)
+}
+
+type watchedCfgSrcFactory struct {
+	MockCfgSrcFactory
+	source *TestConfigSource
+}
+
+func (w *watchedCfgSrcFactory) CreateConfigSource(context.Context, configsource.Settings, *zap.Logger) (configsource.ConfigSource, error) {
+	return w.source, nil
+}
+
+func TestReloadSuppressedWithoutResolvedChanges(t *testing.T) {
+	updates := make(chan error)
+	source := &TestConfigSource{
+		ValueMap: map[string]valueEntry{
+			"key": {Value: "a", WatchForUpdateCh: updates},
+		},
+	}
+	hook := NewTelemetryHook()
+	pw := New(zap.NewNop(), []Hook{hook})
+	pw.factories = configsource.Factories{
+		component.MustNewType("tstcfgsrc"): &watchedCfgSrcFactory{source: source},
+	}
+	provider := pw.Wrap(fileprovider.NewFactory()).Create(confmap.ProviderSettings{})
+
+	events := make(chan *confmap.ChangeEvent, 1)
+	retrieved, err := provider.Retrieve(context.Background(), "file:"+path.Join("testdata", "watched_config.yaml"), func(event *confmap.ChangeEvent) {
+		events <- event
+	})
+	require.NoError(t, err)
+	raw, err := retrieved.AsRaw()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"key": "a", "literal": "value"}, raw)
+
+	// the watcher fires without the value being changed
+	updates <- nil
+	require.Eventually(t, func() bool {
+		return len(hook.GetReloadEvents()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, ReloadEvent{URI: "file:" + path.Join("testdata", "watched_config.yaml"), Suppressed: true}, hook.GetReloadEvents()[0])
+	select {
+	case <-events:
+		t.Fatal("unexpected reload without resolved config changes")
+	default:
+	}
+
+	// the new resolution keeps watching for changes
+	source.ValueMap["key"] = valueEntry{Value: "b", WatchForUpdateCh: updates}
+	updates <- nil
+	select {
+	case event := <-events:
+		require.NoError(t, event.Error)
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected reload with resolved config changes")
+	}
+	reloadEvents := hook.GetReloadEvents()
+	require.Len(t, reloadEvents, 2)
+	assert.Equal(t, []configsource.ConfigChange{
+		{Path: "key", Kind: configsource.ConfigChangeChanged, Old: configsource.RedactedValue, New: configsource.RedactedValue},
+	}, reloadEvents[1].Changes)
+	assert.False(t, reloadEvents[1].Suppressed)
+
+	require.NoError(t, retrieved.Close(context.Background()))
+}
@@ -24,25 +24,46 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
 	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configsource"
 )
 
 var (
-	_ Hook = (*TelemetryHook)(nil)
+	_ Hook                       = (*TelemetryHook)(nil)
+	_ configsource.CacheObserver = (*TelemetryHook)(nil)
+	_ ReloadObserver             = (*TelemetryHook)(nil)
 
 	// globalHook instance needed by the extension
 	globalHook *TelemetryHook
 )
 
 const (
-	meterName                    = "github.com/signalfx/splunk-otel-collector/internal/confmapprovider/configsource"
-	configSourceUsageMetricName  = "otelcol_splunk_config_source_usage"
-	configSourceTypeAttributeKey = "config_source_type"
+	meterName                         = "github.com/signalfx/splunk-otel-collector/internal/confmapprovider/configsource"
+	configSourceUsageMetricName       = "otelcol_splunk_config_source_usage"
+	configSourceCacheHitsMetricName   = "otelcol_splunk_config_source_cache_hits"
+	configSourceCacheMissesMetricName = "otelcol_splunk_config_source_cache_misses"
+	configSourceReloadsMetricName     = "otelcol_splunk_config_source_reloads"
+	configSourceTypeAttributeKey      = "config_source_type"
+	reloadOutcomeAttributeKey         = "outcome"
+
+	// maxReloadEvents is the number of most recent ReloadEvents kept by the TelemetryHook.
+	maxReloadEvents = 10
+	// maxPendingLogs is the number of most recent log entries kept by the TelemetryHook
+	// until the service logger is injected.
+	maxPendingLogs = 100
 )
 
 type TelemetryHook struct {
 	usedSources       map[string]bool
+	cacheHits         map[string]int64
+	cacheMisses       map[string]int64
+	reloads           map[string]int64
 	telemetrySettings *component.TelemetrySettings
-	mutex             sync.RWMutex
+	reloadEvents      []ReloadEvent
+	// pendingLogs are the log entries made before the service logger is injected, e.g. the
+	// cache ones while resolving the configuration at startup.
+	pendingLogs []func(*zap.Logger)
+	mutex       sync.RWMutex
 }
 
 // logger returns the logger from TelemetrySettings once injected, or a no-op logger before that.
@@ -58,6 +79,9 @@ func (t *TelemetryHook) logger() *zap.Logger {
 func NewTelemetryHook() *TelemetryHook {
 	hook := &TelemetryHook{
 		usedSources: make(map[string]bool),
+		cacheHits:   make(map[string]int64),
+		cacheMisses: make(map[string]int64),
+		reloads:     make(map[string]int64),
 	}
 	SetGlobalHook(hook)
 	return hook
@@ -93,17 +117,72 @@ func (t *TelemetryHook) registerMetrics() error {
 		return err
 	}
 
+	_, err = meter.Int64ObservableCounter(
+		configSourceCacheHitsMetricName,
+		metric.WithDescription("Number of failed config source retrievals that used a last-known-good cached value"),
+		metric.WithUnit("{hit}"),
+		metric.WithInt64Callback(t.observeCounts(configSourceTypeAttributeKey, t.cacheHits)),
+	)
+	if err != nil {
+		t.logger().Error("Failed to register config source cache hits metric", zap.Error(err))
+		return err
+	}
+
+	_, err = meter.Int64ObservableCounter(
+		configSourceCacheMissesMetricName,
+		metric.WithDescription("Number of failed config source retrievals without a last-known-good cached value"),
+		metric.WithUnit("{miss}"),
+		metric.WithInt64Callback(t.observeCounts(configSourceTypeAttributeKey, t.cacheMisses)),
+	)
+	if err != nil {
+		t.logger().Error("Failed to register config source cache misses metric", zap.Error(err))
+		return err
+	}
+
+	_, err = meter.Int64ObservableCounter(
+		configSourceReloadsMetricName,
+		metric.WithDescription("Number of config source changes by outcome, either applied with a reload or suppressed when the resolved config is unchanged"),
+		metric.WithUnit("{reload}"),
+		metric.WithInt64Callback(t.observeCounts(reloadOutcomeAttributeKey, t.reloads)),
+	)
+	if err != nil {
+		t.logger().Error("Failed to register config source reloads metric", zap.Error(err))
+		return err
+	}
+
 	t.logger().Info("Config source telemetry metrics registered successfully")
 	return nil
 }
 
+// logWithServiceLogger logs with the service logger, or once it is injected if it isn't yet.
+// It must be called with the mutex locked.
+func (t *TelemetryHook) logWithServiceLogger(log func(*zap.Logger)) {
+	if t.telemetrySettings != nil && t.telemetrySettings.Logger != nil {
+		log(t.telemetrySettings.Logger)
+		return
+	}
+	t.pendingLogs = append(t.pendingLogs, log)
+	if len(t.pendingLogs) > maxPendingLogs {
+		t.pendingLogs = t.pendingLogs[len(t.pendingLogs)-maxPendingLogs:]
+	}
+}
+
 // SetTelemetrySettings injects the service component.TelemetrySettings into the hook.
-// Called by the configsourcetelemetryextension on Start().
+// Called by the configsourcetelemetryextension on Start(). The log entries made before
+// are logged with the service logger.
 func (t *TelemetryHook) SetTelemetrySettings(settings component.TelemetrySettings) {
 	t.mutex.Lock()
 	t.telemetrySettings = &settings
+	var pendingLogs []func(*zap.Logger)
+	if settings.Logger != nil {
+		pendingLogs, t.pendingLogs = t.pendingLogs, nil
+	}
 	t.mutex.Unlock()
 
+	for _, log := range pendingLogs {
+		log(settings.Logger)
+	}
+
 	if err := t.registerMetrics(); err != nil {
 		t.logger().Warn("Failed to register config source telemetry metrics", zap.Error(err))
 	}
@@ -121,11 +200,7 @@ func (t *TelemetryHook) OnRetrieve(scheme string, retrieved map[string]any) {
 		t.logger().Debug("TelemetryHook: config_sources found in retrieved config", zap.String("scheme", scheme))
 
 		for key := range configSourcesMap {
-			// Config source keys follow the component-ID format "type" or "type/name".
-			csType := key
-			if idx := strings.IndexByte(key, '/'); idx >= 0 {
-				csType = key[:idx]
-			}
+			csType := configSourceType(key)
 
 			if isCustomConfigSource(csType) {
 				t.logger().Info("TelemetryHook: custom config source detected",
@@ -161,10 +236,101 @@ func (t *TelemetryHook) observeConfigSourceUsage(_ context.Context, observer met
 	return nil
 }
 
+// OnCacheHit is called when a last-known-good cached value is used for a failed config source retrieval.
+func (t *TelemetryHook) OnCacheHit(cfgSrcName string, retrieveErr error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.cacheHits[configSourceType(cfgSrcName)]++
+	t.logWithServiceLogger(func(logger *zap.Logger) {
+		logger.Warn("config source failed to retrieve value, using last-known-good cached value",
+			zap.String("config_source", cfgSrcName), zap.Error(retrieveErr))
+	})
+}
+
+// OnCacheMiss is called when no last-known-good cached value exists for a failed config source retrieval.
+func (t *TelemetryHook) OnCacheMiss(cfgSrcName string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.cacheMisses[configSourceType(cfgSrcName)]++
+}
+
+// OnCacheError is called when a config source value can't be stored in or loaded from the cache.
+func (t *TelemetryHook) OnCacheError(cfgSrcName string, err error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.logWithServiceLogger(func(logger *zap.Logger) {
+		logger.Warn("config source cache failure", zap.String("config_source", cfgSrcName), zap.Error(err))
+	})
+}
+
+// OnReload is called when a config source change has been evaluated against the resolved config.
+// It counts the reloads by outcome, keeps the most recent events, and logs their redacted changes
+// with the service logger.
+func (t *TelemetryHook) OnReload(event ReloadEvent) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	outcome := "applied"
+	if event.Suppressed {
+		outcome = "suppressed"
+	}
+	t.reloads[outcome]++
+	t.reloadEvents = append(t.reloadEvents, event)
+	if len(t.reloadEvents) > maxReloadEvents {
+		t.reloadEvents = t.reloadEvents[len(t.reloadEvents)-maxReloadEvents:]
+	}
+	if event.Suppressed {
+		t.logger().Info("config source change didn't affect the resolved configuration, skipping reload",
+			zap.String("uri", event.URI))
+		return
+	}
+	changes := make([]string, 0, len(event.Changes))
+	for _, change := range event.Changes {
+		changes = append(changes, change.String())
+	}
+	t.logger().Info("config source change detected, reloading",
+		zap.String("uri", event.URI),
+		zap.Strings("changes", changes))
+}
+
+// GetReloadEvents returns a copy of the most recent ReloadEvents, oldest first.
+func (t *TelemetryHook) GetReloadEvents() []ReloadEvent {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	return append([]ReloadEvent(nil), t.reloadEvents...)
+}
+
+// observeCounts returns the callback function for an observable counter of the provided counts,
+// reported with their key as the value of the provided attribute.
+func (t *TelemetryHook) observeCounts(attributeKey string, counts map[string]int64) metric.Int64Callback {
+	return func(_ context.Context, observer metric.Int64Observer) error {
+		t.mutex.RLock()
+		defer t.mutex.RUnlock()
+
+		for key, count := range counts {
+			observer.Observe(count, metric.WithAttributes(
+				attribute.String(attributeKey, key),
+			))
+		}
+
+		return nil
+	}
+}
+
+// configSourceType returns the type of a config source key in the component-ID format "type" or "type/name".
+func configSourceType(key string) string {
+	if idx := strings.IndexByte(key, '/'); idx >= 0 {
+		return key[:idx]
+	}
+	return key
+}
+
 var customConfigSources = map[string]struct{}{
+	"consul":        {},
 	"env":           {},
 	"etcd2":         {},
 	"include":       {},
+	"secretfile":    {},
 	"splunk_secret": {},
 	"vault":         {},
 	"zookeeper":     {},
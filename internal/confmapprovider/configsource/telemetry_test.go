@@ -16,6 +16,7 @@
 package configsource
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -23,6 +24,11 @@ import (
 	"go.opentelemetry.io/collector/component"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configsource"
 )
 
 func TestNewTelemetryHook(t *testing.T) {
@@ -282,3 +288,121 @@ func TestTelemetryHook_LazyRegistration(t *testing.T) {
 	require.NoError(t, reader.Collect(t.Context(), &rm))
 	require.NotEmpty(t, rm.ScopeMetrics, "metric should be present after TelemetrySettings is injected and a config source is tracked")
 }
+
+func TestTelemetryHook_CacheMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	defer func() {
+		require.NoError(t, provider.Shutdown(t.Context()))
+	}()
+
+	hook := NewTelemetryHook()
+	hook.SetTelemetrySettings(component.TelemetrySettings{MeterProvider: provider})
+
+	retrieveErr := errors.New("connection refused")
+	hook.OnCacheHit("vault/prod", retrieveErr)
+	hook.OnCacheHit("vault/staging", retrieveErr)
+	hook.OnCacheHit("zookeeper", retrieveErr)
+	hook.OnCacheMiss("etcd2")
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(t.Context(), &rm))
+
+	reported := map[string]map[string]int64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != configSourceCacheHitsMetricName && m.Name != configSourceCacheMissesMetricName {
+				continue
+			}
+			sum, ok := m.Data.(metricdata.Sum[int64])
+			require.True(t, ok)
+			assert.True(t, sum.IsMonotonic)
+			reported[m.Name] = map[string]int64{}
+			for _, dp := range sum.DataPoints {
+				csType, _ := dp.Attributes.Value(configSourceTypeAttributeKey)
+				reported[m.Name][csType.AsString()] = dp.Value
+			}
+		}
+	}
+	assert.Equal(t, map[string]map[string]int64{
+		configSourceCacheHitsMetricName:   {"vault": 2, "zookeeper": 1},
+		configSourceCacheMissesMetricName: {"etcd2": 1},
+	}, reported)
+}
+
+func TestTelemetryHook_CacheLogs(t *testing.T) {
+	hook := NewTelemetryHook()
+	// The cache is used while resolving the configuration, before the service logger is injected.
+	hook.OnCacheHit("vault/prod", errors.New("connection refused"))
+	hook.OnCacheError("etcd2", errors.New("failed loading cached config source value: cipher: message authentication failed"))
+
+	core, logs := observer.New(zapcore.WarnLevel)
+	hook.SetTelemetrySettings(component.TelemetrySettings{
+		MeterProvider: sdkmetric.NewMeterProvider(),
+		Logger:        zap.New(core),
+	})
+	entries := logs.TakeAll()
+	require.Len(t, entries, 2)
+	assert.Equal(t, "config source failed to retrieve value, using last-known-good cached value", entries[0].Message)
+	assert.Equal(t, map[string]any{"config_source": "vault/prod", "error": "connection refused"}, entries[0].ContextMap())
+	assert.Equal(t, "config source cache failure", entries[1].Message)
+	assert.Equal(t, "etcd2", entries[1].ContextMap()["config_source"])
+
+	hook.OnCacheHit("zookeeper", errors.New("timeout"))
+	entries = logs.TakeAll()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "zookeeper", entries[0].ContextMap()["config_source"])
+}
+
+func TestTelemetryHook_ReloadEvents(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	defer func() {
+		require.NoError(t, provider.Shutdown(t.Context()))
+	}()
+
+	core, logs := observer.New(zapcore.InfoLevel)
+	hook := NewTelemetryHook()
+	hook.SetTelemetrySettings(component.TelemetrySettings{MeterProvider: provider, Logger: zap.New(core)})
+
+	changed := ReloadEvent{
+		URI: "file:config.yaml",
+		Changes: []configsource.ConfigChange{
+			{Path: "exporters::otlp::endpoint", Kind: configsource.ConfigChangeChanged, Old: configsource.RedactedValue, New: configsource.RedactedValue},
+		},
+	}
+	hook.OnReload(changed)
+	for range maxReloadEvents {
+		hook.OnReload(ReloadEvent{URI: "file:config.yaml", Suppressed: true})
+	}
+
+	events := hook.GetReloadEvents()
+	require.Len(t, events, maxReloadEvents)
+	assert.True(t, events[0].Suppressed)
+
+	reloadLogs := logs.FilterMessage("config source change detected, reloading").All()
+	require.Len(t, reloadLogs, 1)
+	assert.Equal(t, map[string]any{
+		"uri":     "file:config.yaml",
+		"changes": []any{"~ exporters::otlp::endpoint: <redacted> -> <redacted>"},
+	}, reloadLogs[0].ContextMap())
+	assert.Equal(t, maxReloadEvents, logs.FilterMessage("config source change didn't affect the resolved configuration, skipping reload").Len())
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(t.Context(), &rm))
+	reported := map[string]int64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != configSourceReloadsMetricName {
+				continue
+			}
+			sum, ok := m.Data.(metricdata.Sum[int64])
+			require.True(t, ok)
+			for _, dp := range sum.DataPoints {
+				outcome, _ := dp.Attributes.Value(reloadOutcomeAttributeKey)
+				reported[outcome.AsString()] = dp.Value
+			}
+		}
+	}
+	assert.Equal(t, map[string]int64{"applied": 1, "suppressed": int64(maxReloadEvents)}, reported)
+}
@@ -208,6 +208,9 @@ type ReceiverToDiscoverEntry struct {
 	// Platform/observer specific config by observer extension ID.
 	// These are merged w/ "default" component.ID in a "config" map
 	Config map[component.ID]map[string]any
+	// Alternative config fragments, like credential sets, to retry the receiver with in order
+	// when it reports a partial status. Each is merged over the observer's config.
+	Candidates []map[string]any
 	// Whether to attempt to discover this receiver
 	Enabled *bool
 	// The remaining items used to merge applicable rule and config
@@ -586,7 +589,12 @@ func mergeConfigWithBundle(userCfg, bundleCfg *Config) error {
 			enabled = userRec.Enabled
 		}
 
-		receiver := ReceiverToDiscoverEntry{Enabled: enabled, Rule: bundledRec.Rule, Config: bundledRec.Config}
+		candidates := bundledRec.Candidates
+		if userRec.Candidates != nil {
+			candidates = userRec.Candidates
+		}
+
+		receiver := ReceiverToDiscoverEntry{Enabled: enabled, Rule: bundledRec.Rule, Config: bundledRec.Config, Candidates: candidates}
 		for cid, rule := range userRec.Rule {
 			receiver.Rule[cid] = rule
 		}
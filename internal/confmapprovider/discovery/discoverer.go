@@ -272,8 +272,18 @@ func (d *discoverer) continuousDiscoveryConfig(cfg *Config, discoveryReceiversCo
 // discoveryReceiversConfigs merges properties into the discovery receiver configs and returns a map of all discovery receiver configs
 // that can be created for the enabled observers.
 func (d *discoverer) discoveryReceiversConfigs(cfg *Config) (map[string]any, error) {
-	discoveryReceiversConfigs := map[string]any{}
+	observerIDs := make([]component.ID, 0, len(d.operationalObservers))
 	for observerID := range d.operationalObservers {
+		observerIDs = append(observerIDs, observerID)
+	}
+	return d.discoveryReceiversConfigsForObservers(cfg, observerIDs)
+}
+
+// discoveryReceiversConfigsForObservers returns the discovery receiver configs, keyed by discovery receiver
+// name, that watch each of the provided observers regardless of whether they have been started.
+func (d *discoverer) discoveryReceiversConfigsForObservers(cfg *Config, observerIDs []component.ID) (map[string]any, error) {
+	discoveryReceiversConfigs := map[string]any{}
+	for _, observerID := range observerIDs {
 		discoveryReceiverRaw := map[string]any{}
 		discoveryReceiverRaw["watch_observers"] = []string{observerID.String()}
 		discoveryReceiverRaw["embed_receiver_config"] = true
@@ -399,6 +409,9 @@ func (d *discoverer) updateReceiverForObserver(receiverID component.ID, receiver
 
 	receiver.Entry = make(Entry)
 	receiver.Entry["rule"] = observerRule
+	if len(receiver.Candidates) > 0 {
+		receiver.Entry["candidates"] = receiver.Candidates
+	}
 
 	var defaultConfig map[string]any
 	defaultConfig, hasDefault := receiver.Config[defaultType]
@@ -21,10 +21,18 @@ import "embed"
 //go:embed bundle.d/extensions/docker-observer.discovery.yaml
 //go:embed bundle.d/extensions/host-observer.discovery.yaml
 //go:embed bundle.d/extensions/k8s-observer.discovery.yaml
+//go:embed bundle.d/receivers/activemq.discovery.yaml
 //go:embed bundle.d/receivers/apache.discovery.yaml
+//go:embed bundle.d/receivers/cassandra.discovery.yaml
+//go:embed bundle.d/receivers/consul.discovery.yaml
+//go:embed bundle.d/receivers/couchdb.discovery.yaml
+//go:embed bundle.d/receivers/elasticsearch.discovery.yaml
 //go:embed bundle.d/receivers/envoy.discovery.yaml
+//go:embed bundle.d/receivers/etcd.discovery.yaml
+//go:embed bundle.d/receivers/haproxy.discovery.yaml
 //go:embed bundle.d/receivers/istio.discovery.yaml
 //go:embed bundle.d/receivers/kafka_metrics.discovery.yaml
+//go:embed bundle.d/receivers/memcached.discovery.yaml
 //go:embed bundle.d/receivers/mongodb.discovery.yaml
 //go:embed bundle.d/receivers/mysql.discovery.yaml
 //go:embed bundle.d/receivers/nginx.discovery.yaml
@@ -33,5 +41,7 @@ import "embed"
 //go:embed bundle.d/receivers/rabbitmq.discovery.yaml
 //go:embed bundle.d/receivers/redis.discovery.yaml
 //go:embed bundle.d/receivers/sqlserver.discovery.yaml
+//go:embed bundle.d/receivers/vault.discovery.yaml
 //go:embed bundle.d/receivers/weaviate.discovery.yaml
+//go:embed bundle.d/receivers/zookeeper.discovery.yaml
 var BundledFS embed.FS
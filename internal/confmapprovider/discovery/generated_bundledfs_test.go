@@ -0,0 +1,88 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package discovery
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/observer"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/receiver/discoveryreceiver"
+)
+
+type bundledEndpointSample struct {
+	Env      map[string]any `mapstructure:"env"`
+	Observer string         `mapstructure:"observer"`
+	Matches  bool           `mapstructure:"matches"`
+}
+
+type bundledEndpointSamples struct {
+	Receiver  string                  `mapstructure:"receiver"`
+	Endpoints []bundledEndpointSample `mapstructure:"endpoints"`
+}
+
+func TestBundledReceiverRulesMatchSampleEndpoints(t *testing.T) {
+	bundled := NewConfig(zap.NewNop())
+	require.NoError(t, bundled.LoadFS(BundledFS))
+
+	fixtures, err := filepath.Glob(filepath.Join("testdata", "bundle-endpoints", "*.yaml"))
+	require.NoError(t, err)
+	require.NotEmpty(t, fixtures)
+
+	for _, fixture := range fixtures {
+		t.Run(strings.TrimSuffix(filepath.Base(fixture), ".yaml"), func(t *testing.T) {
+			conf, err := confmaptest.LoadConf(fixture)
+			require.NoError(t, err)
+			var samples bundledEndpointSamples
+			require.NoError(t, conf.Unmarshal(&samples))
+			require.NotEmpty(t, samples.Endpoints)
+
+			var receiverID component.ID
+			require.NoError(t, receiverID.UnmarshalText([]byte(samples.Receiver)))
+			entry, ok := bundled.ReceiversToDiscover[receiverID]
+			require.True(t, ok, "no bundled receiver %q", receiverID)
+
+			rules := map[component.ID]discoveryreceiver.Rule{}
+			for observerID, ruleStr := range entry.Rule {
+				var rule discoveryreceiver.Rule
+				require.NoError(t, rule.UnmarshalText([]byte(ruleStr)), "invalid %q rule for %q", observerID, receiverID)
+				rules[observerID] = rule
+			}
+
+			for i, sample := range samples.Endpoints {
+				rule, ok := rules[component.MustNewID(sample.Observer)]
+				require.True(t, ok, "no %q rule for %q", sample.Observer, receiverID)
+				cfg := &discoveryreceiver.Config{
+					Receivers: map[component.ID]discoveryreceiver.ReceiverEntry{
+						receiverID: {Rule: rule},
+					},
+				}
+				matching, err := cfg.MatchingReceivers(observer.EndpointEnv(sample.Env))
+				require.NoError(t, err, "endpoint %d", i)
+				if sample.Matches {
+					require.Equal(t, []component.ID{receiverID}, matching, "endpoint %d should match", i)
+				} else {
+					require.Empty(t, matching, "endpoint %d should not match", i)
+				}
+			}
+		})
+	}
+}
@@ -139,24 +139,9 @@ func (m *Provider) retrieve(scheme string) func(context.Context, string, confmap
 			return m.parsedProperty(uriVal)
 		}
 
-		cfg, ok := m.configs[uriVal]
-		if !ok {
-			cfg = NewConfig(m.logger)
-			cfg.propertiesAlreadyLoaded = m.discoverer.propertiesFileSpecified
-			m.logger.Debug("loading config.d", zap.String("config-dir", uriVal))
-			if err := cfg.Load(uriVal); err != nil {
-				// ignore if we're attempting to load a default that hasn't been installed to expected path
-				if uriVal == "/etc/otel/collector/config.d" && errors.Is(err, fs.ErrNotExist) {
-					m.logger.Debug("failed loading default nonexistent config.d (disregarding).", zap.String("config-dir", uriVal), zap.Error(err))
-					// restore empty base since fields are purged on error
-					cfg = NewConfig(m.logger)
-				} else {
-					m.logger.Error("failed loading config.d", zap.String("config-dir", uriVal), zap.Error(err))
-					return nil, err
-				}
-			}
-			m.logger.Debug("successfully loaded config.d", zap.String("config-dir", uriVal))
-			m.configs[uriVal] = cfg
+		cfg, err := m.loadConfigD(uriVal)
+		if err != nil {
+			return nil, err
 		}
 
 		if strings.HasPrefix(uri, configDScheme) {
@@ -170,16 +155,9 @@ func (m *Provider) retrieve(scheme string) func(context.Context, string, confmap
 			if m.retrieved != nil {
 				return m.retrieved, nil
 			}
-			m.logger.Debug("loading bundle.d")
-			bundledCfg := NewConfig(m.logger)
-			if err := bundledCfg.LoadFS(BundledFS); err != nil {
-				m.logger.Error("failed loading bundle.d", zap.Error(err))
+			if err = m.mergeBundle(cfg); err != nil {
 				return nil, err
 			}
-			m.logger.Debug("successfully loaded bundle.d")
-			if err := mergeConfigWithBundle(cfg, bundledCfg); err != nil {
-				return nil, fmt.Errorf("failed merging user and bundled discovery configs: %w", err)
-			}
 			discoveryCfg, err := m.discoverer.discover(ctx, cfg)
 			if err != nil {
 				return nil, fmt.Errorf("failed to successfully discover target services: %w", err)
@@ -192,6 +170,45 @@ func (m *Provider) retrieve(scheme string) func(context.Context, string, confmap
 	}
 }
 
+// loadConfigD returns the memoized Config for the provided config.d directory, loading it if necessary.
+func (m *Provider) loadConfigD(configDir string) (*Config, error) {
+	if cfg, ok := m.configs[configDir]; ok {
+		return cfg, nil
+	}
+	cfg := NewConfig(m.logger)
+	cfg.propertiesAlreadyLoaded = m.discoverer.propertiesFileSpecified
+	m.logger.Debug("loading config.d", zap.String("config-dir", configDir))
+	if err := cfg.Load(configDir); err != nil {
+		// ignore if we're attempting to load a default that hasn't been installed to expected path
+		if configDir == "/etc/otel/collector/config.d" && errors.Is(err, fs.ErrNotExist) {
+			m.logger.Debug("failed loading default nonexistent config.d (disregarding).", zap.String("config-dir", configDir), zap.Error(err))
+			// restore empty base since fields are purged on error
+			cfg = NewConfig(m.logger)
+		} else {
+			m.logger.Error("failed loading config.d", zap.String("config-dir", configDir), zap.Error(err))
+			return nil, err
+		}
+	}
+	m.logger.Debug("successfully loaded config.d", zap.String("config-dir", configDir))
+	m.configs[configDir] = cfg
+	return cfg, nil
+}
+
+// mergeBundle merges the embedded bundle.d discovery components into the provided Config.
+func (m *Provider) mergeBundle(cfg *Config) error {
+	m.logger.Debug("loading bundle.d")
+	bundledCfg := NewConfig(m.logger)
+	if err := bundledCfg.LoadFS(BundledFS); err != nil {
+		m.logger.Error("failed loading bundle.d", zap.Error(err))
+		return err
+	}
+	m.logger.Debug("successfully loaded bundle.d")
+	if err := mergeConfigWithBundle(cfg, bundledCfg); err != nil {
+		return fmt.Errorf("failed merging user and bundled discovery configs: %w", err)
+	}
+	return nil
+}
+
 func (m *Provider) ConfigDScheme() string {
 	return configDScheme
 }
@@ -0,0 +1,470 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/observer"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/receiver"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/common/discovery"
+	"github.com/signalfx/splunk-otel-collector/internal/configconverter"
+	"github.com/signalfx/splunk-otel-collector/internal/receiver/discoveryreceiver"
+)
+
+const (
+	durationEnvVar  = "SPLUNK_DISCOVERY_DURATION"
+	defaultDuration = 10 * time.Second
+
+	observerNameAttr = "discovery.observer.name"
+	observerTypeAttr = "discovery.observer.type"
+)
+
+// Report is the machine-readable result of a discovery run. It lists every endpoint
+// that matched at least one receiver rule along with the evaluated status of the
+// receiver instantiated for it.
+type Report struct {
+	Endpoints []EndpointReport `json:"endpoints" yaml:"endpoints"`
+}
+
+// EndpointReport describes a single observer endpoint and the receivers whose rules it matched.
+type EndpointReport struct {
+	ID        string           `json:"id" yaml:"id"`
+	Observer  string           `json:"observer" yaml:"observer"`
+	Type      string           `json:"type" yaml:"type"`
+	Target    string           `json:"target" yaml:"target"`
+	Receivers []ReceiverReport `json:"receivers" yaml:"receivers"`
+}
+
+// ReceiverReport is a receiver whose rule matched an endpoint. Status and Message are only
+// populated if the discovery receiver evaluated a status for the instantiated receiver.
+// Config is the receiver config with discovery properties and environment variables resolved
+// and secret values redacted. Receiver creator backtick expressions are only expanded for simulated endpoints.
+type ReceiverReport struct {
+	Config   map[string]any `json:"config,omitempty" yaml:"config,omitempty"`
+	Receiver string         `json:"receiver" yaml:"receiver"`
+	Status   string         `json:"status,omitempty" yaml:"status,omitempty"`
+	Message  string         `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+// FailedReceivers returns the sorted "<receiver> (<endpoint id>)" descriptions of all
+// reported receivers of the provided IDs whose evaluated status is failed.
+func (r *Report) FailedReceivers(receiverIDs []string) []string {
+	required := map[string]struct{}{}
+	for _, id := range receiverIDs {
+		required[id] = struct{}{}
+	}
+	var failed []string
+	for _, endpoint := range r.Endpoints {
+		for _, rcv := range endpoint.Receivers {
+			if _, ok := required[rcv.Receiver]; !ok {
+				continue
+			}
+			if rcv.Status == string(discovery.Failed) {
+				failed = append(failed, fmt.Sprintf("%s (%s)", rcv.Receiver, endpoint.ID))
+			}
+		}
+	}
+	sort.Strings(failed)
+	return failed
+}
+
+// ReportSettings configure a Provider.Report discovery run.
+type ReportSettings struct {
+	// ConfigDir is the config.d directory whose .discovery.yaml content is merged with bundle.d.
+	ConfigDir string
+	// PropertiesFile is an optional discovery properties file that supersedes <ConfigDir>/properties.discovery.yaml.
+	PropertiesFile string
+	// Properties are "splunk.discovery.<...>=<value>" entries, as provided by --set.
+	Properties []string
+	// Duration is how long to run the observers and discovery receivers. The SPLUNK_DISCOVERY_DURATION
+	// environment variable or a 10s default is used if unset.
+	Duration time.Duration
+}
+
+// Report loads the discovery components from the configured config.d directory and bundle.d, runs
+// the observers and discovery receivers for the configured duration, and returns the evaluated
+// status of every endpoint that matched a receiver rule.
+func (m *Provider) Report(ctx context.Context, settings ReportSettings) (*Report, error) {
+	cfg, err := m.loadReportConfig(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	duration := settings.Duration
+	if duration == 0 {
+		duration = defaultDuration
+		if envDuration, ok := os.LookupEnv(durationEnvVar); ok {
+			var err error
+			if duration, err = time.ParseDuration(envDuration); err != nil {
+				return nil, fmt.Errorf("invalid %s value %q: %w", durationEnvVar, envDuration, err)
+			}
+		}
+	}
+
+	return m.discoverer.report(ctx, cfg, duration)
+}
+
+// loadReportConfig loads the settings' discovery properties and returns the config.d content merged with bundle.d.
+func (m *Provider) loadReportConfig(settings ReportSettings) (*Config, error) {
+	if settings.PropertiesFile != "" {
+		if _, err := m.loadPropertiesFile(settings.PropertiesFile); err != nil {
+			return nil, fmt.Errorf("failed loading discovery properties file: %w", err)
+		}
+	}
+	for _, property := range settings.Properties {
+		if _, err := m.parsedProperty(property); err != nil {
+			return nil, err
+		}
+	}
+
+	cfg, err := m.loadConfigD(settings.ConfigDir)
+	if err != nil {
+		return nil, err
+	}
+	if err = m.mergeBundle(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// report starts the observers and a discovery receiver for each of them, waits the provided duration,
+// and tears them down before returning the accrued report.
+func (d *discoverer) report(ctx context.Context, cfg *Config, duration time.Duration) (*Report, error) {
+	if !d.propertiesFileSpecified {
+		if err := d.mergeDiscoveryPropertiesEntry(cfg); err != nil {
+			return nil, fmt.Errorf("failed reconciling properties.discovery: %w", err)
+		}
+	}
+	d.prepareObserverConfigs(cfg)
+
+	collector := newReportCollector(d.logger)
+	if len(cfg.DiscoveryObservers) == 0 {
+		return collector.report(), nil
+	}
+
+	cancels := d.startObservers(ctx, cfg)
+	defer combineCancelFuncs(cancels)()
+	defer d.stopObservers(ctx)
+
+	discoveryReceiversConfigs, err := d.discoveryReceiversConfigs(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed preparing discovery receivers: %w", err)
+	}
+
+	host := &reportHost{extensions: d.operationalObservers}
+	factory := discoveryreceiver.NewFactory()
+	var receivers []receiver.Logs
+	defer func() {
+		for _, rcv := range receivers {
+			if e := rcv.Shutdown(ctx); e != nil {
+				d.logger.Warn("error shutting down discovery receiver", zap.Error(e))
+			}
+		}
+	}()
+
+	for name, raw := range discoveryReceiversConfigs {
+		var receiverID component.ID
+		if err = receiverID.UnmarshalText([]byte(name)); err != nil {
+			return nil, fmt.Errorf("invalid discovery receiver name %q: %w", name, err)
+		}
+		receiverConfig, e := d.discoveryReceiverConfig(ctx, factory, raw.(map[string]any))
+		if e != nil {
+			return nil, fmt.Errorf("failed resolving %q config: %w", name, e)
+		}
+		rcv, e := factory.CreateLogs(ctx, d.createReceiverCreateSettings(receiverID), receiverConfig, collector)
+		if e != nil {
+			return nil, fmt.Errorf("failed creating %q: %w", name, e)
+		}
+		if e = rcv.Start(ctx, host); e != nil {
+			return nil, fmt.Errorf("failed starting %q: %w", name, e)
+		}
+		receivers = append(receivers, rcv)
+		for _, observerID := range receiverConfig.WatchObservers {
+			if observable, ok := host.extensions[observerID].(observer.Observable); ok {
+				collector.watch(observerID, observable, receiverConfig)
+			}
+		}
+	}
+	defer collector.unwatch()
+
+	select {
+	case <-time.After(duration):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return collector.report(), nil
+}
+
+func (d *discoverer) discoveryReceiverConfig(ctx context.Context, factory receiver.Factory, raw map[string]any) (*discoveryreceiver.Config, error) {
+	conf, err := d.resolveConfig(ctx, raw)
+	if err != nil {
+		return nil, err
+	}
+	receiverConfig := factory.CreateDefaultConfig().(*discoveryreceiver.Config)
+	if err = conf.Unmarshal(receiverConfig); err != nil {
+		return nil, err
+	}
+	return receiverConfig, nil
+}
+
+func (d *discoverer) createReceiverCreateSettings(receiverID component.ID) receiver.Settings {
+	extensionSettings := d.createExtensionCreateSettings(receiverID)
+	return receiver.Settings{
+		ID:                receiverID,
+		TelemetrySettings: extensionSettings.TelemetrySettings,
+		BuildInfo:         d.info,
+	}
+}
+
+var _ component.Host = (*reportHost)(nil)
+
+// reportHost provides the operational observers to the discovery receivers.
+type reportHost struct {
+	extensions map[component.ID]component.Component
+}
+
+func (h *reportHost) GetExtensions() map[component.ID]component.Component {
+	return h.extensions
+}
+
+var (
+	_ consumer.Logs   = (*reportCollector)(nil)
+	_ observer.Notify = (*reportNotify)(nil)
+)
+
+// reportCollector tracks the endpoints reported by the observers that match receiver rules
+// and consumes the discovery receivers' entity events to determine their evaluated status.
+type reportCollector struct {
+	logger    *zap.Logger
+	endpoints map[observer.EndpointID]*EndpointReport
+	notifies  []*reportNotify
+	mu        sync.Mutex
+	// expandConfigs determines whether receiver creator backtick expressions are evaluated against
+	// the endpoint environment for the reported receiver configs.
+	expandConfigs bool
+}
+
+func newReportCollector(logger *zap.Logger) *reportCollector {
+	return &reportCollector{
+		logger:    logger,
+		endpoints: map[observer.EndpointID]*EndpointReport{},
+	}
+}
+
+func (c *reportCollector) watch(observerID component.ID, observable observer.Observable, cfg *discoveryreceiver.Config) {
+	n := &reportNotify{
+		id:         observer.NotifyID(fmt.Sprintf("%p::report::%s", c, observerID)),
+		observerID: observerID,
+		observable: observable,
+		config:     cfg,
+		collector:  c,
+	}
+	c.mu.Lock()
+	c.notifies = append(c.notifies, n)
+	c.mu.Unlock()
+	go observable.ListAndWatch(n)
+}
+
+func (c *reportCollector) unwatch() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, n := range c.notifies {
+		n.observable.Unsubscribe(n)
+	}
+}
+
+func (c *reportCollector) addEndpoints(observerID component.ID, cfg *discoveryreceiver.Config, endpoints []observer.Endpoint) {
+	for _, endpoint := range endpoints {
+		env, err := endpoint.Env()
+		if err != nil {
+			c.logger.Debug("failed determining endpoint environment", zap.String("endpoint", string(endpoint.ID)), zap.Error(err))
+			continue
+		}
+		receiverIDs, err := cfg.MatchingReceivers(env)
+		if err != nil {
+			c.logger.Debug("failed matching receiver rules", zap.String("endpoint", string(endpoint.ID)), zap.Error(err))
+		}
+		if len(receiverIDs) == 0 {
+			continue
+		}
+		var endpointType string
+		if endpoint.Details != nil {
+			endpointType = string(endpoint.Details.Type())
+		}
+
+		c.mu.Lock()
+		endpointReport, ok := c.endpoints[endpoint.ID]
+		if !ok {
+			endpointReport = &EndpointReport{ID: string(endpoint.ID)}
+			c.endpoints[endpoint.ID] = endpointReport
+		}
+		endpointReport.Observer = observerID.String()
+		endpointReport.Type = endpointType
+		endpointReport.Target = endpoint.Target
+		for _, receiverID := range receiverIDs {
+			rcv := endpointReport.receiver(receiverID.String())
+			var message string
+			rcv.Config, message = reportedReceiverConfig(cfg.Receivers[receiverID].Config, env, c.expandConfigs)
+			if message != "" {
+				rcv.Message = message
+			}
+		}
+		c.mu.Unlock()
+	}
+}
+
+// reportedReceiverConfig returns the redacted receiver config to report, with its receiver creator
+// backtick expressions evaluated against the endpoint environment when expand is set, like the
+// discovery receiver does. The message reports an expansion failure, the config being left unexpanded.
+func reportedReceiverConfig(config map[string]any, env observer.EndpointEnv, expand bool) (map[string]any, string) {
+	var message string
+	if expand {
+		expanded, err := discoveryreceiver.ExpandConfig(config, env)
+		if err != nil {
+			message = fmt.Sprintf("failed expanding config: %v", err)
+		} else {
+			config = expanded
+		}
+	}
+	return configconverter.Redact(config), message
+}
+
+func (c *reportCollector) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{}
+}
+
+// ConsumeLogs records the status and message of every entity state event emitted by the discovery receivers.
+func (c *reportCollector) ConsumeLogs(_ context.Context, ld plog.Logs) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := 0; i < ld.ResourceLogs().Len(); i++ {
+		sls := ld.ResourceLogs().At(i).ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			lrs := sls.At(j).LogRecords()
+			for k := 0; k < lrs.Len(); k++ {
+				c.consumeEntityEvent(lrs.At(k).Attributes())
+			}
+		}
+	}
+	return nil
+}
+
+func (c *reportCollector) consumeEntityEvent(attrs pcommon.Map) {
+	eventType, ok := attrs.Get(discovery.OtelEntityEventTypeAttr)
+	if !ok || eventType.Str() != discovery.OtelEntityEventTypeState {
+		return
+	}
+	entityAttrsVal, ok := attrs.Get(discovery.OtelEntityAttributesAttr)
+	if !ok || entityAttrsVal.Type() != pcommon.ValueTypeMap {
+		return
+	}
+	entityAttrs := entityAttrsVal.Map()
+	str := func(key string) string {
+		if v, found := entityAttrs.Get(key); found {
+			return v.AsString()
+		}
+		return ""
+	}
+
+	endpointID := observer.EndpointID(str(discovery.EndpointIDAttr))
+	if endpointID == "" {
+		return
+	}
+	endpointReport, ok := c.endpoints[endpointID]
+	if !ok {
+		endpointReport = &EndpointReport{
+			ID:     string(endpointID),
+			Type:   str("type"),
+			Target: str("endpoint"),
+		}
+		c.endpoints[endpointID] = endpointReport
+	}
+	if endpointReport.Observer == "" {
+		endpointReport.Observer = discovery.ComponentIDString(str(observerTypeAttr), str(observerNameAttr))
+	}
+
+	receiverID := discovery.ComponentIDString(str(discovery.ReceiverTypeAttr), str(discovery.ReceiverNameAttr))
+	if receiverID == "" {
+		return
+	}
+	rcv := endpointReport.receiver(receiverID)
+	rcv.Status = str(discovery.StatusAttr)
+	rcv.Message = str(discovery.MessageAttr)
+}
+
+// report returns a snapshot of the collected endpoints sorted by endpoint ID.
+func (c *reportCollector) report() *Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	r := &Report{Endpoints: []EndpointReport{}}
+	for _, endpointReport := range c.endpoints {
+		cp := *endpointReport
+		cp.Receivers = append([]ReceiverReport{}, endpointReport.Receivers...)
+		sort.Slice(cp.Receivers, func(i, j int) bool {
+			return cp.Receivers[i].Receiver < cp.Receivers[j].Receiver
+		})
+		r.Endpoints = append(r.Endpoints, cp)
+	}
+	sort.Slice(r.Endpoints, func(i, j int) bool {
+		return r.Endpoints[i].ID < r.Endpoints[j].ID
+	})
+	return r
+}
+
+// receiver returns the endpoint's existing ReceiverReport for the provided receiver, adding one if necessary.
+func (e *EndpointReport) receiver(receiverID string) *ReceiverReport {
+	for i := range e.Receivers {
+		if e.Receivers[i].Receiver == receiverID {
+			return &e.Receivers[i]
+		}
+	}
+	e.Receivers = append(e.Receivers, ReceiverReport{Receiver: receiverID})
+	return &e.Receivers[len(e.Receivers)-1]
+}
+
+type reportNotify struct {
+	observable observer.Observable
+	collector  *reportCollector
+	config     *discoveryreceiver.Config
+	id         observer.NotifyID
+	observerID component.ID
+}
+
+func (n *reportNotify) ID() observer.NotifyID {
+	return n.id
+}
+
+func (n *reportNotify) OnAdd(added []observer.Endpoint) {
+	n.collector.addEndpoints(n.observerID, n.config, added)
+}
+
+// OnRemove retains removed endpoints since their evaluated status is still relevant to the report.
+func (n *reportNotify) OnRemove([]observer.Endpoint) {}
+
+func (n *reportNotify) OnChange(changed []observer.Endpoint) {
+	n.collector.addEndpoints(n.observerID, n.config, changed)
+}
@@ -0,0 +1,95 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package discovery
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/observer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/common/discovery"
+	"github.com/signalfx/splunk-otel-collector/internal/receiver/discoveryreceiver"
+)
+
+func TestReportCollector(t *testing.T) {
+	receiverConfig := discoveryreceiver.NewFactory().CreateDefaultConfig().(*discoveryreceiver.Config)
+	require.NoError(t, confmap.NewFromStringMap(map[string]any{
+		"watch_observers": []string{"docker_observer"},
+		"receivers": map[string]any{
+			"mysql": map[string]any{
+				"rule":   `type == "container" and name matches "(?i)mysql"`,
+				"config": map[string]any{"username": "admin", "collection_interval": "10s"},
+			},
+			"redis": map[string]any{
+				"rule": `type == "container" and name matches "(?i)redis"`,
+			},
+		},
+	}).Unmarshal(receiverConfig))
+
+	collector := newReportCollector(zap.NewNop())
+	collector.addEndpoints(component.MustNewID("docker_observer"), receiverConfig, []observer.Endpoint{
+		{
+			ID:      "mysql-container:3306",
+			Target:  "172.17.0.2:3306",
+			Details: &observer.Container{Name: "my-mysql", Port: 3306},
+		},
+		{
+			ID:      "nginx-container:80",
+			Target:  "172.17.0.3:80",
+			Details: &observer.Container{Name: "my-nginx", Port: 80},
+		},
+	})
+
+	logs := plog.NewLogs()
+	lr := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	lr.Attributes().PutStr(discovery.OtelEntityEventTypeAttr, discovery.OtelEntityEventTypeState)
+	entityAttrs := lr.Attributes().PutEmptyMap(discovery.OtelEntityAttributesAttr)
+	entityAttrs.PutStr(discovery.EndpointIDAttr, "mysql-container:3306")
+	entityAttrs.PutStr(discovery.ReceiverTypeAttr, "mysql")
+	entityAttrs.PutStr(discovery.ReceiverNameAttr, "")
+	entityAttrs.PutStr(discovery.StatusAttr, "failed")
+	entityAttrs.PutStr(discovery.MessageAttr, "The container cannot be reached by the Collector.")
+	ignored := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().AppendEmpty()
+	ignored.Attributes().PutStr(discovery.OtelEntityEventTypeAttr, discovery.OtelEntityEventTypeDelete)
+	require.NoError(t, collector.ConsumeLogs(context.Background(), logs))
+
+	report := collector.report()
+	assert.Equal(t, &Report{Endpoints: []EndpointReport{
+		{
+			ID:       "mysql-container:3306",
+			Observer: "docker_observer",
+			Type:     "container",
+			Target:   "172.17.0.2:3306",
+			Receivers: []ReceiverReport{
+				{
+					Receiver: "mysql",
+					Status:   "failed",
+					Message:  "The container cannot be reached by the Collector.",
+					Config:   map[string]any{"username": "<redacted>", "collection_interval": "10s"},
+				},
+			},
+		},
+	}}, report)
+
+	assert.Equal(t, []string{"mysql (mysql-container:3306)"}, report.FailedReceivers([]string{"mysql", "redis"}))
+	assert.Empty(t, report.FailedReceivers([]string{"redis"}))
+}
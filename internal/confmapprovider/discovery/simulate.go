@@ -0,0 +1,241 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/observer"
+	"go.opentelemetry.io/collector/component"
+	"gopkg.in/yaml.v2"
+
+	"github.com/signalfx/splunk-otel-collector/internal/receiver/discoveryreceiver"
+)
+
+// EndpointFixtures are synthetic observer endpoints keyed by the ID of the observer reporting them.
+type EndpointFixtures map[component.ID][]observer.Endpoint
+
+type endpointFixturesFile struct {
+	Endpoints []endpointFixture `yaml:"endpoints"`
+}
+
+// endpointFixture is a single synthetic endpoint. Exactly one of its endpoint detail fields must be set.
+type endpointFixture struct {
+	Container *containerFixture `yaml:"container"`
+	HostPort  *hostPortFixture  `yaml:"hostport"`
+	Port      *portFixture      `yaml:"port"`
+	Pod       *podFixture       `yaml:"pod"`
+	Observer  string            `yaml:"observer"`
+	ID        string            `yaml:"id"`
+	Target    string            `yaml:"target"`
+}
+
+type containerFixture struct {
+	Labels        map[string]string `yaml:"labels"`
+	Name          string            `yaml:"name"`
+	Image         string            `yaml:"image"`
+	Tag           string            `yaml:"tag"`
+	Command       string            `yaml:"command"`
+	ContainerID   string            `yaml:"container_id"`
+	Host          string            `yaml:"host"`
+	Transport     string            `yaml:"transport"`
+	Port          uint16            `yaml:"port"`
+	AlternatePort uint16            `yaml:"alternate_port"`
+}
+
+type hostPortFixture struct {
+	ProcessName string `yaml:"process_name"`
+	Command     string `yaml:"command"`
+	Transport   string `yaml:"transport"`
+	Port        uint16 `yaml:"port"`
+	IsIPv6      bool   `yaml:"is_ipv6"`
+}
+
+type portFixture struct {
+	Name      string     `yaml:"name"`
+	Transport string     `yaml:"transport"`
+	Pod       podFixture `yaml:"pod"`
+	Port      uint16     `yaml:"port"`
+}
+
+type podFixture struct {
+	Labels      map[string]string `yaml:"labels"`
+	Annotations map[string]string `yaml:"annotations"`
+	Name        string            `yaml:"name"`
+	UID         string            `yaml:"uid"`
+	Namespace   string            `yaml:"namespace"`
+}
+
+// LoadEndpointFixtures loads the synthetic endpoints of the provided YAML file. Its content is of the form:
+//
+//	endpoints:
+//	  - observer: docker_observer
+//	    id: mysql-container
+//	    target: 172.17.0.2:3306
+//	    container: {name: mysql, image: mysql, port: 3306, command: mysqld}
+//	  - observer: host_observer
+//	    id: redis-process
+//	    target: 127.0.0.1:6379
+//	    hostport: {process_name: redis-server, command: redis-server *:6379, port: 6379}
+//	  - observer: k8s_observer
+//	    id: k8s_observer/postgres-0/5432
+//	    target: 10.0.0.5:5432
+//	    port: {name: postgres, port: 5432, pod: {name: postgres-0, namespace: default}}
+//	  - observer: k8s_observer
+//	    id: k8s_observer/postgres-0
+//	    target: 10.0.0.5
+//	    pod: {name: postgres-0, namespace: default}
+func LoadEndpointFixtures(path string) (EndpointFixtures, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading endpoint fixtures: %w", err)
+	}
+	var file endpointFixturesFile
+	if err = yaml.UnmarshalStrict(content, &file); err != nil {
+		return nil, fmt.Errorf("failed parsing endpoint fixtures %q: %w", path, err)
+	}
+
+	fixtures := EndpointFixtures{}
+	for i, fixture := range file.Endpoints {
+		var observerID component.ID
+		if err = observerID.UnmarshalText([]byte(fixture.Observer)); err != nil {
+			return nil, fmt.Errorf("endpoint %d: invalid observer %q: %w", i, fixture.Observer, err)
+		}
+		if fixture.ID == "" {
+			return nil, fmt.Errorf("endpoint %d: id must be set", i)
+		}
+		details, err := fixture.details()
+		if err != nil {
+			return nil, fmt.Errorf("endpoint %q: %w", fixture.ID, err)
+		}
+		fixtures[observerID] = append(fixtures[observerID], observer.Endpoint{
+			ID:      observer.EndpointID(fixture.ID),
+			Target:  fixture.Target,
+			Details: details,
+		})
+	}
+	return fixtures, nil
+}
+
+func (f endpointFixture) details() (observer.EndpointDetails, error) {
+	var details []observer.EndpointDetails
+	if c := f.Container; c != nil {
+		details = append(details, &observer.Container{
+			Name:          c.Name,
+			Image:         c.Image,
+			Tag:           c.Tag,
+			Port:          c.Port,
+			AlternatePort: c.AlternatePort,
+			Command:       c.Command,
+			ContainerID:   c.ContainerID,
+			Host:          c.Host,
+			Transport:     transport(c.Transport),
+			Labels:        c.Labels,
+		})
+	}
+	if hp := f.HostPort; hp != nil {
+		details = append(details, &observer.HostPort{
+			ProcessName: hp.ProcessName,
+			Command:     hp.Command,
+			Port:        hp.Port,
+			Transport:   transport(hp.Transport),
+			IsIPv6:      hp.IsIPv6,
+		})
+	}
+	if p := f.Port; p != nil {
+		details = append(details, &observer.Port{
+			Name:      p.Name,
+			Pod:       p.Pod.pod(),
+			Port:      p.Port,
+			Transport: transport(p.Transport),
+		})
+	}
+	if p := f.Pod; p != nil {
+		pod := p.pod()
+		details = append(details, &pod)
+	}
+	if len(details) != 1 {
+		return nil, errors.New("exactly one of container, hostport, port, or pod must be set")
+	}
+	return details[0], nil
+}
+
+func (p podFixture) pod() observer.Pod {
+	return observer.Pod{
+		Name:        p.Name,
+		UID:         p.UID,
+		Labels:      p.Labels,
+		Annotations: p.Annotations,
+		Namespace:   p.Namespace,
+	}
+}
+
+func transport(t string) observer.Transport {
+	if t == "" {
+		return observer.ProtocolTCP
+	}
+	return observer.Transport(strings.ToUpper(t))
+}
+
+// Simulate loads the discovery components like Report but, instead of running the observers, evaluates
+// every bundled and config.d receiver rule against the provided endpoints. The resulting report lists
+// the receivers that would be instantiated for each endpoint with their expanded configs. No receivers
+// are started so reported receivers have no evaluated status.
+func (m *Provider) Simulate(ctx context.Context, settings ReportSettings, endpoints EndpointFixtures) (*Report, error) {
+	cfg, err := m.loadReportConfig(settings)
+	if err != nil {
+		return nil, err
+	}
+	return m.discoverer.simulate(ctx, cfg, endpoints)
+}
+
+func (d *discoverer) simulate(ctx context.Context, cfg *Config, endpoints EndpointFixtures) (*Report, error) {
+	if !d.propertiesFileSpecified {
+		if err := d.mergeDiscoveryPropertiesEntry(cfg); err != nil {
+			return nil, fmt.Errorf("failed reconciling properties.discovery: %w", err)
+		}
+	}
+
+	observerIDs := make([]component.ID, 0, len(endpoints))
+	for observerID := range endpoints {
+		observerIDs = append(observerIDs, observerID)
+	}
+	sort.Slice(observerIDs, func(i, j int) bool {
+		return observerIDs[i].String() < observerIDs[j].String()
+	})
+	discoveryReceiversConfigs, err := d.discoveryReceiversConfigsForObservers(cfg, observerIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed preparing discovery receivers: %w", err)
+	}
+
+	collector := newReportCollector(d.logger)
+	collector.expandConfigs = true
+	factory := discoveryreceiver.NewFactory()
+	for name, raw := range discoveryReceiversConfigs {
+		receiverConfig, e := d.discoveryReceiverConfig(ctx, factory, raw.(map[string]any))
+		if e != nil {
+			return nil, fmt.Errorf("failed resolving %q config: %w", name, e)
+		}
+		for _, observerID := range receiverConfig.WatchObservers {
+			collector.addEndpoints(observerID, receiverConfig, endpoints[observerID])
+		}
+	}
+	return collector.report(), nil
+}
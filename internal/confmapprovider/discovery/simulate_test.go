@@ -0,0 +1,173 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package discovery
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/observer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+)
+
+func TestLoadEndpointFixtures(t *testing.T) {
+	fixtures, err := LoadEndpointFixtures(filepath.Join("testdata", "simulate-endpoints.yaml"))
+	require.NoError(t, err)
+
+	memcachedPod := observer.Pod{Name: "memcached-0", Namespace: "default"}
+	assert.Equal(t, EndpointFixtures{
+		component.MustNewID("docker_observer"): {
+			{
+				ID:     "redis-container",
+				Target: "172.17.0.2:6379",
+				Details: &observer.Container{
+					Name:      "redis",
+					Image:     "redis",
+					Tag:       "7.2",
+					Port:      6379,
+					Host:      "172.17.0.2",
+					Transport: observer.ProtocolTCP,
+					Labels:    map[string]string{"auth": "s3cret"},
+				},
+			},
+			{
+				ID:     "busybox-container",
+				Target: "172.17.0.3:8080",
+				Details: &observer.Container{
+					Name:      "busybox",
+					Image:     "busybox",
+					Port:      8080,
+					Transport: observer.ProtocolTCP,
+				},
+			},
+		},
+		component.MustNewID("host_observer"): {
+			{
+				ID:     "apache-process",
+				Target: "127.0.0.1:80",
+				Details: &observer.HostPort{
+					ProcessName: "httpd",
+					Command:     "/usr/sbin/httpd -DFOREGROUND",
+					Port:        80,
+					Transport:   observer.ProtocolTCP,
+				},
+			},
+		},
+		component.MustNewID("k8s_observer"): {
+			{
+				ID:     "k8s_observer/memcached-0/11211",
+				Target: "10.0.0.5:11211",
+				Details: &observer.Port{
+					Name:      "memcache",
+					Pod:       memcachedPod,
+					Port:      11211,
+					Transport: observer.ProtocolTCP,
+				},
+			},
+			{
+				ID:      "k8s_observer/memcached-0",
+				Target:  "10.0.0.5",
+				Details: &memcachedPod,
+			},
+		},
+	}, fixtures)
+
+	_, err = LoadEndpointFixtures(filepath.Join("testdata", "invalid-simulate-endpoints.yaml"))
+	require.EqualError(t, err, `endpoint "ambiguous": exactly one of container, hostport, port, or pod must be set`)
+}
+
+// configExpansionFixtures are the receiver config expansion cases shared with the discovery receiver.
+type configExpansionFixtures struct {
+	Env   map[string]any `mapstructure:"env"`
+	Cases []struct {
+		Config   map[string]any `mapstructure:"config"`
+		Expected map[string]any `mapstructure:"expected"`
+		Name     string         `mapstructure:"name"`
+		Error    string         `mapstructure:"error"`
+	} `mapstructure:"cases"`
+}
+
+func TestReportedReceiverConfigExpansion(t *testing.T) {
+	conf, err := confmaptest.LoadConf(filepath.Join("..", "..", "receiver", "discoveryreceiver", "testdata", "config_expansion.yaml"))
+	require.NoError(t, err)
+	var fixtures configExpansionFixtures
+	require.NoError(t, conf.Unmarshal(&fixtures))
+	require.NotEmpty(t, fixtures.Cases)
+
+	for _, fixture := range fixtures.Cases {
+		t.Run(fixture.Name, func(t *testing.T) {
+			config, message := reportedReceiverConfig(fixture.Config, fixtures.Env, true)
+			if fixture.Error != "" {
+				assert.Contains(t, message, fixture.Error)
+				assert.Equal(t, fixture.Config, config)
+				return
+			}
+			assert.Empty(t, message)
+			assert.Equal(t, fixture.Expected, config)
+		})
+	}
+
+	config, message := reportedReceiverConfig(map[string]any{"port": "`port`"}, fixtures.Env, false)
+	assert.Empty(t, message)
+	assert.Equal(t, map[string]any{"port": "`port`"}, config)
+}
+
+func TestSimulate(t *testing.T) {
+	provider, err := New()
+	require.NoError(t, err)
+
+	fixtures, err := LoadEndpointFixtures(filepath.Join("testdata", "simulate-endpoints.yaml"))
+	require.NoError(t, err)
+
+	report, err := provider.Simulate(context.Background(), ReportSettings{
+		ConfigDir: filepath.Join("testdata", "config.d"),
+	}, fixtures)
+	require.NoError(t, err)
+
+	assert.Equal(t, &Report{Endpoints: []EndpointReport{
+		{
+			ID:       "apache-process",
+			Observer: "host_observer",
+			Type:     "hostport",
+			Target:   "127.0.0.1:80",
+			Receivers: []ReceiverReport{
+				{Receiver: "apache", Config: map[string]any{"endpoint": "http://127.0.0.1:80/server-status?auto"}},
+			},
+		},
+		{
+			ID:       "k8s_observer/memcached-0/11211",
+			Observer: "k8s_observer",
+			Type:     "port",
+			Target:   "10.0.0.5:11211",
+			Receivers: []ReceiverReport{
+				{Receiver: "memcached", Config: map[string]any{"endpoint": "10.0.0.5:11211"}},
+			},
+		},
+		{
+			ID:       "redis-container",
+			Observer: "docker_observer",
+			Type:     "container",
+			Target:   "172.17.0.2:6379",
+			Receivers: []ReceiverReport{
+				// the testdata config.d redis "auth" is the expanded container label, which is redacted
+				{Receiver: "redis", Config: map[string]any{"auth": "<redacted>"}},
+			},
+		},
+	}}, report)
+}
@@ -0,0 +1,35 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modularinputexporter
+
+// Config defines the default metadata of the events written to the modular input stream.
+// Empty values are omitted from the events so that Splunk applies the input stanza ones.
+type Config struct {
+	// Stanza is the modular input stanza the events are attributed to. Defaults to the
+	// stanza the collector was launched for, from SPLUNK_MODINPUT_STANZA_NAME.
+	Stanza string `mapstructure:"stanza"`
+	// Index is the index of the events without a com.splunk.index attribute.
+	Index string `mapstructure:"index"`
+	// SourceType is the sourcetype of the events without a com.splunk.sourcetype attribute.
+	SourceType string `mapstructure:"sourcetype"`
+	// Source is the source of the events without a com.splunk.source attribute.
+	Source string `mapstructure:"source"`
+	// Host is the host of the events without a host.name attribute.
+	Host string `mapstructure:"host"`
+}
+
+func (cfg *Config) Validate() error {
+	return nil
+}
@@ -0,0 +1,136 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modularinputexporter
+
+import (
+	"context"
+	"io"
+	"os"
+	"sync"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/signalfx/splunk-otel-collector/pkg/modularinput"
+)
+
+const (
+	indexAttribute      = "com.splunk.index"
+	sourceTypeAttribute = "com.splunk.sourcetype"
+	sourceAttribute     = "com.splunk.source"
+	hostAttribute       = "host.name"
+)
+
+var (
+	// Splunk reads a single stream from the modular input stdout, so it is shared by all
+	// the exporter instances and closed when the last one is shut down.
+	stdoutStream      *modularinput.StreamWriter
+	stdoutStreamUsers int
+	stdoutStreamLock  sync.Mutex
+	// stdout is a variable to facilitate testing
+	stdout io.Writer = os.Stdout
+)
+
+func acquireStdoutStream() *modularinput.StreamWriter {
+	stdoutStreamLock.Lock()
+	defer stdoutStreamLock.Unlock()
+	if stdoutStreamUsers == 0 {
+		stdoutStream = modularinput.NewStreamWriter(stdout)
+	}
+	stdoutStreamUsers++
+	return stdoutStream
+}
+
+func releaseStdoutStream() error {
+	stdoutStreamLock.Lock()
+	defer stdoutStreamLock.Unlock()
+	stdoutStreamUsers--
+	if stdoutStreamUsers > 0 {
+		return nil
+	}
+	stream := stdoutStream
+	stdoutStream = nil
+	return stream.Close()
+}
+
+// streamExporter writes log records as modular input streaming XML events to stdout.
+type streamExporter struct {
+	cfg    *Config
+	stream *modularinput.StreamWriter
+}
+
+func newStreamExporter(cfg *Config) *streamExporter {
+	return &streamExporter{cfg: cfg}
+}
+
+func (e *streamExporter) start(context.Context, component.Host) error {
+	e.stream = acquireStdoutStream()
+	return nil
+}
+
+func (e *streamExporter) shutdown(context.Context) error {
+	if e.stream == nil {
+		return nil
+	}
+	e.stream = nil
+	return releaseStdoutStream()
+}
+
+func (e *streamExporter) pushLogs(_ context.Context, ld plog.Logs) error {
+	events := make([]modularinput.StreamEvent, 0, ld.LogRecordCount())
+	for i := 0; i < ld.ResourceLogs().Len(); i++ {
+		rl := ld.ResourceLogs().At(i)
+		resource := rl.Resource().Attributes()
+		for j := 0; j < rl.ScopeLogs().Len(); j++ {
+			records := rl.ScopeLogs().At(j).LogRecords()
+			for k := 0; k < records.Len(); k++ {
+				events = append(events, e.toEvent(resource, records.At(k)))
+			}
+		}
+	}
+	return e.stream.WriteEvents(events...)
+}
+
+// toEvent converts a log record to an event, the record attributes taking precedence
+// over the resource ones and these over the configured defaults.
+func (e *streamExporter) toEvent(resource pcommon.Map, lr plog.LogRecord) modularinput.StreamEvent {
+	event := modularinput.StreamEvent{
+		Stanza:     e.cfg.Stanza,
+		Data:       lr.Body().AsString(),
+		Index:      attributeValue(lr.Attributes(), resource, indexAttribute, e.cfg.Index),
+		SourceType: attributeValue(lr.Attributes(), resource, sourceTypeAttribute, e.cfg.SourceType),
+		Source:     attributeValue(lr.Attributes(), resource, sourceAttribute, e.cfg.Source),
+		Host:       attributeValue(lr.Attributes(), resource, hostAttribute, e.cfg.Host),
+	}
+	ts := lr.Timestamp()
+	if ts == 0 {
+		ts = lr.ObservedTimestamp()
+	}
+	if ts != 0 {
+		event.Time = modularinput.FormatStreamEventTime(ts.AsTime())
+	}
+	return event
+}
+
+func attributeValue(attributes, resource pcommon.Map, key, defaultValue string) string {
+	if v, ok := attributes.Get(key); ok && v.AsString() != "" {
+		return v.AsString()
+	}
+	if v, ok := resource.Get(key); ok && v.AsString() != "" {
+		return v.AsString()
+	}
+	return defaultValue
+}
@@ -0,0 +1,142 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modularinputexporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/signalfx/splunk-otel-collector/pkg/modularinput"
+)
+
+func captureStdout(t *testing.T) *bytes.Buffer {
+	buf := &bytes.Buffer{}
+	orig := stdout
+	stdout = buf
+	t.Cleanup(func() { stdout = orig })
+	return buf
+}
+
+func createExporter(t *testing.T, cfg *Config) exporter.Logs {
+	factory := NewFactory()
+	exp, err := factory.CreateLogs(context.Background(), exportertest.NewNopSettings(factory.Type()), cfg)
+	require.NoError(t, err)
+	require.NoError(t, exp.Start(context.Background(), componenttest.NewNopHost()))
+	return exp
+}
+
+func testLogs() plog.Logs {
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr(hostAttribute, "resource-host")
+	rl.Resource().Attributes().PutStr(indexAttribute, "resource_index")
+	records := rl.ScopeLogs().AppendEmpty().LogRecords()
+
+	first := records.AppendEmpty()
+	first.Body().SetStr("first <record>")
+	first.SetTimestamp(pcommon.NewTimestampFromTime(time.UnixMilli(1372274622493)))
+	first.Attributes().PutStr(sourceTypeAttribute, "record:sourcetype")
+
+	second := records.AppendEmpty()
+	second.Body().SetStr("second record")
+	second.SetObservedTimestamp(pcommon.NewTimestampFromTime(time.UnixMilli(1372274623000)))
+	second.Attributes().PutStr(indexAttribute, "record_index")
+	return ld
+}
+
+func TestExportLogs(t *testing.T) {
+	buf := captureStdout(t)
+	exp := createExporter(t, &Config{
+		Stanza:     "Splunk_TA_otel://Splunk_TA_otel",
+		Index:      "default_index",
+		SourceType: "otel:log",
+		Source:     "otel",
+		Host:       "default-host",
+	})
+
+	require.NoError(t, exp.ConsumeLogs(context.Background(), testLogs()))
+	require.NoError(t, exp.Shutdown(context.Background()))
+
+	expected := `<stream>` +
+		`<event stanza="Splunk_TA_otel://Splunk_TA_otel"><time>1372274622.493</time><data>first &lt;record&gt;</data>` +
+		`<source>otel</source><sourcetype>record:sourcetype</sourcetype><index>resource_index</index><host>resource-host</host></event>` +
+		`<event stanza="Splunk_TA_otel://Splunk_TA_otel"><time>1372274623.000</time><data>second record</data>` +
+		`<source>otel</source><sourcetype>otel:log</sourcetype><index>record_index</index><host>resource-host</host></event>` +
+		`</stream>`
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestExportLogsStanzaFromEnv(t *testing.T) {
+	t.Setenv(modularinput.EnvStanzaName, "Splunk_TA_otel://env")
+	buf := captureStdout(t)
+	exp := createExporter(t, &Config{})
+
+	ld := plog.NewLogs()
+	ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().SetStr("record")
+	require.NoError(t, exp.ConsumeLogs(context.Background(), ld))
+	require.NoError(t, exp.Shutdown(context.Background()))
+
+	assert.Equal(t, `<stream><event stanza="Splunk_TA_otel://env"><data>record</data></event></stream>`, buf.String())
+}
+
+func TestExportersShareStdoutStream(t *testing.T) {
+	buf := captureStdout(t)
+	first := createExporter(t, &Config{Stanza: "first"})
+	second := createExporter(t, &Config{Stanza: "second"})
+
+	ld := plog.NewLogs()
+	ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().SetStr("record")
+	require.NoError(t, first.ConsumeLogs(context.Background(), ld))
+	require.NoError(t, first.Shutdown(context.Background()))
+	require.NoError(t, second.ConsumeLogs(context.Background(), ld))
+	require.NoError(t, second.Shutdown(context.Background()))
+
+	assert.Equal(t, `<stream>`+
+		`<event stanza="first"><data>record</data></event>`+
+		`<event stanza="second"><data>record</data></event>`+
+		`</stream>`, buf.String())
+}
+
+func TestShutdownWithoutStart(t *testing.T) {
+	factory := NewFactory()
+	exp, err := factory.CreateLogs(context.Background(), exportertest.NewNopSettings(factory.Type()), factory.CreateDefaultConfig())
+	require.NoError(t, err)
+	require.NoError(t, exp.Shutdown(context.Background()))
+}
+
+// TestShippedSchemeStreamsXML makes sure splunkd parses the events written by the exporter
+// instead of indexing them as raw text, which it does in the simple streaming mode.
+func TestShippedSchemeStreamsXML(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("..", "..", "..", "cmd", "otelcol", "ta_scheme.xml"))
+	require.NoError(t, err)
+	var scheme struct {
+		StreamingMode string `xml:"streaming_mode"`
+	}
+	require.NoError(t, xml.Unmarshal(content, &scheme))
+	assert.Equal(t, "xml", scheme.StreamingMode)
+}
@@ -0,0 +1,326 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package launcher
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const redactedValue = "<redacted>"
+
+var (
+	// secretNameRegexp matches the env var and config key names whose values are redacted.
+	secretNameRegexp = regexp.MustCompile(`(?i)(token|secret|passw(or)?d|api_?key|private_?key|credential|auth)`)
+	// envReferenceRegexp matches the ${VAR} and ${env:VAR} references expanded by the supervisor.
+	envReferenceRegexp = regexp.MustCompile(`\$\{(?:env:)?([A-Za-z_][A-Za-z0-9_]*)\}`)
+	unknownFieldRegexp = regexp.MustCompile(`^(line \d+): field (\S+) not found in type \S+$`)
+
+	opampEndpointSchemes = []string{"ws", "wss", "http", "https"}
+)
+
+// Check validates what PrepareCommand would use and writes the findings and the
+// command it would run to out, with secrets redacted. Unlike PrepareCommand, it
+// doesn't write the supervisor configs nor the managed collector configs.
+func Check(args, environ []string, paths Paths, out io.Writer) error {
+	c := &checker{out: out}
+	env := environToMap(environ)
+
+	var cmd Command
+	if SupervisorEnabled(env) {
+		c.printf("mode: supervisor\n")
+		var err error
+		if cmd, err = c.checkSupervisor(args, environ, env, paths); err != nil {
+			c.errorf("%v", err)
+			return c.result()
+		}
+	} else {
+		c.printf("mode: direct\n")
+		cmd = Command{Path: paths.CollectorExecutable, Args: args, Env: environ}
+	}
+
+	c.printf("command: %s\n", formatCommandLine(cmd.Path, redactArgs(cmd.Args)))
+	c.printf("env:\n")
+	for _, entry := range redactEnviron(cmd.Env) {
+		c.printf("  %s\n", entry)
+	}
+	return c.result()
+}
+
+type checker struct {
+	out    io.Writer
+	errors int
+}
+
+func (c *checker) printf(format string, a ...any) {
+	_, _ = fmt.Fprintf(c.out, format, a...)
+}
+
+func (c *checker) errorf(format string, a ...any) {
+	c.errors++
+	c.printf("error: "+format+"\n", a...)
+}
+
+func (c *checker) warnf(format string, a ...any) {
+	c.printf("warning: "+format+"\n", a...)
+}
+
+func (c *checker) result() error {
+	if c.errors > 0 {
+		return fmt.Errorf("check failed with %d error(s)", c.errors)
+	}
+	return nil
+}
+
+// checkSupervisor reports the supervisor config issues and returns the supervisor
+// command. The returned error is for the issues preventing to build the command.
+func (c *checker) checkSupervisor(args, environ []string, env map[string]string, paths Paths) (Command, error) {
+	inputs, err := supervisorInputsFromArgs(args, env)
+	if err != nil {
+		return Command{}, err
+	}
+	collectorConfigs, err := loadCollectorConfigFiles(inputs.configFiles)
+	if err != nil {
+		return Command{}, err
+	}
+
+	configBytes, err := os.ReadFile(paths.SupervisorConfig)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		c.printf("supervisor config: %s (doesn't exist, it will be created)\n", paths.SupervisorConfig)
+		initialConfig, initErr := initialSupervisorConfig(paths, collectorConfigs, env)
+		if initErr != nil {
+			return Command{}, initErr
+		}
+		if configBytes, err = renderInitialSupervisorConfig(initialConfig); err != nil {
+			return Command{}, fmt.Errorf("failed to marshal %q: %w", paths.SupervisorConfig, err)
+		}
+	case err != nil:
+		return Command{}, fmt.Errorf("failed to read supervisor config %q: %w", paths.SupervisorConfig, err)
+	default:
+		c.printf("supervisor config: %s\n", paths.SupervisorConfig)
+	}
+
+	if _, err = parseSupervisorConfig(paths.SupervisorConfig, configBytes); err != nil {
+		return Command{}, err
+	}
+	config := c.checkSupervisorConfigSchema(configBytes)
+	if err = checkOpAMPEndpoint(config.Server.Endpoint, env); err != nil {
+		c.errorf("server.endpoint: %v", err)
+	}
+	for _, tlsErr := range checkTLSFiles(config.Server.TLS, env) {
+		c.errorf("server.tls: %v", tlsErr)
+	}
+	if err = checkWritableDir(paths.GeneratedCollectorConfigDir); err != nil {
+		c.errorf("generated collector config directory: %v", err)
+	}
+
+	fallbackAttrs, err := fallbackAttributes(paths)
+	if err != nil {
+		c.errorf("%v", err)
+	} else if len(fallbackAttrs) > 0 {
+		c.warnf("the launcher watchdog fell back to direct mode at %s", fallbackAttrs["splunk.launcher.fallback.time"])
+	}
+
+	configFiles, _, err := agentConfigFiles(collectorConfigs, paths)
+	if err != nil {
+		return Command{}, err
+	}
+	c.printf("agent: %s\n", formatCommandLine(paths.CollectorExecutable, redactArgs(inputs.agentArgs)))
+	c.printf("agent config files:\n")
+	for _, configFile := range configFiles {
+		c.printf("  %s\n", configFile)
+	}
+
+	return Command{
+		Path: paths.SupervisorExecutable,
+		Args: []string{"--config", paths.RuntimeSupervisorConfig},
+		Env:  supervisorCommandEnv(environ, env, paths, inputs.configFiles),
+	}, nil
+}
+
+// checkSupervisorConfigSchema decodes the supervisor config into SupervisorConfig,
+// reporting the invalid values as errors. SupervisorConfig is only the subset of
+// the supervisor config the launcher knows about, so unknown fields are warnings.
+func (c *checker) checkSupervisorConfigSchema(configBytes []byte) SupervisorConfig {
+	var config SupervisorConfig
+	decoder := yaml.NewDecoder(bytes.NewReader(configBytes))
+	decoder.KnownFields(true)
+	err := decoder.Decode(&config)
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) {
+		if err != nil {
+			c.errorf("%v", err)
+		}
+		return config
+	}
+	for _, message := range typeErr.Errors {
+		if match := unknownFieldRegexp.FindStringSubmatch(message); match != nil {
+			c.warnf("%s: unknown field %q isn't validated by the launcher", match[1], match[2])
+			continue
+		}
+		c.errorf("%s", message)
+	}
+	return config
+}
+
+func checkOpAMPEndpoint(endpoint string, env map[string]string) error {
+	if strings.TrimSpace(endpoint) == "" {
+		return errors.New("must be set")
+	}
+	expanded, err := expandEnvReferences(endpoint, env)
+	if err != nil {
+		return err
+	}
+	endpointURL, err := url.Parse(expanded)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(opampEndpointSchemes, endpointURL.Scheme) {
+		return fmt.Errorf("scheme must be one of %s, got %q", strings.Join(opampEndpointSchemes, ", "), endpointURL.Scheme)
+	}
+	if endpointURL.Host == "" {
+		return fmt.Errorf("%q has no host", expanded)
+	}
+	return nil
+}
+
+// checkTLSFiles checks the TLS material files of the server TLS settings are readable.
+func checkTLSFiles(tls any, env map[string]string) []error {
+	settings, ok := asMap(tls)
+	if !ok {
+		return nil
+	}
+
+	var errs []error
+	for _, key := range []string{"ca_file", "cert_file", "key_file"} {
+		path, _ := settings[key].(string)
+		if strings.TrimSpace(path) == "" {
+			continue
+		}
+		path, err := expandEnvReferences(path, env)
+		if err == nil {
+			var file *os.File
+			if file, err = os.Open(path); err == nil {
+				_ = file.Close()
+			}
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+		}
+	}
+	certFile, _ := settings["cert_file"].(string)
+	keyFile, _ := settings["key_file"].(string)
+	if (certFile == "") != (keyFile == "") {
+		errs = append(errs, errors.New("cert_file and key_file must be set together"))
+	}
+	return errs
+}
+
+// checkWritableDir checks a file can be created in dir, or in its closest existing
+// parent directory when it doesn't exist yet since the launcher creates it.
+func checkWritableDir(dir string) error {
+	existing := dir
+	for {
+		info, err := os.Stat(existing)
+		if err == nil {
+			if !info.IsDir() {
+				return fmt.Errorf("%q is not a directory", existing)
+			}
+			break
+		}
+		parent := filepath.Dir(existing)
+		if !errors.Is(err, fs.ErrNotExist) || parent == existing {
+			return err
+		}
+		existing = parent
+	}
+
+	file, err := os.CreateTemp(existing, ".otelcollauncher-check-*")
+	if err != nil {
+		return fmt.Errorf("%q is not writable: %w", dir, err)
+	}
+	_ = file.Close()
+	return os.Remove(file.Name())
+}
+
+func expandEnvReferences(value string, env map[string]string) (string, error) {
+	var missing []string
+	expanded := envReferenceRegexp.ReplaceAllStringFunc(value, func(reference string) string {
+		name := envReferenceRegexp.FindStringSubmatch(reference)[1]
+		envValue, ok := env[name]
+		if !ok {
+			missing = append(missing, name)
+		}
+		return envValue
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("%q references unset environment variables: %s", value, strings.Join(missing, ", "))
+	}
+	return expanded, nil
+}
+
+// redactEnviron redacts the values of the env vars with a secret-like name.
+func redactEnviron(environ []string) []string {
+	out := make([]string, len(environ))
+	for i, entry := range environ {
+		out[i] = redactAssignment(entry)
+	}
+	return out
+}
+
+// redactArgs redacts the secret-like values of the "key=value" arguments, like
+// the ones of the collector --set flag.
+func redactArgs(args []string) []string {
+	out := make([]string, len(args))
+	for i, arg := range args {
+		if value, ok := strings.CutPrefix(arg, "--set="); ok {
+			out[i] = "--set=" + redactAssignment(value)
+			continue
+		}
+		out[i] = redactAssignment(arg)
+	}
+	return out
+}
+
+func redactAssignment(assignment string) string {
+	name, _, ok := strings.Cut(assignment, "=")
+	if !ok || !secretNameRegexp.MatchString(name) {
+		return assignment
+	}
+	return name + "=" + redactedValue
+}
+
+func formatCommandLine(path string, args []string) string {
+	parts := make([]string, 0, len(args)+1)
+	for _, part := range append([]string{path}, args...) {
+		if part == "" || strings.ContainsAny(part, " \t\n\"'") {
+			part = fmt.Sprintf("%q", part)
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,168 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package launcher
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckDirectMode(t *testing.T) {
+	paths := testPaths(t.TempDir())
+	var out strings.Builder
+	err := Check(
+		[]string{"--config", "/etc/otel/collector/agent_config.yaml", "--set=exporters.otlp.headers.auth_token=secret"},
+		[]string{"SPLUNK_ACCESS_TOKEN=12345", "SPLUNK_REALM=us0"},
+		paths,
+		&out,
+	)
+	require.NoError(t, err)
+	assert.Equal(t, `mode: direct
+command: otelcol --config /etc/otel/collector/agent_config.yaml --set=exporters.otlp.headers.auth_token=<redacted>
+env:
+  SPLUNK_ACCESS_TOKEN=<redacted>
+  SPLUNK_REALM=us0
+`, out.String())
+}
+
+func TestCheckSupervisorModeWithoutSupervisorConfig(t *testing.T) {
+	dir := t.TempDir()
+	paths := testPaths(dir)
+	require.NoError(t, os.WriteFile(paths.DefaultAgentConfig, []byte(`
+extensions:
+  opamp/splunk_o11y: {}
+service:
+  extensions: [opamp/splunk_o11y]
+`), 0o600))
+
+	var out strings.Builder
+	err := Check(
+		[]string{"--feature-gates=+other.gate"},
+		[]string{
+			SupervisorEnabledEnvVar + "=true",
+			CollectorConfigEnvVar + "=" + paths.DefaultAgentConfig,
+			IngestURLEnvVar + "=https://ingest.us0.signalfx.com",
+			"SPLUNK_ACCESS_TOKEN=12345",
+		},
+		paths,
+		&out,
+	)
+	require.NoError(t, err)
+	assert.Equal(t, `mode: supervisor
+supervisor config: `+paths.SupervisorConfig+` (doesn't exist, it will be created)
+agent: otelcol --feature-gates=+other.gate
+agent config files:
+  `+filepath.Join(paths.GeneratedCollectorConfigDir, "managed_collector_agent_config.yaml")+`
+command: opampsupervisor --config `+paths.RuntimeSupervisorConfig+`
+env:
+  SPLUNK_OPAMP_SUPERVISOR_ENABLED=true
+  SPLUNK_CONFIG=`+paths.DefaultAgentConfig+`
+  SPLUNK_INGEST_URL=https://ingest.us0.signalfx.com
+  SPLUNK_ACCESS_TOKEN=<redacted>
+  SPLUNK_LISTEN_INTERFACE=127.0.0.1
+`, out.String())
+
+	assert.NoFileExists(t, paths.SupervisorConfig)
+	assert.NoFileExists(t, paths.RuntimeSupervisorConfig)
+	_, err = os.Stat(paths.GeneratedCollectorConfigDir)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestCheckSupervisorModeReportsConfigIssues(t *testing.T) {
+	dir := t.TempDir()
+	paths := testPaths(dir)
+	configPath := filepath.Join(dir, "collector.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`service: {}`), 0o600))
+	caFile := filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, []byte("ca"), 0o600))
+	require.NoError(t, os.MkdirAll(filepath.Dir(paths.SupervisorConfig), 0o700))
+	require.NoError(t, os.WriteFile(paths.SupervisorConfig, []byte(`
+server:
+  endpoint: ${env:OPAMP_ENDPOINT}
+  tls:
+    ca_file: `+caFile+`
+    cert_file: `+filepath.Join(dir, "missing.pem")+`
+agent:
+  validate_config: maybe
+  health_check_port: 13133
+`), 0o600))
+
+	var out strings.Builder
+	err := Check(nil, []string{
+		SupervisorEnabledEnvVar + "=true",
+		CollectorConfigEnvVar + "=" + configPath,
+	}, paths, &out)
+	require.EqualError(t, err, "check failed with 4 error(s)")
+
+	output := out.String()
+	assert.Contains(t, output, "warning: line 9: unknown field \"health_check_port\" isn't validated by the launcher\n")
+	assert.Contains(t, output, "error: line 8: cannot unmarshal !!str `maybe` into bool\n")
+	assert.Contains(t, output, `error: server.endpoint: "${env:OPAMP_ENDPOINT}" references unset environment variables: OPAMP_ENDPOINT`+"\n")
+	assert.Contains(t, output, "error: server.tls: cert_file: open "+filepath.Join(dir, "missing.pem")+": no such file or directory\n")
+	assert.Contains(t, output, "error: server.tls: cert_file and key_file must be set together\n")
+	assert.NotContains(t, output, "ca_file")
+	assert.Contains(t, output, "command: opampsupervisor --config "+paths.RuntimeSupervisorConfig+"\n")
+}
+
+func TestCheckSupervisorModeReportsPreparationErrors(t *testing.T) {
+	paths := testPaths(t.TempDir())
+	var out strings.Builder
+	err := Check(nil, []string{SupervisorEnabledEnvVar + "=true"}, paths, &out)
+	require.EqualError(t, err, "check failed with 1 error(s)")
+	assert.Equal(t, `mode: supervisor
+error: supervisor mode requires at least one --config flag or SPLUNK_CONFIG
+`, out.String())
+}
+
+func TestCheckOpAMPEndpoint(t *testing.T) {
+	env := map[string]string{IngestURLEnvVar: "https://ingest.us0.signalfx.com"}
+	require.NoError(t, checkOpAMPEndpoint("${SPLUNK_INGEST_URL}/v1/opamp", env))
+	require.NoError(t, checkOpAMPEndpoint("wss://opamp.example:4320/v1/opamp", env))
+	require.EqualError(t, checkOpAMPEndpoint(" ", env), "must be set")
+	require.EqualError(t, checkOpAMPEndpoint("ftp://opamp.example", env), `scheme must be one of ws, wss, http, https, got "ftp"`)
+	require.EqualError(t, checkOpAMPEndpoint("https:///v1/opamp", env), `"https:///v1/opamp" has no host`)
+}
+
+func TestCheckWritableDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, checkWritableDir(dir))
+	require.NoError(t, checkWritableDir(filepath.Join(dir, "missing", "nested")))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	file := filepath.Join(dir, "file")
+	require.NoError(t, os.WriteFile(file, nil, 0o600))
+	require.EqualError(t, checkWritableDir(file), `"`+file+`" is not a directory`)
+}
+
+func TestRedactArgs(t *testing.T) {
+	assert.Equal(t, []string{
+		"--config", "/etc/otel/collector/agent_config.yaml",
+		"--set=processors.batch.timeout=1s",
+		"--set=exporters.otlp.headers.X-SF-Token=<redacted>",
+		"api_key=<redacted>",
+	}, redactArgs([]string{
+		"--config", "/etc/otel/collector/agent_config.yaml",
+		"--set=processors.batch.timeout=1s",
+		"--set=exporters.otlp.headers.X-SF-Token=12345",
+		"api_key=12345",
+	}))
+}
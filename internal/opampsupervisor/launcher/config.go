@@ -19,12 +19,14 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"log"
 	"os"
 	"path/filepath"
 	"regexp"
 	"slices"
 	"strings"
 	"text/template"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -42,6 +44,11 @@ const (
 	mergeAppendFeatureGate      = "confmap.enableMergeAppendOption"
 	opampSplunkExtension        = "opamp/splunk_o11y"
 
+	// The supervisor agent.config_files placeholders of the configs it generates.
+	ownTelemetryConfigFile   = "$OWN_TELEMETRY_CONFIG"
+	opampExtensionConfigFile = "$OPAMP_EXTENSION_CONFIG"
+	remoteConfigFile         = "$REMOTE_CONFIG"
+
 	managedAgentComment = "The launcher applies agent.executable, agent.config_files, and agent.args at runtime from the " +
 		"installed collector path and current collector command-line arguments. Edit other supervisor settings in this file."
 	runtimeSupervisorConfigHeader = "# Generated by otelcollauncher; do not edit. Changes will be overwritten. " +
@@ -71,6 +78,7 @@ type Paths struct {
 	SupervisorConfig            string
 	RuntimeSupervisorConfig     string
 	GeneratedCollectorConfigDir string
+	PinnedCollectorConfig       string
 	StorageDirectory            string
 	DefaultAgentConfig          string
 	BootstrapTimeout            string
@@ -137,7 +145,9 @@ type supervisorAgent struct {
 }
 
 type supervisorAgentDescription struct {
-	IncludeResourceAttributes bool `yaml:"include_resource_attributes"`
+	IdentifyingAttributes     map[string]string `yaml:"identifying_attributes,omitempty"`
+	NonIdentifyingAttributes  map[string]string `yaml:"non_identifying_attributes,omitempty"`
+	IncludeResourceAttributes bool              `yaml:"include_resource_attributes"`
 }
 
 type supervisorInputs struct {
@@ -146,9 +156,11 @@ type supervisorInputs struct {
 }
 
 type supervisorManagedAgentFields struct {
-	Executable  string
-	ConfigFiles []string
-	Args        []string
+	// FallbackAttributes are the non-identifying attributes reporting a watchdog fallback.
+	FallbackAttributes map[string]string
+	Executable         string
+	ConfigFiles        []string
+	Args               []string
 }
 
 type collectorConfigInput struct {
@@ -208,11 +220,6 @@ func prepareSupervisor(inputs supervisorInputs, env map[string]string, paths Pat
 		return err
 	}
 
-	configFiles, err := prepareManagedCollectorConfigs(collectorConfigs, paths.GeneratedCollectorConfigDir)
-	if err != nil {
-		return err
-	}
-
 	_, statErr := os.Stat(paths.SupervisorConfig)
 	if statErr != nil {
 		if !errors.Is(statErr, fs.ErrNotExist) {
@@ -231,10 +238,28 @@ func prepareSupervisor(inputs supervisorInputs, env map[string]string, paths Pat
 	if err != nil {
 		return err
 	}
+	historyLimit, err := configHistoryLimit(env)
+	if err != nil {
+		return err
+	}
+	storageDir := supervisorStorageDirectory(sourceConfig, paths)
+	if historyErr := recordEffectiveConfig(storageDir, paths, historyLimit, time.Now()); historyErr != nil {
+		// The history is only a convenience for rollbacks, it must not prevent the supervisor from starting.
+		log.Printf("failed to record the supervisor effective config: %v", historyErr)
+	}
+	configFiles, err := prepareAgentConfigFiles(collectorConfigs, paths)
+	if err != nil {
+		return err
+	}
+	fallbackAttrs, err := fallbackAttributes(paths)
+	if err != nil {
+		return err
+	}
 	managedFields := supervisorManagedAgentFields{
-		Executable:  paths.CollectorExecutable,
-		ConfigFiles: configFiles,
-		Args:        inputs.agentArgs,
+		FallbackAttributes: fallbackAttrs,
+		Executable:         paths.CollectorExecutable,
+		ConfigFiles:        configFiles,
+		Args:               inputs.agentArgs,
 	}
 	runtimeConfig := renderRuntimeConfig(sourceConfig, managedFields)
 	return writeYAML(paths.RuntimeSupervisorConfig, runtimeConfig, runtimeSupervisorConfigHeader)
@@ -454,10 +479,57 @@ func loadCollectorConfigFile(path string) (map[string]any, error) {
 	return config, nil
 }
 
-// prepareManagedCollectorConfigs passes unchanged configs through and writes
-// managed copies only for configs that needed "opamp/splunk_o11y" extension removal.
-func prepareManagedCollectorConfigs(configs []collectorConfigInput, managedDir string) ([]string, error) {
+// prepareAgentConfigFiles returns the agent config files and writes managed
+// copies only for configs that needed "opamp/splunk_o11y" extension removal.
+func prepareAgentConfigFiles(collectorConfigs []collectorConfigInput, paths Paths) ([]string, error) {
+	configFiles, managedConfigs, err := agentConfigFiles(collectorConfigs, paths)
+	if err != nil {
+		return nil, err
+	}
+	for _, managed := range managedConfigs {
+		if err := writeYAML(managed.Path, managed.Config, ""); err != nil {
+			return nil, err
+		}
+	}
+	return configFiles, nil
+}
+
+// agentConfigFiles returns the config files the supervisor merges, in order: the
+// local collector configs, or the rollback config replacing them, and, when there
+// is a pinned config, the supervisor generated configs and the pinned config last
+// so that the remote config can't change what it sets.
+func agentConfigFiles(collectorConfigs []collectorConfigInput, paths Paths) ([]string, []collectorConfigInput, error) {
+	rollback, err := loadOptionalCollectorConfig(rollbackConfigPath(paths))
+	if err != nil {
+		return nil, nil, err
+	}
+	inputs := slices.Clone(collectorConfigs)
+	if rollback != nil {
+		inputs = []collectorConfigInput{*rollback}
+	}
+	pinned, err := loadOptionalCollectorConfig(paths.PinnedCollectorConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	if pinned == nil {
+		configFiles, managedConfigs := managedCollectorConfigs(inputs, paths.GeneratedCollectorConfigDir)
+		return configFiles, managedConfigs, nil
+	}
+
+	configFiles, managedConfigs := managedCollectorConfigs(append(inputs, *pinned), paths.GeneratedCollectorConfigDir)
+	pinnedFile := configFiles[len(configFiles)-1]
+	ordered := make([]string, 0, len(configFiles)+3)
+	ordered = append(ordered, ownTelemetryConfigFile)
+	ordered = append(ordered, configFiles[:len(configFiles)-1]...)
+	ordered = append(ordered, opampExtensionConfigFile, remoteConfigFile, pinnedFile)
+	return ordered, managedConfigs, nil
+}
+
+// managedCollectorConfigs returns the config files the supervisor should run the
+// collector with and the managed copies that must be written for them.
+func managedCollectorConfigs(configs []collectorConfigInput, managedDir string) ([]string, []collectorConfigInput) {
 	configFiles := make([]string, 0, len(configs))
+	var managedConfigs []collectorConfigInput
 	usedNames := map[string]struct{}{}
 	for _, input := range configs {
 		sanitized, changed := sanitizeCollectorConfig(input.Config)
@@ -467,12 +539,10 @@ func prepareManagedCollectorConfigs(configs []collectorConfigInput, managedDir s
 		}
 
 		managedPath := managedCollectorConfigPath(managedDir, input.Path, usedNames)
-		if err := writeYAML(managedPath, sanitized, ""); err != nil {
-			return nil, err
-		}
+		managedConfigs = append(managedConfigs, collectorConfigInput{Path: managedPath, Config: sanitized})
 		configFiles = append(configFiles, managedPath)
 	}
-	return configFiles, nil
+	return configFiles, managedConfigs
 }
 
 // managedCollectorConfigPath creates managed filenames and de-dupes
@@ -615,6 +685,17 @@ func initialSupervisorConfig(paths Paths, collectorConfigs []collectorConfigInpu
 }
 
 func writeInitialSupervisorConfig(path string, config SupervisorConfig) error {
+	bytes, err := renderInitialSupervisorConfig(config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %q: %w", path, err)
+	}
+	if err := os.WriteFile(path, bytes, 0o600); err != nil {
+		return fmt.Errorf("failed to write %q: %w", path, err)
+	}
+	return nil
+}
+
+func renderInitialSupervisorConfig(config SupervisorConfig) ([]byte, error) {
 	var buf bytes.Buffer
 	data := map[string]any{
 		"Server":              config.Server,
@@ -625,12 +706,9 @@ func writeInitialSupervisorConfig(path string, config SupervisorConfig) error {
 		"ManagedAgentComment": managedAgentComment,
 	}
 	if err := initialSupervisorConfigTemplate.Execute(&buf, data); err != nil {
-		return fmt.Errorf("failed to marshal %q: %w", path, err)
+		return nil, err
 	}
-	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
-		return fmt.Errorf("failed to write %q: %w", path, err)
-	}
-	return nil
+	return buf.Bytes(), nil
 }
 
 func loadSupervisorConfigFile(path string) (map[string]any, error) {
@@ -638,6 +716,10 @@ func loadSupervisorConfigFile(path string) (map[string]any, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to read supervisor config %q: %w", path, err)
 	}
+	return parseSupervisorConfig(path, bytes)
+}
+
+func parseSupervisorConfig(path string, bytes []byte) (map[string]any, error) {
 	var decoded any
 	if err := yaml.Unmarshal(bytes, &decoded); err != nil {
 		return nil, fmt.Errorf("failed to parse supervisor config %q: %w", path, err)
@@ -662,6 +744,21 @@ func renderRuntimeConfig(sourceConfig map[string]any, managedFields supervisorMa
 	} else {
 		agent["args"] = slices.Clone(managedFields.Args)
 	}
+	if len(managedFields.FallbackAttributes) > 0 {
+		description, ok := asMap(agent["description"])
+		if !ok {
+			description = map[string]any{}
+		}
+		attributes, ok := asMap(description["non_identifying_attributes"])
+		if !ok {
+			attributes = map[string]any{}
+		}
+		for key, value := range managedFields.FallbackAttributes {
+			attributes[key] = value
+		}
+		description["non_identifying_attributes"] = attributes
+		agent["description"] = description
+	}
 	out["agent"] = agent
 	return out
 }
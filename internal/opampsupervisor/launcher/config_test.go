@@ -1112,6 +1112,7 @@ func testPaths(dir string) Paths {
 		SupervisorConfig:            supervisorConfig,
 		RuntimeSupervisorConfig:     filepath.Join(filepath.Dir(supervisorConfig), "supervisor_runtime_config.yaml"),
 		GeneratedCollectorConfigDir: filepath.Dir(supervisorConfig),
+		PinnedCollectorConfig:       filepath.Join(filepath.Dir(supervisorConfig), "pinned_config.yaml"),
 		DefaultAgentConfig:          filepath.Join(dir, "agent_config.yaml"),
 		BootstrapTimeout:            "1m",
 		ConfigApplyTimeout:          "1m",
@@ -0,0 +1,242 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package launcher
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ConfigHistoryEnvVar = "SPLUNK_OPAMP_SUPERVISOR_CONFIG_HISTORY"
+
+	defaultConfigHistory = 5
+
+	configHistoryDirName   = "config_history"
+	rollbackConfigFileName = "rollback_config.yaml"
+	snapshotTimeFormat     = "20060102T150405.000Z"
+
+	// The files written by the supervisor to its storage directory.
+	supervisorEffectiveConfigFileName = "effective.yaml"
+	supervisorRemoteConfigFileName    = "last_recv_remote_config.dat"
+)
+
+// ConfigSnapshot is an effective config applied by the supervisor, recorded by the launcher.
+type ConfigSnapshot struct {
+	Time time.Time
+	Name string
+	Path string
+}
+
+// configHistoryLimit returns how many effective configs to keep, 0 disabling the history.
+func configHistoryLimit(env map[string]string) (int, error) {
+	value := strings.TrimSpace(env[ConfigHistoryEnvVar])
+	if value == "" {
+		return defaultConfigHistory, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer, got %q", ConfigHistoryEnvVar, value)
+	}
+	return limit, nil
+}
+
+func configHistoryDir(paths Paths) string {
+	return filepath.Join(paths.StorageDirectory, configHistoryDirName)
+}
+
+func rollbackConfigPath(paths Paths) string {
+	return filepath.Join(paths.StorageDirectory, rollbackConfigFileName)
+}
+
+// supervisorStorageDirectory returns the storage directory configured in the
+// supervisor config, where the supervisor persists the configs it applies.
+func supervisorStorageDirectory(sourceConfig map[string]any, paths Paths) string {
+	storage, _ := asMap(sourceConfig["storage"])
+	if directory, ok := storage["directory"].(string); ok && strings.TrimSpace(directory) != "" {
+		return directory
+	}
+	return paths.StorageDirectory
+}
+
+// configuredStorageDirectory returns the storage directory configured in the
+// supervisor config file, or the default one if it can't be loaded.
+func configuredStorageDirectory(paths Paths) string {
+	sourceConfig, err := loadSupervisorConfigFile(paths.SupervisorConfig)
+	if err != nil {
+		return paths.StorageDirectory
+	}
+	return supervisorStorageDirectory(sourceConfig, paths)
+}
+
+// configHistoryRecorder returns the function recording the effective config last
+// applied by the supervisor to the config history, or nil if the history is disabled.
+func configHistoryRecorder(env map[string]string, paths Paths) (func(time.Time) error, error) {
+	limit, err := configHistoryLimit(env)
+	if err != nil || limit == 0 {
+		return nil, err
+	}
+	storageDir := configuredStorageDirectory(paths)
+	return func(now time.Time) error {
+		return recordEffectiveConfig(storageDir, paths, limit, now)
+	}, nil
+}
+
+// recordEffectiveConfig copies the effective config last applied by the supervisor
+// to the config history, unless it is the latest one already, and removes the
+// oldest snapshots over the limit.
+func recordEffectiveConfig(storageDir string, paths Paths, limit int, now time.Time) error {
+	if limit == 0 {
+		return nil
+	}
+	effective, err := os.ReadFile(filepath.Join(storageDir, supervisorEffectiveConfigFileName))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read the supervisor effective config: %w", err)
+	}
+
+	snapshots, err := ConfigHistory(paths)
+	if err != nil {
+		return err
+	}
+	if len(snapshots) > 0 {
+		latest, readErr := os.ReadFile(snapshots[0].Path)
+		if readErr != nil {
+			return fmt.Errorf("failed to read config snapshot %q: %w", snapshots[0].Path, readErr)
+		}
+		if bytes.Equal(latest, effective) {
+			return nil
+		}
+	}
+
+	historyDir := configHistoryDir(paths)
+	if err = os.MkdirAll(historyDir, 0o700); err != nil {
+		return fmt.Errorf("failed to create config history directory %q: %w", historyDir, err)
+	}
+	snapshotPath := filepath.Join(historyDir, now.UTC().Format(snapshotTimeFormat)+".yaml")
+	if err = os.WriteFile(snapshotPath, effective, 0o600); err != nil {
+		return fmt.Errorf("failed to write config snapshot %q: %w", snapshotPath, err)
+	}
+
+	snapshots, err = ConfigHistory(paths)
+	if err != nil {
+		return err
+	}
+	for _, snapshot := range snapshots[min(limit, len(snapshots)):] {
+		if err = os.Remove(snapshot.Path); err != nil {
+			return fmt.Errorf("failed to remove config snapshot %q: %w", snapshot.Path, err)
+		}
+	}
+	return nil
+}
+
+// ConfigHistory returns the recorded effective configs, the most recent first.
+func ConfigHistory(paths Paths) ([]ConfigSnapshot, error) {
+	historyDir := configHistoryDir(paths)
+	entries, err := os.ReadDir(historyDir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read config history directory %q: %w", historyDir, err)
+	}
+
+	var snapshots []ConfigSnapshot
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".yaml")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		snapshotTime, err := time.Parse(snapshotTimeFormat, name)
+		if err != nil {
+			continue
+		}
+		snapshots = append(snapshots, ConfigSnapshot{
+			Time: snapshotTime,
+			Name: name,
+			Path: filepath.Join(historyDir, entry.Name()),
+		})
+	}
+	slices.SortFunc(snapshots, func(a, b ConfigSnapshot) int {
+		return b.Time.Compare(a.Time)
+	})
+	return snapshots, nil
+}
+
+// Rollback makes the supervisor run the collector with the recorded effective
+// config instead of the local configs on its next start. The remote config it
+// persisted is removed so it isn't applied again until the server sends one.
+func Rollback(paths Paths, name string) error {
+	snapshots, err := ConfigHistory(paths)
+	if err != nil {
+		return err
+	}
+	index := slices.IndexFunc(snapshots, func(snapshot ConfigSnapshot) bool {
+		return snapshot.Name == name
+	})
+	if index < 0 {
+		return fmt.Errorf("config snapshot %q not found in %q", name, configHistoryDir(paths))
+	}
+
+	config, err := os.ReadFile(snapshots[index].Path)
+	if err != nil {
+		return fmt.Errorf("failed to read config snapshot %q: %w", snapshots[index].Path, err)
+	}
+	if err = os.WriteFile(rollbackConfigPath(paths), config, 0o600); err != nil {
+		return fmt.Errorf("failed to write rollback config: %w", err)
+	}
+
+	remoteConfig := filepath.Join(configuredStorageDirectory(paths), supervisorRemoteConfigFileName)
+	if err = os.Remove(remoteConfig); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove the supervisor remote config %q: %w", remoteConfig, err)
+	}
+	return nil
+}
+
+// ClearRollback makes the supervisor run the collector with the local configs again.
+func ClearRollback(paths Paths) error {
+	if err := os.Remove(rollbackConfigPath(paths)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove rollback config: %w", err)
+	}
+	return nil
+}
+
+// loadOptionalCollectorConfig loads the collector config at path, if it exists.
+func loadOptionalCollectorConfig(path string) (*collectorConfigInput, error) {
+	if path == "" {
+		return nil, nil
+	}
+	if _, err := os.Stat(path); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to access collector config %q: %w", path, err)
+	}
+	config, err := loadCollectorConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &collectorConfigInput{Path: path, Config: config}, nil
+}
@@ -0,0 +1,164 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package launcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeEffectiveConfig(t *testing.T, storageDir, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(storageDir, 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(storageDir, supervisorEffectiveConfigFileName), []byte(content), 0o600))
+}
+
+func TestConfigHistoryLimit(t *testing.T) {
+	limit, err := configHistoryLimit(map[string]string{})
+	require.NoError(t, err)
+	assert.Equal(t, defaultConfigHistory, limit)
+
+	limit, err = configHistoryLimit(map[string]string{ConfigHistoryEnvVar: "0"})
+	require.NoError(t, err)
+	assert.Zero(t, limit)
+
+	_, err = configHistoryLimit(map[string]string{ConfigHistoryEnvVar: "-1"})
+	require.EqualError(t, err, `SPLUNK_OPAMP_SUPERVISOR_CONFIG_HISTORY must be a non-negative integer, got "-1"`)
+}
+
+func TestRecordEffectiveConfig(t *testing.T) {
+	paths := testPaths(t.TempDir())
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	require.NoError(t, recordEffectiveConfig(paths.StorageDirectory, paths, 2, start))
+	snapshots, err := ConfigHistory(paths)
+	require.NoError(t, err)
+	assert.Empty(t, snapshots)
+
+	writeEffectiveConfig(t, paths.StorageDirectory, "receivers: {a: {}}")
+	require.NoError(t, recordEffectiveConfig(paths.StorageDirectory, paths, 2, start))
+	// An unchanged effective config isn't recorded again.
+	require.NoError(t, recordEffectiveConfig(paths.StorageDirectory, paths, 2, start.Add(time.Minute)))
+	writeEffectiveConfig(t, paths.StorageDirectory, "receivers: {b: {}}")
+	require.NoError(t, recordEffectiveConfig(paths.StorageDirectory, paths, 2, start.Add(2*time.Minute)))
+	writeEffectiveConfig(t, paths.StorageDirectory, "receivers: {c: {}}")
+	require.NoError(t, recordEffectiveConfig(paths.StorageDirectory, paths, 2, start.Add(3*time.Minute)))
+
+	snapshots, err = ConfigHistory(paths)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 2)
+	assert.Equal(t, "20260102T030705.000Z", snapshots[0].Name)
+	assert.Equal(t, start.Add(3*time.Minute), snapshots[0].Time)
+	assert.Equal(t, "receivers: {c: {}}", readFile(t, snapshots[0].Path))
+	assert.Equal(t, "20260102T030605.000Z", snapshots[1].Name)
+	assert.Equal(t, "receivers: {b: {}}", readFile(t, snapshots[1].Path))
+}
+
+func TestRecordEffectiveConfigDisabled(t *testing.T) {
+	paths := testPaths(t.TempDir())
+	writeEffectiveConfig(t, paths.StorageDirectory, "receivers: {a: {}}")
+	require.NoError(t, recordEffectiveConfig(paths.StorageDirectory, paths, 0, time.Now()))
+	assert.NoDirExists(t, filepath.Join(paths.StorageDirectory, configHistoryDirName))
+}
+
+func TestRollback(t *testing.T) {
+	dir := t.TempDir()
+	paths := testPaths(dir)
+	supervisorStorage := filepath.Join(dir, "custom-storage")
+	require.NoError(t, os.MkdirAll(filepath.Dir(paths.SupervisorConfig), 0o700))
+	require.NoError(t, os.WriteFile(paths.SupervisorConfig, []byte("storage:\n  directory: "+supervisorStorage+"\nagent: {}\n"), 0o600))
+	writeEffectiveConfig(t, supervisorStorage, "receivers: {a: {}}")
+	remoteConfig := filepath.Join(supervisorStorage, supervisorRemoteConfigFileName)
+	require.NoError(t, os.WriteFile(remoteConfig, []byte("remote"), 0o600))
+	require.NoError(t, recordEffectiveConfig(supervisorStorage, paths, 5, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)))
+
+	require.EqualError(t, Rollback(paths, "missing"),
+		`config snapshot "missing" not found in "`+filepath.Join(paths.StorageDirectory, configHistoryDirName)+`"`)
+
+	require.NoError(t, Rollback(paths, "20260102T030405.000Z"))
+	assert.Equal(t, "receivers: {a: {}}", readFile(t, filepath.Join(paths.StorageDirectory, rollbackConfigFileName)))
+	assert.NoFileExists(t, remoteConfig)
+
+	configFiles, _, err := agentConfigFiles([]collectorConfigInput{{Path: "/etc/otel/collector/agent_config.yaml"}}, paths)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(paths.StorageDirectory, rollbackConfigFileName)}, configFiles)
+
+	require.NoError(t, ClearRollback(paths))
+	require.NoError(t, ClearRollback(paths))
+	configFiles, _, err = agentConfigFiles([]collectorConfigInput{{Path: "/etc/otel/collector/agent_config.yaml"}}, paths)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/etc/otel/collector/agent_config.yaml"}, configFiles)
+}
+
+func TestPrepareSupervisorMergesPinnedConfigLast(t *testing.T) {
+	dir := t.TempDir()
+	paths := testPaths(dir)
+	configPath := filepath.Join(dir, "collector.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`service: {}`), 0o600))
+	require.NoError(t, os.MkdirAll(filepath.Dir(paths.PinnedCollectorConfig), 0o700))
+	require.NoError(t, os.WriteFile(paths.PinnedCollectorConfig, []byte(`
+exporters:
+  splunk_hec/audit:
+    endpoint: https://localhost:8088/services/collector
+service:
+  pipelines:
+    logs/audit:
+      receivers: [filelog/audit]
+      exporters: [splunk_hec/audit]
+`), 0o600))
+	writeEffectiveConfig(t, paths.StorageDirectory, "service: {}")
+
+	err := prepareSupervisor(
+		supervisorInputs{configFiles: []string{configPath}},
+		map[string]string{IngestURLEnvVar: "https://ingest.example"},
+		paths,
+	)
+	require.NoError(t, err)
+
+	var runtimeConfig SupervisorConfig
+	readYAML(t, paths.RuntimeSupervisorConfig, &runtimeConfig)
+	assert.Equal(t, []string{
+		ownTelemetryConfigFile,
+		configPath,
+		opampExtensionConfigFile,
+		remoteConfigFile,
+		paths.PinnedCollectorConfig,
+	}, runtimeConfig.Agent.ConfigFiles)
+
+	snapshots, err := ConfigHistory(paths)
+	require.NoError(t, err)
+	assert.Len(t, snapshots, 1)
+}
+
+func TestPrepareSupervisorReturnsInvalidPinnedConfigErrors(t *testing.T) {
+	dir := t.TempDir()
+	paths := testPaths(dir)
+	configPath := filepath.Join(dir, "collector.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`service: {}`), 0o600))
+	require.NoError(t, os.MkdirAll(filepath.Dir(paths.PinnedCollectorConfig), 0o700))
+	require.NoError(t, os.WriteFile(paths.PinnedCollectorConfig, []byte(`service: [`), 0o600))
+
+	err := prepareSupervisor(
+		supervisorInputs{configFiles: []string{configPath}},
+		map[string]string{IngestURLEnvVar: "https://ingest.example"},
+		paths,
+	)
+	require.ErrorContains(t, err, `failed to parse collector config "`+paths.PinnedCollectorConfig+`"`)
+}
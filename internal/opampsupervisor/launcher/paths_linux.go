@@ -44,6 +44,7 @@ func pathsForExecutable(launcherExecutable string) Paths {
 		SupervisorConfig:            filepath.Join(supervisorConfigDir, "supervisor_config.yaml"),
 		RuntimeSupervisorConfig:     filepath.Join(supervisorConfigDir, "supervisor_runtime_config.yaml"),
 		GeneratedCollectorConfigDir: supervisorConfigDir,
+		PinnedCollectorConfig:       filepath.Join(supervisorConfigDir, "pinned_config.yaml"),
 		StorageDirectory:            "/var/lib/otelcol/supervisor",
 		DefaultAgentConfig:          filepath.Join(configDir, "agent_config.yaml"),
 		// Allow the collector to start before a SIGHUP reload when the supervisor
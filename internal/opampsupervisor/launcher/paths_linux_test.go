@@ -39,6 +39,7 @@ func TestPathsForExecutable(t *testing.T) {
 				SupervisorConfig:            "/etc/otel/collector/supervisor/supervisor_config.yaml",
 				RuntimeSupervisorConfig:     "/etc/otel/collector/supervisor/supervisor_runtime_config.yaml",
 				GeneratedCollectorConfigDir: "/etc/otel/collector/supervisor",
+				PinnedCollectorConfig:       "/etc/otel/collector/supervisor/pinned_config.yaml",
 				StorageDirectory:            "/var/lib/otelcol/supervisor",
 				DefaultAgentConfig:          "/etc/otel/collector/agent_config.yaml",
 				BootstrapTimeout:            "1m",
@@ -55,6 +56,7 @@ func TestPathsForExecutable(t *testing.T) {
 				SupervisorConfig:            filepath.Join(bundleDir, "config", "supervisor", "supervisor_config.yaml"),
 				RuntimeSupervisorConfig:     filepath.Join(bundleDir, "config", "supervisor", "supervisor_runtime_config.yaml"),
 				GeneratedCollectorConfigDir: filepath.Join(bundleDir, "config", "supervisor"),
+				PinnedCollectorConfig:       filepath.Join(bundleDir, "config", "supervisor", "pinned_config.yaml"),
 				StorageDirectory:            "/var/lib/otelcol/supervisor",
 				DefaultAgentConfig:          filepath.Join(bundleDir, "config", "agent_config.yaml"),
 				BootstrapTimeout:            "1m",
@@ -41,6 +41,7 @@ func DefaultPaths() Paths {
 		SupervisorConfig:            filepath.Join(stateDir, "supervisor_config.yaml"),
 		RuntimeSupervisorConfig:     filepath.Join(stateDir, "supervisor_runtime_config.yaml"),
 		GeneratedCollectorConfigDir: stateDir,
+		PinnedCollectorConfig:       filepath.Join(stateDir, "pinned_config.yaml"),
 		StorageDirectory:            stateDir,
 		DefaultAgentConfig:          defaultAgentConfig,
 		BootstrapTimeout:            "15s",
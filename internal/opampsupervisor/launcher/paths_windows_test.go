@@ -37,6 +37,7 @@ func TestDefaultPathsWindowsFallbacksAlignWithInstaller(t *testing.T) {
 	assert.Equal(t, filepath.Join(stateDir, "supervisor_config.yaml"), paths.SupervisorConfig)
 	assert.Equal(t, filepath.Join(stateDir, "supervisor_runtime_config.yaml"), paths.RuntimeSupervisorConfig)
 	assert.Equal(t, stateDir, paths.GeneratedCollectorConfigDir)
+	assert.Equal(t, filepath.Join(stateDir, "pinned_config.yaml"), paths.PinnedCollectorConfig)
 	assert.Equal(t, stateDir, paths.StorageDirectory)
 	assert.Equal(t, filepath.Join(`\ProgramData`, "Splunk", "OpenTelemetry Collector", "agent_config.yaml"), paths.DefaultAgentConfig)
 	assert.Equal(t, "15s", paths.BootstrapTimeout)
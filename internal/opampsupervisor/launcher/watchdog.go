@@ -0,0 +1,463 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package launcher
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	WatchdogEnabledEnvVar       = "SPLUNK_LAUNCHER_WATCHDOG_ENABLED"
+	WatchdogMaxFailuresEnvVar   = "SPLUNK_LAUNCHER_WATCHDOG_MAX_FAILURES"
+	WatchdogFailureWindowEnvVar = "SPLUNK_LAUNCHER_WATCHDOG_FAILURE_WINDOW"
+
+	// FallbackMarkerFileName is the name of the marker file written to the supervisor
+	// storage directory when the launcher falls back to direct mode.
+	FallbackMarkerFileName = "launcher_fallback.yaml"
+
+	defaultWatchdogMaxFailures   = 5
+	defaultWatchdogFailureWindow = 10 * time.Minute
+	defaultWatchdogRestartDelay  = time.Second
+	// defaultConfigHistoryInterval is how often the effective config applied by a
+	// running supervisor is checked for the config history.
+	defaultConfigHistoryInterval = 30 * time.Second
+	// defaultCollectorCheckInterval is how often the collectors run by a running
+	// supervisor are checked for restarts.
+	defaultCollectorCheckInterval = 5 * time.Second
+)
+
+// errCollectorRestarts is the failure of a supervisor stopped by the watchdog
+// for restarting the collector too many times.
+var errCollectorRestarts = errors.New("the supervisor kept restarting the collector")
+
+// WatchdogSettings configures how the launcher supervises its child process.
+type WatchdogSettings struct {
+	// MaxFailures is the number of child exits and collector restarts by the
+	// supervisor within FailureWindow after which the launcher falls back to
+	// direct mode, or gives up if already in direct mode.
+	MaxFailures            int
+	FailureWindow          time.Duration
+	RestartDelay           time.Duration
+	ConfigHistoryInterval  time.Duration
+	CollectorCheckInterval time.Duration
+}
+
+// FallbackMarker is the content of the marker file written when the launcher
+// falls back to direct mode, for the supervisor to report on its next start.
+type FallbackMarker struct {
+	Time          time.Time `yaml:"time"`
+	Command       string    `yaml:"command"`
+	LastError     string    `yaml:"last_error"`
+	FallbackPath  string    `yaml:"fallback_config"`
+	FailureWindow string    `yaml:"failure_window"`
+	Failures      int       `yaml:"failures"`
+}
+
+// Child is a process started by the watchdog.
+type Child interface {
+	Pid() int
+	Signal(sig os.Signal) error
+	Wait() error
+}
+
+// WatchdogEnabled reports whether the persisted service-scoped watchdog switch
+// is enabled in the provided environment.
+func WatchdogEnabled(env map[string]string) bool {
+	return strings.EqualFold(strings.TrimSpace(env[WatchdogEnabledEnvVar]), "true")
+}
+
+// WatchdogSettingsFromEnv returns the watchdog settings, applying the defaults
+// of the settings not set in the provided environment.
+func WatchdogSettingsFromEnv(env map[string]string) (WatchdogSettings, error) {
+	settings := WatchdogSettings{
+		MaxFailures:            defaultWatchdogMaxFailures,
+		FailureWindow:          defaultWatchdogFailureWindow,
+		RestartDelay:           defaultWatchdogRestartDelay,
+		ConfigHistoryInterval:  defaultConfigHistoryInterval,
+		CollectorCheckInterval: defaultCollectorCheckInterval,
+	}
+	if value := strings.TrimSpace(env[WatchdogMaxFailuresEnvVar]); value != "" {
+		maxFailures, err := strconv.Atoi(value)
+		if err != nil || maxFailures < 1 {
+			return WatchdogSettings{}, fmt.Errorf("%s must be a positive integer, got %q", WatchdogMaxFailuresEnvVar, value)
+		}
+		settings.MaxFailures = maxFailures
+	}
+	if value := strings.TrimSpace(env[WatchdogFailureWindowEnvVar]); value != "" {
+		window, err := time.ParseDuration(value)
+		if err != nil || window <= 0 {
+			return WatchdogSettings{}, fmt.Errorf("%s must be a positive duration, got %q", WatchdogFailureWindowEnvVar, value)
+		}
+		settings.FailureWindow = window
+	}
+	return settings, nil
+}
+
+// FallbackCommand builds the direct mode command running the collector with the
+// local default agent config, keeping the other collector command-line arguments.
+func FallbackCommand(args, environ []string, paths Paths) (Command, error) {
+	env := environToMap(environ)
+	env[CollectorConfigEnvVar] = paths.DefaultAgentConfig
+	inputs, err := supervisorInputsFromArgs(args, env)
+	if err != nil {
+		return Command{}, err
+	}
+
+	fallbackEnviron := make([]string, 0, len(environ)+1)
+	for _, entry := range environ {
+		if name, _, _ := strings.Cut(entry, "="); name == CollectorConfigEnvVar {
+			continue
+		}
+		fallbackEnviron = append(fallbackEnviron, entry)
+	}
+	fallbackEnviron = append(fallbackEnviron, CollectorConfigEnvVar+"="+paths.DefaultAgentConfig)
+
+	return Command{
+		Path: paths.CollectorExecutable,
+		Args: append([]string{"--config", paths.DefaultAgentConfig}, inputs.agentArgs...),
+		Env:  supervisorCommandEnv(fallbackEnviron, env, paths, []string{paths.DefaultAgentConfig}),
+	}, nil
+}
+
+// Watchdog runs the launcher child process instead of replacing the launcher
+// with it, restarting it when it exits without being asked to. A child failing
+// MaxFailures times within FailureWindow is replaced by the Fallback command,
+// when there is one, so a crash-looping supervisor doesn't keep the host
+// without a collector. While the supervisor runs, the collectors it runs are
+// listed by Collectors every CollectorCheckInterval: a collector restarted
+// without a new effective config in SupervisorStorage, e.g. crashing with a
+// bad remote config, is a failure too, and the supervisor is stopped when
+// falling back. The effective configs it applies are recorded by RecordConfig
+// every ConfigHistoryInterval and when it exits.
+type Watchdog struct {
+	Start             func(Command) (Child, error)
+	Now               func() time.Time
+	RecordConfig      func(time.Time) error
+	Collectors        func(supervisorPID int) ([]int, error)
+	Logger            *log.Logger
+	Fallback          *Command
+	SupervisorStorage string
+	Paths             Paths
+	Settings          WatchdogSettings
+}
+
+// NewWatchdog returns the watchdog configured by the provided environment, or
+// nil if it isn't enabled. In supervisor mode its fallback is FallbackCommand,
+// it checks the collector restarts where supported and records the config history.
+func NewWatchdog(args, environ []string, paths Paths, start func(Command) (Child, error)) (*Watchdog, error) {
+	env := environToMap(environ)
+	if !WatchdogEnabled(env) {
+		return nil, nil
+	}
+	settings, err := WatchdogSettingsFromEnv(env)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Watchdog{
+		Start:    start,
+		Now:      time.Now,
+		Logger:   log.Default(),
+		Paths:    paths,
+		Settings: settings,
+	}
+	if SupervisorEnabled(env) {
+		fallback, err := FallbackCommand(args, environ, paths)
+		if err != nil {
+			return nil, fmt.Errorf("failed to prepare the fallback command: %w", err)
+		}
+		w.Fallback = &fallback
+		w.Collectors = childProcesses
+		w.SupervisorStorage = configuredStorageDirectory(paths)
+		if w.RecordConfig, err = configHistoryRecorder(env, paths); err != nil {
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+// Run runs cmd until a signal other than SIGHUP is received, which is forwarded
+// to the child before waiting for it to exit. SIGHUP is forwarded to the child
+// for config reloads.
+func (w *Watchdog) Run(cmd Command, signals <-chan os.Signal) error {
+	fallback := w.Fallback
+	current := cmd
+	fallenBack := false
+	var failures []time.Time
+
+	for {
+		child, err := w.Start(current)
+		if err != nil {
+			return fmt.Errorf("failed to start %q: %w", current.Path, err)
+		}
+		stopped, exitErr := w.wait(child, current, fallback != nil && !fallenBack, signals, &failures)
+		if stopped {
+			return ignoreSignaledExit(exitErr)
+		}
+
+		now := w.Now()
+		if !errors.Is(exitErr, errCollectorRestarts) {
+			failures = append(w.recentFailures(failures, now), now)
+			w.Logger.Printf("%q exited unexpectedly (%d failures within %s): %v",
+				current.Path, len(failures), w.Settings.FailureWindow, exitErr)
+		}
+
+		if len(failures) >= w.Settings.MaxFailures {
+			if fallback == nil || fallenBack {
+				return fmt.Errorf("%q failed %d times within %s: %w", current.Path, len(failures), w.Settings.FailureWindow, exitErr)
+			}
+			w.writeFallbackMarker(FallbackMarker{
+				Time:          now,
+				Command:       current.Path,
+				LastError:     fmt.Sprint(exitErr),
+				FallbackPath:  w.Paths.DefaultAgentConfig,
+				FailureWindow: w.Settings.FailureWindow.String(),
+				Failures:      len(failures),
+			})
+			w.Logger.Printf("falling back to direct mode with %q", w.Paths.DefaultAgentConfig)
+			current = *fallback
+			fallenBack = true
+			failures = nil
+			continue
+		}
+
+		select {
+		case sig := <-signals:
+			if sig != syscall.SIGHUP {
+				return nil
+			}
+		case <-time.After(w.Settings.RestartDelay):
+		}
+	}
+}
+
+// wait waits for the child to exit, forwarding the signals to it. It reports
+// whether the child exited after being asked to shut down. The collector
+// restarts by a supervising child are added to the failures, and the child is
+// stopped with errCollectorRestarts once they reach MaxFailures.
+func (w *Watchdog) wait(child Child, cmd Command, supervising bool, signals <-chan os.Signal, failures *[]time.Time) (bool, error) {
+	exited := make(chan error, 1)
+	go func() { exited <- child.Wait() }()
+
+	// A marker left by a previous fallback is removed once the child ran
+	// without failing for a whole window, after it had the time to report it.
+	var markerTimer <-chan time.Time
+	if supervising && w.fallbackMarkerExists() {
+		timer := time.NewTimer(w.Settings.FailureWindow)
+		defer timer.Stop()
+		markerTimer = timer.C
+	}
+	var historyTicker <-chan time.Time
+	if supervising && w.RecordConfig != nil {
+		ticker := time.NewTicker(w.Settings.ConfigHistoryInterval)
+		defer ticker.Stop()
+		historyTicker = ticker.C
+		// The last effective config applied before an exit, e.g. the one crashing
+		// the supervisor, is recorded too.
+		defer w.recordConfig()
+	}
+	var collectorTicker <-chan time.Time
+	var collectors collectorState
+	if supervising && w.Collectors != nil {
+		ticker := time.NewTicker(w.Settings.CollectorCheckInterval)
+		defer ticker.Stop()
+		collectorTicker = ticker.C
+	}
+
+	for {
+		select {
+		case sig := <-signals:
+			if err := child.Signal(sig); err != nil {
+				w.Logger.Printf("failed to forward signal %v to %q: %v", sig, cmd.Path, err)
+			}
+			if sig != syscall.SIGHUP {
+				return true, <-exited
+			}
+		case <-markerTimer:
+			w.removeFallbackMarker()
+		case <-historyTicker:
+			w.recordConfig()
+		case <-collectorTicker:
+			if !w.collectorRestarted(child, &collectors) {
+				continue
+			}
+			now := w.Now()
+			*failures = append(w.recentFailures(*failures, now), now)
+			w.Logger.Printf("%q restarted the collector (%d failures within %s)",
+				cmd.Path, len(*failures), w.Settings.FailureWindow)
+			if len(*failures) >= w.Settings.MaxFailures {
+				return w.stop(child, cmd, signals, exited)
+			}
+		case err := <-exited:
+			return false, err
+		}
+	}
+}
+
+// stop asks the child to shut down for the watchdog to fall back, forwarding
+// the signals received meanwhile. It reports whether a shutdown was requested
+// meanwhile, errCollectorRestarts being returned otherwise.
+func (w *Watchdog) stop(child Child, cmd Command, signals <-chan os.Signal, exited <-chan error) (bool, error) {
+	if err := child.Signal(syscall.SIGTERM); err != nil {
+		w.Logger.Printf("failed to stop %q: %v", cmd.Path, err)
+	}
+	for {
+		select {
+		case sig := <-signals:
+			if err := child.Signal(sig); err != nil {
+				w.Logger.Printf("failed to forward signal %v to %q: %v", sig, cmd.Path, err)
+			}
+			if sig != syscall.SIGHUP {
+				return true, <-exited
+			}
+		case <-exited:
+			return false, errCollectorRestarts
+		}
+	}
+}
+
+// collectorState is the collectors last seen running under the supervisor and
+// the effective config when they were first seen.
+type collectorState struct {
+	pids   []int
+	config []byte
+}
+
+// collectorRestarted reports whether the supervisor runs another collector
+// than at the last check without having applied a new effective config since,
+// i.e. restarted it after it exited. Restarts applying remote configs aren't
+// failures.
+func (w *Watchdog) collectorRestarted(child Child, state *collectorState) bool {
+	pids, err := w.Collectors(child.Pid())
+	if err != nil {
+		w.Logger.Printf("failed to list the collectors run by the supervisor: %v", err)
+		return false
+	}
+	started := slices.ContainsFunc(pids, func(pid int) bool {
+		return !slices.Contains(state.pids, pid)
+	})
+	if !started {
+		return false
+	}
+	config, err := os.ReadFile(filepath.Join(w.SupervisorStorage, supervisorEffectiveConfigFileName))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		w.Logger.Printf("failed to read the supervisor effective config: %v", err)
+		return false
+	}
+	restarted := state.pids != nil && bytes.Equal(config, state.config)
+	state.pids, state.config = pids, config
+	return restarted
+}
+
+// recordConfig failures are only logged since the history is only a convenience
+// for rollbacks.
+func (w *Watchdog) recordConfig() {
+	if err := w.RecordConfig(w.Now()); err != nil {
+		w.Logger.Printf("failed to record the supervisor effective config: %v", err)
+	}
+}
+
+func (w *Watchdog) recentFailures(failures []time.Time, now time.Time) []time.Time {
+	recent := failures[:0]
+	for _, failure := range failures {
+		if now.Sub(failure) < w.Settings.FailureWindow {
+			recent = append(recent, failure)
+		}
+	}
+	return recent
+}
+
+func (w *Watchdog) fallbackMarkerPath() string {
+	return fallbackMarkerPath(w.Paths)
+}
+
+func fallbackMarkerPath(paths Paths) string {
+	return filepath.Join(paths.StorageDirectory, FallbackMarkerFileName)
+}
+
+// fallbackAttributes returns the agent description attributes reporting the
+// last fallback to direct mode, if any, for the supervisor to send to the server.
+func fallbackAttributes(paths Paths) (map[string]string, error) {
+	bytes, err := os.ReadFile(fallbackMarkerPath(paths))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read fallback marker: %w", err)
+	}
+	var marker FallbackMarker
+	if err = yaml.Unmarshal(bytes, &marker); err != nil {
+		return nil, fmt.Errorf("failed to parse fallback marker %q: %w", fallbackMarkerPath(paths), err)
+	}
+	return map[string]string{
+		"splunk.launcher.fallback.time":       marker.Time.UTC().Format(time.RFC3339),
+		"splunk.launcher.fallback.failures":   strconv.Itoa(marker.Failures),
+		"splunk.launcher.fallback.last_error": marker.LastError,
+	}, nil
+}
+
+// writeFallbackMarker failures are only logged since falling back is more
+// important than reporting it.
+func (w *Watchdog) writeFallbackMarker(marker FallbackMarker) {
+	path := w.fallbackMarkerPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		w.Logger.Printf("failed to create fallback marker directory: %v", err)
+		return
+	}
+	bytes, err := yaml.Marshal(marker)
+	if err == nil {
+		err = os.WriteFile(path, bytes, 0o600)
+	}
+	if err != nil {
+		w.Logger.Printf("failed to write fallback marker %q: %v", path, err)
+	}
+}
+
+func (w *Watchdog) fallbackMarkerExists() bool {
+	_, err := os.Stat(w.fallbackMarkerPath())
+	return err == nil
+}
+
+func (w *Watchdog) removeFallbackMarker() {
+	if err := os.Remove(w.fallbackMarkerPath()); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		w.Logger.Printf("failed to remove fallback marker %q: %v", w.fallbackMarkerPath(), err)
+	}
+}
+
+// ignoreSignaledExit ignores the error of a child terminated by the forwarded
+// shutdown signal, like systemd does for the main process of a service.
+func ignoreSignaledExit(err error) error {
+	var exitErr interface{ Sys() any }
+	if !errors.As(err, &exitErr) {
+		return err
+	}
+	if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return nil
+	}
+	return err
+}
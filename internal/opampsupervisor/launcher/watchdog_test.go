@@ -0,0 +1,442 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package launcher
+
+import (
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeChild struct {
+	exit    chan error
+	lock    sync.Mutex
+	signals []os.Signal
+}
+
+func newFakeChild() *fakeChild {
+	return &fakeChild{exit: make(chan error, 1)}
+}
+
+func (c *fakeChild) Pid() int {
+	return 1
+}
+
+func (c *fakeChild) Signal(sig os.Signal) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.signals = append(c.signals, sig)
+	if sig != syscall.SIGHUP {
+		c.exit <- nil
+	}
+	return nil
+}
+
+func (c *fakeChild) Wait() error {
+	return <-c.exit
+}
+
+func (c *fakeChild) receivedSignals() []os.Signal {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]os.Signal(nil), c.signals...)
+}
+
+type fakeStarter struct {
+	started  chan *fakeChild
+	lock     sync.Mutex
+	commands []Command
+}
+
+func newFakeStarter() *fakeStarter {
+	return &fakeStarter{started: make(chan *fakeChild, 100)}
+}
+
+func (s *fakeStarter) start(cmd Command) (Child, error) {
+	s.lock.Lock()
+	s.commands = append(s.commands, cmd)
+	s.lock.Unlock()
+	child := newFakeChild()
+	s.started <- child
+	return child, nil
+}
+
+func (s *fakeStarter) startedCommands() []Command {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]Command(nil), s.commands...)
+}
+
+func testWatchdog(t *testing.T, starter *fakeStarter, now func() time.Time) *Watchdog {
+	return &Watchdog{
+		Start:  starter.start,
+		Now:    now,
+		Logger: log.New(io.Discard, "", 0),
+		Paths:  testPaths(t.TempDir()),
+		Settings: WatchdogSettings{
+			MaxFailures:   3,
+			FailureWindow: time.Minute,
+		},
+	}
+}
+
+func TestWatchdogEnabled(t *testing.T) {
+	assert.False(t, WatchdogEnabled(map[string]string{}))
+	assert.False(t, WatchdogEnabled(map[string]string{WatchdogEnabledEnvVar: "false"}))
+	assert.True(t, WatchdogEnabled(map[string]string{WatchdogEnabledEnvVar: " TRUE "}))
+}
+
+func TestWatchdogSettingsFromEnv(t *testing.T) {
+	settings, err := WatchdogSettingsFromEnv(map[string]string{})
+	require.NoError(t, err)
+	assert.Equal(t, WatchdogSettings{
+		MaxFailures:            defaultWatchdogMaxFailures,
+		FailureWindow:          defaultWatchdogFailureWindow,
+		RestartDelay:           defaultWatchdogRestartDelay,
+		ConfigHistoryInterval:  defaultConfigHistoryInterval,
+		CollectorCheckInterval: defaultCollectorCheckInterval,
+	}, settings)
+
+	settings, err = WatchdogSettingsFromEnv(map[string]string{
+		WatchdogMaxFailuresEnvVar:   "2",
+		WatchdogFailureWindowEnvVar: "30s",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, settings.MaxFailures)
+	assert.Equal(t, 30*time.Second, settings.FailureWindow)
+
+	_, err = WatchdogSettingsFromEnv(map[string]string{WatchdogMaxFailuresEnvVar: "0"})
+	require.EqualError(t, err, `SPLUNK_LAUNCHER_WATCHDOG_MAX_FAILURES must be a positive integer, got "0"`)
+	_, err = WatchdogSettingsFromEnv(map[string]string{WatchdogFailureWindowEnvVar: "soon"})
+	require.EqualError(t, err, `SPLUNK_LAUNCHER_WATCHDOG_FAILURE_WINDOW must be a positive duration, got "soon"`)
+}
+
+func TestNewWatchdog(t *testing.T) {
+	paths := testPaths(t.TempDir())
+	w, err := NewWatchdog(nil, []string{"A=B"}, paths, nil)
+	require.NoError(t, err)
+	assert.Nil(t, w)
+
+	w, err = NewWatchdog(nil, []string{WatchdogEnabledEnvVar + "=true", WatchdogMaxFailuresEnvVar + "=2"}, paths, nil)
+	require.NoError(t, err)
+	require.NotNil(t, w)
+	assert.Equal(t, 2, w.Settings.MaxFailures)
+	assert.Nil(t, w.Fallback)
+	assert.Nil(t, w.RecordConfig)
+	assert.Nil(t, w.Collectors)
+
+	w, err = NewWatchdog(nil, []string{WatchdogEnabledEnvVar + "=true", SupervisorEnabledEnvVar + "=true"}, paths, nil)
+	require.NoError(t, err)
+	require.NotNil(t, w)
+	require.NotNil(t, w.Fallback)
+	assert.Equal(t, []string{"--config", paths.DefaultAgentConfig}, w.Fallback.Args)
+	assert.NotNil(t, w.RecordConfig)
+	assert.Equal(t, paths.StorageDirectory, w.SupervisorStorage)
+
+	w, err = NewWatchdog(nil, []string{WatchdogEnabledEnvVar + "=true", SupervisorEnabledEnvVar + "=true", ConfigHistoryEnvVar + "=0"}, paths, nil)
+	require.NoError(t, err)
+	require.NotNil(t, w)
+	assert.Nil(t, w.RecordConfig)
+
+	_, err = NewWatchdog(nil, []string{WatchdogEnabledEnvVar + "=true", SupervisorEnabledEnvVar + "=true", ConfigHistoryEnvVar + "=all"}, paths, nil)
+	require.EqualError(t, err, `SPLUNK_OPAMP_SUPERVISOR_CONFIG_HISTORY must be a non-negative integer, got "all"`)
+
+	_, err = NewWatchdog(nil, []string{WatchdogEnabledEnvVar + "=true", WatchdogFailureWindowEnvVar + "=-1s"}, paths, nil)
+	require.EqualError(t, err, `SPLUNK_LAUNCHER_WATCHDOG_FAILURE_WINDOW must be a positive duration, got "-1s"`)
+}
+
+func TestFallbackCommand(t *testing.T) {
+	paths := testPaths(t.TempDir())
+	cmd, err := FallbackCommand(
+		[]string{"--config", "/etc/otel/collector/gateway_config.yaml", "--feature-gates=+other.gate", "--discovery"},
+		[]string{SupervisorEnabledEnvVar + "=true", CollectorConfigEnvVar + "=/etc/otel/collector/gateway_config.yaml", "A=B"},
+		paths,
+	)
+	require.NoError(t, err)
+	assert.Equal(t, paths.CollectorExecutable, cmd.Path)
+	assert.Equal(t, []string{"--config", paths.DefaultAgentConfig, "--feature-gates=+other.gate", "--discovery"}, cmd.Args)
+	assert.Equal(t, []string{
+		SupervisorEnabledEnvVar + "=true",
+		"A=B",
+		CollectorConfigEnvVar + "=" + paths.DefaultAgentConfig,
+		ListenInterfaceEnvVar + "=" + defaultAgentListenInterface,
+	}, cmd.Env)
+}
+
+func TestWatchdogFallsBackAfterMaxFailures(t *testing.T) {
+	starter := newFakeStarter()
+	w := testWatchdog(t, starter, time.Now)
+	supervisor := Command{Path: "supervisor"}
+	fallback := Command{Path: "collector"}
+	w.Fallback = &fallback
+
+	errs := make(chan error, 1)
+	go func() { errs <- w.Run(supervisor, make(chan os.Signal)) }()
+
+	for range 3 {
+		(<-starter.started).exit <- errors.New("exit status 1")
+	}
+	for range 3 {
+		(<-starter.started).exit <- errors.New("exit status 2")
+	}
+	require.EqualError(t, <-errs, `"collector" failed 3 times within 1m0s: exit status 2`)
+
+	commands := starter.startedCommands()
+	require.Len(t, commands, 6)
+	for i, cmd := range commands {
+		if i < 3 {
+			assert.Equal(t, supervisor, cmd)
+		} else {
+			assert.Equal(t, fallback, cmd)
+		}
+	}
+
+	attributes, err := fallbackAttributes(w.Paths)
+	require.NoError(t, err)
+	assert.Equal(t, "3", attributes["splunk.launcher.fallback.failures"])
+	assert.Equal(t, "exit status 1", attributes["splunk.launcher.fallback.last_error"])
+	assert.NotEmpty(t, attributes["splunk.launcher.fallback.time"])
+}
+
+func TestWatchdogWithoutFallbackReturnsAfterMaxFailures(t *testing.T) {
+	starter := newFakeStarter()
+	w := testWatchdog(t, starter, time.Now)
+
+	errs := make(chan error, 1)
+	go func() { errs <- w.Run(Command{Path: "collector"}, make(chan os.Signal)) }()
+	for range 3 {
+		(<-starter.started).exit <- errors.New("exit status 1")
+	}
+	require.EqualError(t, <-errs, `"collector" failed 3 times within 1m0s: exit status 1`)
+	assert.NoFileExists(t, filepath.Join(w.Paths.StorageDirectory, FallbackMarkerFileName))
+}
+
+func TestWatchdogForgetsFailuresOutsideWindow(t *testing.T) {
+	starter := newFakeStarter()
+	now := time.Now()
+	w := testWatchdog(t, starter, func() time.Time {
+		now = now.Add(40 * time.Second)
+		return now
+	})
+	w.Fallback = &Command{Path: "collector"}
+	supervisor := Command{Path: "supervisor"}
+
+	signals := make(chan os.Signal, 1)
+	errs := make(chan error, 1)
+	go func() { errs <- w.Run(supervisor, signals) }()
+	for range 5 {
+		(<-starter.started).exit <- errors.New("exit status 1")
+	}
+	child := <-starter.started
+	signals <- syscall.SIGTERM
+	require.NoError(t, <-errs)
+
+	assert.Equal(t, []os.Signal{syscall.SIGTERM}, child.receivedSignals())
+	for _, cmd := range starter.startedCommands() {
+		assert.Equal(t, supervisor, cmd)
+	}
+}
+
+func TestWatchdogForwardsSignals(t *testing.T) {
+	starter := newFakeStarter()
+	w := testWatchdog(t, starter, time.Now)
+
+	signals := make(chan os.Signal)
+	errs := make(chan error, 1)
+	go func() { errs <- w.Run(Command{Path: "collector"}, signals) }()
+	child := <-starter.started
+	signals <- syscall.SIGHUP
+	signals <- syscall.SIGINT
+	require.NoError(t, <-errs)
+
+	assert.Equal(t, []os.Signal{syscall.SIGHUP, syscall.SIGINT}, child.receivedSignals())
+	assert.Len(t, starter.startedCommands(), 1)
+}
+
+func TestWatchdogFallsBackAfterCollectorRestarts(t *testing.T) {
+	starter := newFakeStarter()
+	w := testWatchdog(t, starter, time.Now)
+	w.Fallback = &Command{Path: "collector"}
+	w.SupervisorStorage = w.Paths.StorageDirectory
+	w.Settings.CollectorCheckInterval = time.Millisecond
+	checks := make(chan []int)
+	w.Collectors = func(int) ([]int, error) {
+		return <-checks, nil
+	}
+	// Listing the same collectors again makes sure the previous check is done.
+	check := func(pids ...int) {
+		checks <- pids
+		checks <- pids
+	}
+	writeEffectiveConfig(t, w.SupervisorStorage, "receivers: {a: {}}")
+
+	signals := make(chan os.Signal)
+	errs := make(chan error, 1)
+	go func() { errs <- w.Run(Command{Path: "supervisor"}, signals) }()
+	supervisor := <-starter.started
+
+	check(10)
+	// Restarting the collector to apply a new effective config isn't a failure.
+	writeEffectiveConfig(t, w.SupervisorStorage, "receivers: {b: {}}")
+	check(11)
+	check()
+	check(12)
+	check(13)
+	checks <- []int{14}
+
+	fallback := <-starter.started
+	assert.Equal(t, []os.Signal{syscall.SIGTERM}, supervisor.receivedSignals())
+	assert.Equal(t, []Command{{Path: "supervisor"}, {Path: "collector"}}, starter.startedCommands())
+	attributes, err := fallbackAttributes(w.Paths)
+	require.NoError(t, err)
+	assert.Equal(t, "3", attributes["splunk.launcher.fallback.failures"])
+	assert.Equal(t, "the supervisor kept restarting the collector", attributes["splunk.launcher.fallback.last_error"])
+
+	signals <- syscall.SIGTERM
+	require.NoError(t, <-errs)
+	assert.Equal(t, []os.Signal{syscall.SIGTERM}, fallback.receivedSignals())
+}
+
+func TestWatchdogReturnsStartErrors(t *testing.T) {
+	w := testWatchdog(t, newFakeStarter(), time.Now)
+	w.Start = func(Command) (Child, error) {
+		return nil, errors.New("no such file or directory")
+	}
+	err := w.Run(Command{Path: "supervisor"}, make(chan os.Signal))
+	require.EqualError(t, err, `failed to start "supervisor": no such file or directory`)
+}
+
+func TestWatchdogRemovesFallbackMarkerAfterHealthyWindow(t *testing.T) {
+	starter := newFakeStarter()
+	w := testWatchdog(t, starter, time.Now)
+	w.Settings.FailureWindow = 10 * time.Millisecond
+	w.writeFallbackMarker(FallbackMarker{Time: time.Now(), Failures: 3})
+	markerPath := filepath.Join(w.Paths.StorageDirectory, FallbackMarkerFileName)
+	require.FileExists(t, markerPath)
+
+	w.Fallback = &Command{Path: "collector"}
+
+	signals := make(chan os.Signal)
+	errs := make(chan error, 1)
+	go func() { errs <- w.Run(Command{Path: "supervisor"}, signals) }()
+	<-starter.started
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(markerPath)
+		return errors.Is(err, os.ErrNotExist)
+	}, 5*time.Second, 5*time.Millisecond)
+	signals <- syscall.SIGTERM
+	require.NoError(t, <-errs)
+}
+
+func TestWatchdogRecordsEffectiveConfigs(t *testing.T) {
+	starter := newFakeStarter()
+	w := testWatchdog(t, starter, time.Now)
+	w.Fallback = &Command{Path: "collector"}
+	w.Settings.ConfigHistoryInterval = 5 * time.Millisecond
+	recordConfig, err := configHistoryRecorder(map[string]string{}, w.Paths)
+	require.NoError(t, err)
+	w.RecordConfig = recordConfig
+
+	signals := make(chan os.Signal)
+	errs := make(chan error, 1)
+	go func() { errs <- w.Run(Command{Path: "supervisor"}, signals) }()
+	<-starter.started
+
+	// The remote configs applied by the running supervisor are recorded.
+	writeEffectiveConfig(t, w.Paths.StorageDirectory, "receivers: {a: {}}")
+	assert.Eventually(t, func() bool {
+		snapshots, historyErr := ConfigHistory(w.Paths)
+		return historyErr == nil && len(snapshots) == 1
+	}, 5*time.Second, 5*time.Millisecond)
+	writeEffectiveConfig(t, w.Paths.StorageDirectory, "receivers: {b: {}}")
+	assert.Eventually(t, func() bool {
+		snapshots, historyErr := ConfigHistory(w.Paths)
+		return historyErr == nil && len(snapshots) == 2
+	}, 5*time.Second, 5*time.Millisecond)
+
+	signals <- syscall.SIGTERM
+	require.NoError(t, <-errs)
+	snapshots, err := ConfigHistory(w.Paths)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 2)
+	assert.Equal(t, "receivers: {b: {}}", readFile(t, snapshots[0].Path))
+}
+
+func TestWatchdogRecordsEffectiveConfigOnExit(t *testing.T) {
+	starter := newFakeStarter()
+	w := testWatchdog(t, starter, time.Now)
+	w.Fallback = &Command{Path: "collector"}
+	w.Settings.ConfigHistoryInterval = time.Hour
+	recordConfig, err := configHistoryRecorder(map[string]string{}, w.Paths)
+	require.NoError(t, err)
+	w.RecordConfig = recordConfig
+
+	signals := make(chan os.Signal)
+	errs := make(chan error, 1)
+	go func() { errs <- w.Run(Command{Path: "supervisor"}, signals) }()
+	child := <-starter.started
+	writeEffectiveConfig(t, w.Paths.StorageDirectory, "receivers: {a: {}}")
+	child.exit <- errors.New("exit status 1")
+
+	<-starter.started
+	snapshots, err := ConfigHistory(w.Paths)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 1)
+	assert.Equal(t, "receivers: {a: {}}", readFile(t, snapshots[0].Path))
+
+	signals <- syscall.SIGTERM
+	require.NoError(t, <-errs)
+}
+
+func TestPrepareSupervisorReportsFallbackMarker(t *testing.T) {
+	dir := t.TempDir()
+	paths := testPaths(dir)
+	configPath := filepath.Join(dir, "collector.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`service: {}`), 0o600))
+	require.NoError(t, os.MkdirAll(paths.StorageDirectory, 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(paths.StorageDirectory, FallbackMarkerFileName), []byte(`
+time: 2026-01-02T03:04:05Z
+failures: 5
+last_error: exit status 1
+`), 0o600))
+
+	err := prepareSupervisor(
+		supervisorInputs{configFiles: []string{configPath}},
+		map[string]string{IngestURLEnvVar: "https://ingest.example"},
+		paths,
+	)
+	require.NoError(t, err)
+
+	var runtimeConfig map[string]any
+	readYAML(t, paths.RuntimeSupervisorConfig, &runtimeConfig)
+	agent, _ := asMap(runtimeConfig["agent"])
+	description, _ := asMap(agent["description"])
+	attributes, _ := asMap(description["non_identifying_attributes"])
+	assert.Equal(t, map[string]any{
+		"splunk.launcher.fallback.time":       "2026-01-02T03:04:05Z",
+		"splunk.launcher.fallback.failures":   "5",
+		"splunk.launcher.fallback.last_error": "exit status 1",
+	}, attributes)
+	assert.Equal(t, true, description["include_resource_attributes"])
+}
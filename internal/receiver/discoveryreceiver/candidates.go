@@ -0,0 +1,307 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package discoveryreceiver
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"sync"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/observer"
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+)
+
+// candidateEnvKey is the endpoint env key the internal receiver creator rules use to select the
+// receiver template of the endpoint's current candidate. Its value maps the ID of each receiver
+// with candidates to the receiver's current candidate for the endpoint.
+const candidateEnvKey = "discovery_candidate"
+
+// candidateNameRegexp matches the receiver creator template names of the non-initial receiver candidates,
+// which are of the form `<receiver.type>/(<receiver.name>/)candidate-<index>`.
+var candidateNameRegexp = regexp.MustCompile(`^(?:(.*)/)?candidate-([0-9]+)$`)
+
+// candidateTemplateID returns the receiver creator template ID for the receiver's candidate at the provided index.
+func candidateTemplateID(receiverID component.ID, candidate int) component.ID {
+	if candidate == 0 {
+		return receiverID
+	}
+	name := fmt.Sprintf("candidate-%d", candidate)
+	if receiverID.Name() != "" {
+		name = receiverID.Name() + "/" + name
+	}
+	return component.NewIDWithName(receiverID.Type(), name)
+}
+
+// candidateRule returns the receiver rule that only matches endpoints whose current candidate for the
+// receiver is the provided index.
+func candidateRule(receiverID component.ID, rule Rule, candidate int) string {
+	return fmt.Sprintf("%s and (%s) and %s[%q] == %d", ruleRe.FindString(rule.String()), rule.String(), candidateEnvKey, receiverID.String(), candidate)
+}
+
+// receiverCandidateFromTemplateID returns the configured receiver ID and candidate index of the provided
+// receiver creator template ID. Receivers without candidates are always candidate 0.
+func (cfg *Config) receiverCandidateFromTemplateID(templateID component.ID) (component.ID, int) {
+	matches := candidateNameRegexp.FindStringSubmatch(templateID.Name())
+	if matches == nil {
+		return templateID, 0
+	}
+	receiverID := component.NewIDWithName(templateID.Type(), matches[1])
+	candidate, err := strconv.Atoi(matches[2])
+	if err != nil || candidate >= len(cfg.Receivers[receiverID].Candidates) {
+		return templateID, 0
+	}
+	return receiverID, candidate
+}
+
+func (cfg *Config) hasCandidates() bool {
+	for _, rEntry := range cfg.Receivers {
+		if len(rEntry.Candidates) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// candidateKey identifies the receiver created for an endpoint.
+type candidateKey struct {
+	endpointID observer.EndpointID
+	receiverID component.ID
+}
+
+// candidateTracker records the current candidate of each receiver for each endpoint. Retrying a receiver
+// advances its candidate and reports the endpoint as changed to the internal receiver creator, which tears
+// down the receiver created for the previous candidate and creates the one whose rule matches the new
+// candidate. The receivers of the endpoint for the other receiver entries keep their current candidate.
+type candidateTracker struct {
+	logger   *zap.Logger
+	config   *Config
+	current  map[candidateKey]int
+	notifies map[observer.NotifyID]*candidateNotify
+	mu       sync.Mutex
+}
+
+func newCandidateTracker(logger *zap.Logger, config *Config) *candidateTracker {
+	return &candidateTracker{
+		logger:   logger,
+		config:   config,
+		current:  map[candidateKey]int{},
+		notifies: map[observer.NotifyID]*candidateNotify{},
+	}
+}
+
+// isCurrent returns whether the provided candidate is the receiver's current one for the endpoint. Statements
+// from the receivers of previous candidates that haven't completed shutting down should be disregarded.
+func (ct *candidateTracker) isCurrent(receiverID component.ID, endpointID observer.EndpointID, candidate int) bool {
+	if ct == nil {
+		return candidate == 0
+	}
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	return ct.current[candidateKey{endpointID: endpointID, receiverID: receiverID}] == candidate
+}
+
+// retry advances the receiver from the provided candidate to its next one for the endpoint, if any, and
+// returns whether a new receiver is being created for it.
+func (ct *candidateTracker) retry(receiverID component.ID, endpointID observer.EndpointID, candidate int) bool {
+	if ct == nil {
+		return false
+	}
+	next := candidate + 1
+	if next >= len(ct.config.Receivers[receiverID].Candidates) {
+		return false
+	}
+
+	key := candidateKey{endpointID: endpointID, receiverID: receiverID}
+	ct.mu.Lock()
+	if ct.current[key] != candidate {
+		ct.mu.Unlock()
+		return false
+	}
+	ct.current[key] = next
+	type change struct {
+		notify   observer.Notify
+		endpoint observer.Endpoint
+	}
+	var changes []change
+	for _, n := range ct.notifies {
+		if endpoint, ok := n.endpoints[endpointID]; ok {
+			changes = append(changes, change{notify: n.next, endpoint: ct.withCandidate(endpoint)})
+		}
+	}
+	ct.mu.Unlock()
+
+	ct.logger.Info("retrying receiver with next candidate", zap.String("receiver", receiverID.String()),
+		zap.String("endpoint", string(endpointID)), zap.Int("candidate", next))
+	// The statement triggering the retry is likely being logged by the receiver being torn down,
+	// so the change must not be handled synchronously.
+	go func() {
+		for _, c := range changes {
+			c.notify.OnChange([]observer.Endpoint{c.endpoint})
+		}
+	}()
+	return true
+}
+
+// withCandidate returns a copy of the endpoint whose env includes the current candidate of each receiver
+// with candidates. Must be called with ct.mu held.
+func (ct *candidateTracker) withCandidate(endpoint observer.Endpoint) observer.Endpoint {
+	if endpoint.Details == nil {
+		return endpoint
+	}
+	if cd, ok := endpoint.Details.(*candidateDetails); ok {
+		endpoint.Details = cd.EndpointDetails
+	}
+	candidates := map[string]any{}
+	for receiverID, rEntry := range ct.config.Receivers {
+		if len(rEntry.Candidates) > 0 {
+			candidates[receiverID.String()] = ct.current[candidateKey{endpointID: endpoint.ID, receiverID: receiverID}]
+		}
+	}
+	endpoint.Details = &candidateDetails{
+		EndpointDetails: endpoint.Details,
+		candidates:      candidates,
+	}
+	return endpoint
+}
+
+func (ct *candidateTracker) track(n *candidateNotify, endpoints []observer.Endpoint) []observer.Endpoint {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	tracked := make([]observer.Endpoint, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		n.endpoints[endpoint.ID] = endpoint
+		tracked = append(tracked, ct.withCandidate(endpoint))
+	}
+	return tracked
+}
+
+func (ct *candidateTracker) untrack(n *candidateNotify, endpoints []observer.Endpoint) []observer.Endpoint {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	untracked := make([]observer.Endpoint, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		untracked = append(untracked, ct.withCandidate(endpoint))
+		delete(n.endpoints, endpoint.ID)
+		for key := range ct.current {
+			if key.endpointID == endpoint.ID {
+				delete(ct.current, key)
+			}
+		}
+	}
+	return untracked
+}
+
+var _ observer.EndpointDetails = (*candidateDetails)(nil)
+
+// candidateDetails adds the current candidate of each receiver for the endpoint to its env.
+type candidateDetails struct {
+	observer.EndpointDetails
+	candidates map[string]any
+}
+
+func (cd *candidateDetails) Env() observer.EndpointEnv {
+	env := cd.EndpointDetails.Env()
+	env[candidateEnvKey] = cd.candidates
+	return env
+}
+
+var (
+	_ component.Host      = (*candidateHost)(nil)
+	_ observer.Observable = (*candidateObservable)(nil)
+	_ observer.Notify     = (*candidateNotify)(nil)
+)
+
+// candidateHost provides the internal receiver creator with candidateObservables in place of the watched observers.
+type candidateHost struct {
+	component.Host
+	extensions map[component.ID]component.Component
+}
+
+func newCandidateHost(host component.Host, observables map[component.ID]observer.Observable, tracker *candidateTracker) *candidateHost {
+	extensions := map[component.ID]component.Component{}
+	for id, ext := range host.GetExtensions() {
+		extensions[id] = ext
+	}
+	for id, observable := range observables {
+		extensions[id] = &candidateObservable{Observable: observable, tracker: tracker}
+	}
+	return &candidateHost{Host: host, extensions: extensions}
+}
+
+func (h *candidateHost) GetExtensions() map[component.ID]component.Component {
+	return h.extensions
+}
+
+// candidateObservable wraps the subscribing receiver creator's notify in a candidateNotify.
+type candidateObservable struct {
+	observer.Observable
+	tracker *candidateTracker
+}
+
+func (*candidateObservable) Start(context.Context, component.Host) error {
+	return nil
+}
+
+func (*candidateObservable) Shutdown(context.Context) error {
+	return nil
+}
+
+func (co *candidateObservable) ListAndWatch(notify observer.Notify) {
+	n := &candidateNotify{
+		next:      notify,
+		tracker:   co.tracker,
+		endpoints: map[observer.EndpointID]observer.Endpoint{},
+	}
+	co.tracker.mu.Lock()
+	co.tracker.notifies[notify.ID()] = n
+	co.tracker.mu.Unlock()
+	co.Observable.ListAndWatch(n)
+}
+
+func (co *candidateObservable) Unsubscribe(notify observer.Notify) {
+	co.tracker.mu.Lock()
+	n, ok := co.tracker.notifies[notify.ID()]
+	delete(co.tracker.notifies, notify.ID())
+	co.tracker.mu.Unlock()
+	if ok {
+		co.Observable.Unsubscribe(n)
+	}
+}
+
+// candidateNotify forwards endpoint events with their current candidate and retains the endpoints for retries.
+type candidateNotify struct {
+	next      observer.Notify
+	tracker   *candidateTracker
+	endpoints map[observer.EndpointID]observer.Endpoint
+}
+
+func (n *candidateNotify) ID() observer.NotifyID {
+	return n.next.ID()
+}
+
+func (n *candidateNotify) OnAdd(added []observer.Endpoint) {
+	n.next.OnAdd(n.tracker.track(n, added))
+}
+
+func (n *candidateNotify) OnRemove(removed []observer.Endpoint) {
+	n.next.OnRemove(n.tracker.untrack(n, removed))
+}
+
+func (n *candidateNotify) OnChange(changed []observer.Endpoint) {
+	n.next.OnChange(n.tracker.track(n, changed))
+}
@@ -0,0 +1,193 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package discoveryreceiver
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/observer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+)
+
+type recordingNotify struct {
+	candidates map[string][]any
+	lock       sync.Mutex
+}
+
+func (r *recordingNotify) ID() observer.NotifyID {
+	return "recording"
+}
+
+func (r *recordingNotify) record(event string, endpoints []observer.Endpoint) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for _, endpoint := range endpoints {
+		r.candidates[event] = append(r.candidates[event], endpoint.Details.Env()[candidateEnvKey])
+	}
+}
+
+func (r *recordingNotify) recorded(event string) []any {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]any(nil), r.candidates[event]...)
+}
+
+func (r *recordingNotify) OnAdd(added []observer.Endpoint) {
+	r.record("add", added)
+}
+
+func (r *recordingNotify) OnRemove(removed []observer.Endpoint) {
+	r.record("remove", removed)
+}
+
+func (r *recordingNotify) OnChange(changed []observer.Endpoint) {
+	r.record("change", changed)
+}
+
+func TestCandidateTrackerRetry(t *testing.T) {
+	mysqlID := component.MustNewID("mysql")
+	cfg := &Config{
+		Receivers: map[component.ID]ReceiverEntry{
+			mysqlID: {
+				Rule:       mustNewRule(`type == "container"`),
+				Candidates: []map[string]any{{"username": "first"}, {"username": "second"}},
+			},
+		},
+	}
+	require.True(t, cfg.hasCandidates())
+	tracker := newCandidateTracker(zap.NewNop(), cfg)
+
+	obs := &fakeObservable{}
+	host := newCandidateHost(mockHost{extensions: map[component.ID]component.Component{}},
+		map[component.ID]observer.Observable{component.MustNewID("an_observer"): obs}, tracker)
+	candidateObs, ok := host.GetExtensions()[component.MustNewID("an_observer")].(observer.Observable)
+	require.True(t, ok)
+
+	notify := &recordingNotify{candidates: map[string][]any{}}
+	candidateObs.ListAndWatch(notify)
+
+	endpoint := observer.Endpoint{
+		ID:      "mysql.endpoint",
+		Target:  "172.17.0.2:3306",
+		Details: &observer.Container{Name: "mysql", Port: 3306},
+	}
+	obs.onAdd([]observer.Endpoint{endpoint})
+	require.Equal(t, []any{map[string]any{"mysql": 0}}, notify.recorded("add"))
+	require.True(t, tracker.isCurrent(mysqlID, endpoint.ID, 0))
+
+	require.True(t, tracker.retry(mysqlID, endpoint.ID, 0))
+	require.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]any{map[string]any{"mysql": 1}}, notify.recorded("change"))
+	}, 5*time.Second, time.Millisecond)
+	require.False(t, tracker.isCurrent(mysqlID, endpoint.ID, 0))
+	require.True(t, tracker.isCurrent(mysqlID, endpoint.ID, 1))
+
+	// statements from the previous candidate's receiver and exhausted candidates don't lead to retries
+	require.False(t, tracker.retry(mysqlID, endpoint.ID, 0))
+	require.False(t, tracker.retry(mysqlID, endpoint.ID, 1))
+
+	obs.onRemove([]observer.Endpoint{endpoint})
+	require.Equal(t, []any{map[string]any{"mysql": 1}}, notify.recorded("remove"))
+	require.True(t, tracker.isCurrent(mysqlID, endpoint.ID, 0))
+
+	var nilTracker *candidateTracker
+	require.True(t, nilTracker.isCurrent(mysqlID, endpoint.ID, 0))
+	require.False(t, nilTracker.retry(mysqlID, endpoint.ID, 0))
+}
+
+func TestCandidateTrackerRetryKeepsOtherReceiversCandidates(t *testing.T) {
+	mysqlID := component.MustNewID("mysql")
+	otherID := component.MustNewIDWithName("mysql", "other")
+	rule := mustNewRule(`type == "container"`)
+	cfg := &Config{
+		Receivers: map[component.ID]ReceiverEntry{
+			mysqlID: {Rule: rule, Candidates: []map[string]any{{"username": "first"}, {"username": "second"}}},
+			otherID: {Rule: rule, Candidates: []map[string]any{{"username": "first"}, {"username": "second"}}},
+		},
+	}
+	tracker := newCandidateTracker(zap.NewNop(), cfg)
+
+	obs := &fakeObservable{}
+	host := newCandidateHost(mockHost{extensions: map[component.ID]component.Component{}},
+		map[component.ID]observer.Observable{component.MustNewID("an_observer"): obs}, tracker)
+	candidateObs, ok := host.GetExtensions()[component.MustNewID("an_observer")].(observer.Observable)
+	require.True(t, ok)
+
+	var changed []observer.Endpoint
+	var lock sync.Mutex
+	notify := &recordingNotify{candidates: map[string][]any{}}
+	candidateObs.ListAndWatch(&endpointsNotify{recordingNotify: notify, onChange: func(endpoints []observer.Endpoint) {
+		lock.Lock()
+		defer lock.Unlock()
+		changed = append(changed, endpoints...)
+	}})
+
+	endpoint := observer.Endpoint{
+		ID:      "mysql.endpoint",
+		Target:  "172.17.0.2:3306",
+		Details: &observer.Container{Name: "mysql", Port: 3306},
+	}
+	obs.onAdd([]observer.Endpoint{endpoint})
+	require.Equal(t, []any{map[string]any{"mysql": 0, "mysql/other": 0}}, notify.recorded("add"))
+
+	require.True(t, tracker.retry(mysqlID, endpoint.ID, 0))
+	require.Eventually(t, func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+		return len(changed) == 1
+	}, 5*time.Second, time.Millisecond)
+	require.Equal(t, []any{map[string]any{"mysql": 1, "mysql/other": 0}}, notify.recorded("change"))
+	require.True(t, tracker.isCurrent(mysqlID, endpoint.ID, 1))
+	require.True(t, tracker.isCurrent(otherID, endpoint.ID, 0))
+
+	// the changed endpoint still matches the other receiver's current candidate template
+	env, err := changed[0].Env()
+	require.NoError(t, err)
+	for _, tc := range []struct {
+		receiverID component.ID
+		candidate  int
+		matches    bool
+	}{
+		{mysqlID, 0, false},
+		{mysqlID, 1, true},
+		{otherID, 0, true},
+		{otherID, 1, false},
+	} {
+		candidateMatch, err := mustNewRule(candidateRule(tc.receiverID, rule, tc.candidate)).eval(env)
+		require.NoError(t, err)
+		assert.Equal(t, tc.matches, candidateMatch, "%s candidate %d", tc.receiverID, tc.candidate)
+	}
+
+	// the other receiver has its own candidates to retry
+	require.True(t, tracker.retry(otherID, endpoint.ID, 0))
+	require.True(t, tracker.isCurrent(mysqlID, endpoint.ID, 1))
+	require.True(t, tracker.isCurrent(otherID, endpoint.ID, 1))
+}
+
+// endpointsNotify records the changed endpoints in addition to their candidates.
+type endpointsNotify struct {
+	*recordingNotify
+	onChange func([]observer.Endpoint)
+}
+
+func (n *endpointsNotify) OnChange(changed []observer.Endpoint) {
+	n.recordingNotify.OnChange(changed)
+	n.onChange(changed)
+}
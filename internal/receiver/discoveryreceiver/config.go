@@ -18,8 +18,11 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"sort"
+	"strconv"
 	"time"
 
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/observer"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/receivercreator"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/confmap"
@@ -51,6 +54,12 @@ type Config struct {
 	// Warning: these values will include the literal receiver subconfig from the parent Collector config.
 	// The feature provides no secret redaction and its output is easily decodable into plaintext.
 	EmbedReceiverConfig bool `mapstructure:"embed_receiver_config"`
+	// The ID of a storage extension used to persist emitted entities and their last status across restarts.
+	// Entity delete events are emitted for persisted entities whose endpoints are no longer reported.
+	Storage *component.ID `mapstructure:"storage"`
+	// The ID of an OpAMP extension supporting custom capabilities, like opamp, through which a summary of the
+	// discovered endpoints and their statuses is reported to the OpAMP server as "com.splunk.discovery" custom messages.
+	OpAMP *component.ID `mapstructure:"opamp"`
 	// The duration to maintain "removed" endpoints since their last updated timestamp.
 	CorrelationTTL time.Duration `mapstructure:"correlation_ttl"`
 }
@@ -62,6 +71,11 @@ type ReceiverEntry struct {
 	Config             map[string]any    `mapstructure:"config"`
 	ResourceAttributes map[string]string `mapstructure:"resource_attributes"`
 	Rule               Rule              `mapstructure:"rule"`
+	// Candidates are alternative config fragments, like credential sets, that are merged over Config in order.
+	// The receiver is created with the first candidate and, whenever a statement match with a "partial" status
+	// is evaluated for it, recreated with the next one. The index of the candidate in use is reported in the
+	// "discovery.receiver.candidate" attribute.
+	Candidates []map[string]any `mapstructure:"candidates"`
 }
 
 // Status defines the Match rules for applicable app and telemetry sources.
@@ -104,6 +118,10 @@ func (cfg *Config) Validate() error {
 				err = multierr.Combine(err, fmt.Errorf("receiver %q validation failure: receiver name cannot contain %q", name, re.String()))
 			}
 		}
+		// The receiver creator templates of non-initial candidates are distinguished by their name suffix.
+		if candidateNameRegexp.MatchString(rName.Name()) {
+			err = multierr.Combine(err, fmt.Errorf("receiver %q validation failure: receiver name cannot match %q", name, candidateNameRegexp.String()))
+		}
 	}
 
 	if len(cfg.WatchObservers) == 0 {
@@ -153,6 +171,28 @@ func (s *Status) Validate() error {
 	return err
 }
 
+// MatchingReceivers returns the sorted IDs of all configured receivers whose rule matches
+// the provided endpoint environment. Rule evaluation errors are combined and returned
+// alongside any successful matches.
+func (cfg *Config) MatchingReceivers(endpointEnv observer.EndpointEnv) ([]component.ID, error) {
+	var matchingReceivers []component.ID
+	var err error
+	for receiverID, receiverCfg := range cfg.Receivers {
+		ok, e := receiverCfg.Rule.eval(endpointEnv)
+		if e != nil {
+			err = multierr.Combine(err, fmt.Errorf("rule %q for receiver %q: %w", receiverCfg.Rule.String(), receiverID, e))
+			continue
+		}
+		if ok {
+			matchingReceivers = append(matchingReceivers, receiverID)
+		}
+	}
+	sort.Slice(matchingReceivers, func(i, j int) bool {
+		return matchingReceivers[i].String() < matchingReceivers[j].String()
+	})
+	return matchingReceivers, err
+}
+
 // receiverCreatorFactoryAndConfig will embed the applicable receiver creator fields in a new receiver creator config
 // suitable for being used to create a receiver instance by the returned factory.
 func (cfg *Config) receiverCreatorFactoryAndConfig() (receiver.Factory, component.Config, error) {
@@ -165,9 +205,12 @@ func (cfg *Config) receiverCreatorFactoryAndConfig() (receiver.Factory, componen
 
 	receiverCreatorConfig.WatchObservers = cfg.WatchObservers
 
-	receiversConfig := cfg.receiverCreatorReceiversConfig()
+	receiversConfig, err := cfg.receiverCreatorReceiversConfig()
+	if err != nil {
+		return nil, nil, err
+	}
 	receiverTemplates := confmap.NewFromStringMap(map[string]any{"receivers": receiversConfig})
-	if err := receiverCreatorConfig.Unmarshal(receiverTemplates); err != nil {
+	if err = receiverCreatorConfig.Unmarshal(receiverTemplates); err != nil {
 		return nil, nil, fmt.Errorf("failed unmarshaling discoveryreceiver receiverTemplates into receiver_creator config: %w", err)
 	}
 
@@ -175,7 +218,8 @@ func (cfg *Config) receiverCreatorFactoryAndConfig() (receiver.Factory, componen
 }
 
 // receiverCreatorReceiversConfig produces the actual config string map used by the receiver creator config unmarshaler.
-func (cfg *Config) receiverCreatorReceiversConfig() map[string]any {
+// Receivers with candidates have a template for each candidate whose rule only matches endpoints currently using it.
+func (cfg *Config) receiverCreatorReceiversConfig() (map[string]any, error) {
 	receiversConfig := map[string]any{}
 	for receiverID, rEntry := range cfg.Receivers {
 		resourceAttributes := map[string]string{}
@@ -186,12 +230,33 @@ func (cfg *Config) receiverCreatorReceiversConfig() map[string]any {
 		resourceAttributes[discovery.ReceiverTypeAttr] = receiverID.Type().String()
 		resourceAttributes[discovery.EndpointIDAttr] = "`id`"
 
-		rEntryMap := map[string]any{}
-		rEntryMap["rule"] = rEntry.Rule.String()
-		rEntryMap["config"] = rEntry.Config
-		rEntryMap["resource_attributes"] = resourceAttributes
-		receiversConfig[receiverID.String()] = rEntryMap
+		if len(rEntry.Candidates) == 0 {
+			rEntryMap := map[string]any{}
+			rEntryMap["rule"] = rEntry.Rule.String()
+			rEntryMap["config"] = rEntry.Config
+			rEntryMap["resource_attributes"] = resourceAttributes
+			receiversConfig[receiverID.String()] = rEntryMap
+			continue
+		}
+
+		for i, candidate := range rEntry.Candidates {
+			candidateConfig := confmap.NewFromStringMap(rEntry.Config)
+			if err := candidateConfig.Merge(confmap.NewFromStringMap(candidate)); err != nil {
+				return nil, fmt.Errorf("failed merging candidate %d of receiver %q: %w", i, receiverID, err)
+			}
+			candidateAttributes := map[string]string{}
+			for k, v := range resourceAttributes {
+				candidateAttributes[k] = v
+			}
+			candidateAttributes[discovery.ReceiverCandidateAttr] = strconv.Itoa(i)
+
+			rEntryMap := map[string]any{}
+			rEntryMap["rule"] = candidateRule(receiverID, rEntry.Rule, i)
+			rEntryMap["config"] = candidateConfig.ToStringMap()
+			rEntryMap["resource_attributes"] = candidateAttributes
+			receiversConfig[candidateTemplateID(receiverID, i).String()] = rEntryMap
+		}
 	}
 
-	return receiversConfig
+	return receiversConfig, nil
 }
@@ -0,0 +1,148 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The code is copied from
+// https://github.com/open-telemetry/opentelemetry-collector-contrib/blob/v0.159.0/receiver/receivercreator/config_expansion.go
+// with minimal changes so the discovery report expands the receiver configs like the embedded receiver
+// creator does. Once the discovery receiver upstreamed, this code can be reused.
+
+package discoveryreceiver
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/expr-lang/expr"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/observer"
+)
+
+// evalBackticksInConfigValue expands any expressions within backticks inside configValue
+// using variables from env.
+//
+// Note that when evaluating multi-expression strings, the expression results are concatenated
+// as strings, and a value consisting of a single expression retains the type of its result.
+func evalBackticksInConfigValue(configValue string, env observer.EndpointEnv) (any, error) {
+	// Tracks index into configValue where an expression (backtick) begins. -1 is unset.
+	exprStartIndex := -1
+	// Accumulate expanded string.
+	output := &strings.Builder{}
+	// Accumulate results of calls to eval for use at the end to return well-typed
+	// results if possible.
+	var expansions []any
+
+	// Loop through configValue one byte at a time using exprStartIndex to keep track of
+	// inside or outside of expressions.
+	for i := 0; i < len(configValue); i++ {
+		switch configValue[i] {
+		case '\\':
+			if i+1 == len(configValue) {
+				return nil, errors.New(`encountered escape (\) without value at end of expression`)
+			}
+			output.WriteByte(configValue[i+1])
+			i++
+		case '`':
+			if exprStartIndex == -1 {
+				// Opening backtick, start of expression.
+				exprStartIndex = i + 1
+			} else {
+				// Closing backtick, evaluate previous expression.
+				res, err := expr.Eval(configValue[exprStartIndex:i], map[string]any(env))
+				if err != nil {
+					return nil, err
+				}
+				expansions = append(expansions, res)
+				_, _ = fmt.Fprintf(output, "%v", res)
+				// Reset start index since this expression just closed.
+				exprStartIndex = -1
+			}
+		default:
+			if exprStartIndex == -1 {
+				// Not inside an expression so write byte to output.
+				output.WriteByte(configValue[i])
+			}
+		}
+	}
+
+	if exprStartIndex != -1 {
+		return nil, fmt.Errorf("expression was unclosed in %q", configValue)
+	}
+
+	// If there was only one expansion and it is equal to the full output string return the expansion
+	// itself so that it retains its type (e.g., int, bool).
+	if len(expansions) == 1 && output.String() == fmt.Sprintf("%v", expansions[0]) {
+		return expansions[0], nil
+	}
+
+	return output.String(), nil
+}
+
+// ExpandConfig walks the provided receiver config and expands any `backticked` content
+// with the associated observer.EndpointEnv values, returning an expanded copy.
+func ExpandConfig(cfg map[string]any, env observer.EndpointEnv) (map[string]any, error) {
+	expanded, err := expandAny(cfg, env)
+	if err != nil {
+		return nil, err
+	}
+	expandedMap, _ := expanded.(map[string]any)
+	return expandedMap, nil
+}
+
+// expandAny recursively expands any expressions in backticks inside values of input using
+// env as variables available within the expression, returning a copy of input
+func expandAny(input any, env observer.EndpointEnv) (any, error) {
+	switch v := input.(type) {
+	case string:
+		res, err := evalBackticksInConfigValue(v, env)
+		if err != nil {
+			return nil, fmt.Errorf("failed evaluating config expression for %v: %w", v, err)
+		}
+		return res, nil
+	case []string, []any:
+		var vSlice []any
+		if vss, ok := v.([]string); ok {
+			// expanded strings aren't guaranteed to remain them, so we
+			// coerce to any for shared []any expansion path
+			for _, vs := range vss {
+				vSlice = append(vSlice, vs)
+			}
+		} else {
+			vSlice = v.([]any)
+		}
+		expandedSlice := make([]any, 0, len(vSlice))
+		for _, val := range vSlice {
+			expanded, err := expandAny(val, env)
+			if err != nil {
+				return nil, fmt.Errorf("failed evaluating config expression for %v: %w", val, err)
+			}
+			expandedSlice = append(expandedSlice, expanded)
+		}
+		return expandedSlice, nil
+	case map[string]any:
+		if v == nil {
+			return nil, nil
+		}
+		expandedMap := map[string]any{}
+		for key, val := range v {
+			expandedVal, err := expandAny(val, env)
+			if err != nil {
+				return nil, fmt.Errorf("failed evaluating config expression for {%q: %v}: %w", key, val, err)
+			}
+			expandedMap[key] = expandedVal
+		}
+		return expandedMap, nil
+	default:
+		return v, nil
+	}
+}
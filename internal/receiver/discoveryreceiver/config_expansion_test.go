@@ -0,0 +1,55 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package discoveryreceiver
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+)
+
+// configExpansionFixtures are the receiver config expansion cases of testdata/config_expansion.yaml.
+type configExpansionFixtures struct {
+	Env   map[string]any `mapstructure:"env"`
+	Cases []struct {
+		Config   map[string]any `mapstructure:"config"`
+		Expected map[string]any `mapstructure:"expected"`
+		Name     string         `mapstructure:"name"`
+		Error    string         `mapstructure:"error"`
+	} `mapstructure:"cases"`
+}
+
+func TestExpandConfig(t *testing.T) {
+	conf, err := confmaptest.LoadConf(filepath.Join("testdata", "config_expansion.yaml"))
+	require.NoError(t, err)
+	var fixtures configExpansionFixtures
+	require.NoError(t, conf.Unmarshal(&fixtures))
+	require.NotEmpty(t, fixtures.Cases)
+
+	for _, fixture := range fixtures.Cases {
+		t.Run(fixture.Name, func(t *testing.T) {
+			expanded, err := ExpandConfig(fixture.Config, fixtures.Env)
+			if fixture.Error != "" {
+				require.ErrorContains(t, err, fixture.Error)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, fixture.Expected, expanded)
+		})
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/observer"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/receivercreator"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -71,6 +72,7 @@ func TestInvalidConfigs(t *testing.T) {
 		{name: "no_watch_observers", expectedError: "`watch_observers` must be defined and include at least one configured observer extension"},
 		{name: "reserved_receiver_creator", expectedError: `receiver "receiver_creator/with-name" validation failure: receiver cannot be a receiver_creator`},
 		{name: "reserved_receiver_name", expectedError: "receiver \"a_receiver/with-receiver_creator/in-name\" validation failure: receiver name cannot contain \"receiver_creator/\""},
+		{name: "reserved_receiver_candidate_name", expectedError: "receiver \"a_receiver/candidate-1\" validation failure: receiver name cannot match \"^(?:(.*)/)?candidate-([0-9]+)$\""},
 	}
 
 	for _, test := range tests {
@@ -116,7 +118,8 @@ func TestReceiverCreatorFactoryAndConfig(t *testing.T) {
 		component.MustNewIDWithName("another_observer", "with_name"),
 	}, creatorCfg.WatchObservers)
 
-	receiverTemplate := dCfg.receiverCreatorReceiversConfig()
+	receiverTemplate, err := dCfg.receiverCreatorReceiversConfig()
+	require.NoError(t, err)
 	expectedTemplate := map[string]any{
 		"redis": map[string]any{
 			"config": map[string]any{
@@ -135,3 +138,118 @@ func TestReceiverCreatorFactoryAndConfig(t *testing.T) {
 	}
 	require.Equal(t, expectedTemplate, receiverTemplate)
 }
+
+func TestReceiverCreatorReceiversConfigWithCandidates(t *testing.T) {
+	mysqlID := component.MustNewIDWithName("mysql", "bundled")
+	cfg := &Config{
+		Receivers: map[component.ID]ReceiverEntry{
+			mysqlID: {
+				Config: map[string]any{
+					"endpoint": "`endpoint`",
+					"username": "splunk.discovery.default",
+					"tls":      map[string]any{"insecure": true},
+				},
+				Candidates: []map[string]any{
+					{},
+					{"username": "root", "password": "${env:MYSQL_ROOT_PASSWORD}"},
+					{"username": "monitor", "tls": map[string]any{"insecure_skip_verify": true}},
+				},
+				Rule: mustNewRule(`type == "container" and port == 3306`),
+			},
+		},
+	}
+	require.NoError(t, cfg.Validate())
+
+	receiverTemplates, err := cfg.receiverCreatorReceiversConfig()
+	require.NoError(t, err)
+	resourceAttributes := func(candidate string) map[string]string {
+		return map[string]string{
+			"discovery.endpoint.id":        "`id`",
+			"discovery.receiver.name":      "bundled",
+			"discovery.receiver.type":      "mysql",
+			"discovery.receiver.candidate": candidate,
+		}
+	}
+	require.Equal(t, map[string]any{
+		"mysql/bundled": map[string]any{
+			"config": map[string]any{
+				"endpoint": "`endpoint`",
+				"username": "splunk.discovery.default",
+				"tls":      map[string]any{"insecure": true},
+			},
+			"resource_attributes": resourceAttributes("0"),
+			"rule":                `type == "container" and (type == "container" and port == 3306) and discovery_candidate["mysql/bundled"] == 0`,
+		},
+		"mysql/bundled/candidate-1": map[string]any{
+			"config": map[string]any{
+				"endpoint": "`endpoint`",
+				"username": "root",
+				"password": "${env:MYSQL_ROOT_PASSWORD}",
+				"tls":      map[string]any{"insecure": true},
+			},
+			"resource_attributes": resourceAttributes("1"),
+			"rule":                `type == "container" and (type == "container" and port == 3306) and discovery_candidate["mysql/bundled"] == 1`,
+		},
+		"mysql/bundled/candidate-2": map[string]any{
+			"config": map[string]any{
+				"endpoint": "`endpoint`",
+				"username": "monitor",
+				"tls":      map[string]any{"insecure": true, "insecure_skip_verify": true},
+			},
+			"resource_attributes": resourceAttributes("2"),
+			"rule":                `type == "container" and (type == "container" and port == 3306) and discovery_candidate["mysql/bundled"] == 2`,
+		},
+	}, receiverTemplates)
+
+	for templateID, expected := range map[string]struct {
+		receiverID component.ID
+		candidate  int
+	}{
+		"mysql/bundled":             {mysqlID, 0},
+		"mysql/bundled/candidate-2": {mysqlID, 2},
+		"mysql/bundled/candidate-3": {component.MustNewIDWithName("mysql", "bundled/candidate-3"), 0},
+		"redis/candidate-1":         {component.MustNewIDWithName("redis", "candidate-1"), 0},
+	} {
+		var id component.ID
+		require.NoError(t, id.UnmarshalText([]byte(templateID)))
+		receiverID, candidate := cfg.receiverCandidateFromTemplateID(id)
+		assert.Equal(t, expected.receiverID, receiverID, templateID)
+		assert.Equal(t, expected.candidate, candidate, templateID)
+	}
+}
+
+func TestMatchingReceivers(t *testing.T) {
+	cfg := &Config{
+		Receivers: map[component.ID]ReceiverEntry{
+			component.MustNewID("redis"): {
+				Rule: mustNewRule(`type == "container" && name matches "(?i)redis"`),
+			},
+			component.MustNewIDWithName("redis", "any"): {
+				Rule: mustNewRule(`type == "container"`),
+			},
+			component.MustNewID("mysql"): {
+				Rule: mustNewRule(`type == "container" && name matches "(?i)mysql"`),
+			},
+			component.MustNewID("invalid"): {
+				Rule: mustNewRule(`type == "container" ? "not a boolean" : false`),
+			},
+		},
+	}
+
+	matching, err := cfg.MatchingReceivers(observer.EndpointEnv{
+		"type": "container",
+		"name": "my-redis",
+	})
+	require.EqualError(t, err, `rule "type == \"container\" ? \"not a boolean\" : false" for receiver "invalid": rule did not return a boolean`)
+	require.Equal(t, []component.ID{
+		component.MustNewID("redis"),
+		component.MustNewIDWithName("redis", "any"),
+	}, matching)
+
+	matching, err = cfg.MatchingReceivers(observer.EndpointEnv{
+		"type": "hostport",
+		"name": "my-redis",
+	})
+	require.NoError(t, err)
+	require.Empty(t, matching)
+}
@@ -63,9 +63,14 @@ type endpointTracker struct {
 	observables  map[component.ID]observer.Observable
 	stopCh       chan struct{}
 	notifies     []*notify
+	// store persists emitted entities, if a storage extension is configured.
+	store *entityStore
 	// emitInterval defines an interval for emitting entity state events.
 	// Potentially can be exposed as a user config option if there is a need.
 	emitInterval time.Duration
+	// vanishedGracePeriod defines how long observers have to report restored endpoints again
+	// before entity delete events are emitted for them.
+	vanishedGracePeriod time.Duration
 }
 
 type notify struct {
@@ -91,7 +96,10 @@ func newEndpointTracker(
 		// 15 minutes are not emitted again. So the actual interval of emitting entity state events can be more than 15
 		// minutes but always less than 30 minutes.
 		emitInterval: 15 * time.Minute,
-		stopCh:       make(chan struct{}),
+		// Observers report existing endpoints on subscription or in their first refresh,
+		// which is 10s by default for the host_observer.
+		vanishedGracePeriod: time.Minute,
+		stopCh:              make(chan struct{}),
 	}
 }
 
@@ -113,6 +121,12 @@ func (et *endpointTracker) start() {
 
 func (et *endpointTracker) startEmitLoop() {
 	timer := time.NewTicker(et.emitInterval)
+	var vanishedC <-chan time.Time
+	if et.store != nil {
+		vanishedTimer := time.NewTimer(et.vanishedGracePeriod)
+		defer vanishedTimer.Stop()
+		vanishedC = vanishedTimer.C
+	}
 	for {
 		select {
 		case corr := <-et.correlations.emitCh:
@@ -121,6 +135,8 @@ func (et *endpointTracker) startEmitLoop() {
 			for obs := range et.observables {
 				et.emitEntityStateEvents(obs, et.correlations.Endpoints(time.Now().Add(-et.emitInterval)))
 			}
+		case <-vanishedC:
+			et.emitVanishedEntityDeleteEvents()
 		case <-et.stopCh:
 			timer.Stop()
 			return
@@ -143,6 +159,9 @@ func (et *endpointTracker) emitEntityStateEvents(observerCID component.ID, endpo
 		if err != nil {
 			et.logger.Warn(fmt.Sprintf("failed converting %v endpoints to entity state events", numFailed), zap.Error(err))
 		}
+		if et.store != nil && entityEvents.Len() > 0 {
+			et.store.recordStateEvents(entityEvents, et.correlations)
+		}
 		if entityEvents.Len() > 0 {
 			et.pLogs <- entityEvents.ConvertAndMoveToLogs()
 		}
@@ -156,12 +175,41 @@ func (et *endpointTracker) emitEntityDeleteEvents(observerCID component.ID, endp
 		if err != nil {
 			et.logger.Warn(fmt.Sprintf("failed converting %v endpoints to entity delete events", numFailed), zap.Error(err))
 		}
+		if et.store != nil {
+			et.store.forget(endpoints)
+		}
 		if entityEvents.Len() > 0 {
 			et.pLogs <- entityEvents.ConvertAndMoveToLogs()
 		}
 	}
 }
 
+// emitVanishedEntityDeleteEvents emits entity delete events for the restored entities
+// whose endpoints weren't reported again, like those removed while the collector was down.
+func (et *endpointTracker) emitVanishedEntityDeleteEvents() {
+	vanished := et.store.takeVanished()
+	if et.pLogs == nil || len(vanished) == 0 {
+		return
+	}
+	entityEvents := experimentalmetricmetadata.NewEntityEventsSlice()
+	ts := pcommon.NewTimestampFromTime(time.Now())
+	for endpointID, entity := range vanished {
+		id := pcommon.NewMap()
+		if err := id.FromRaw(entity.ID); err != nil {
+			et.logger.Warn("failed restoring vanished entity id", zap.String("endpoint", string(endpointID)), zap.Error(err))
+			continue
+		}
+		event := entityEvents.AppendEmpty()
+		id.MoveTo(event.ID())
+		event.SetTimestamp(ts)
+		event.SetEntityDelete().SetEntityType(entityType)
+	}
+	if entityEvents.Len() > 0 {
+		et.logger.Debug("emitting entity delete events for vanished endpoints", zap.Int("count", entityEvents.Len()))
+		et.pLogs <- entityEvents.ConvertAndMoveToLogs()
+	}
+}
+
 func (et *endpointTracker) updateEndpoints(endpoints []observer.Endpoint, observerID component.ID) {
 	var matchingEndpoints []observer.Endpoint
 	for _, endpoint := range endpoints {
@@ -169,6 +217,12 @@ func (et *endpointTracker) updateEndpoints(endpoints []observer.Endpoint, observ
 		if receiver != discovery.NoType {
 			matchingEndpoints = append(matchingEndpoints, endpoint)
 			et.correlations.UpdateEndpoint(endpoint, receiver, observerID)
+			if et.store != nil {
+				// retain the status emitted before the restart
+				if attrs, ok := et.store.claimRestored(endpoint.ID, receiver); ok {
+					et.correlations.UpdateAttrs(endpoint.ID, attrs)
+				}
+			}
 		}
 	}
 	et.emitEntityStateEvents(observerID, matchingEndpoints)
@@ -200,16 +254,9 @@ func (et *endpointTracker) receiverMatchingEndpoint(endpoint observer.Endpoint)
 }
 
 func (et *endpointTracker) matchingReceivers(endpointEnv observer.EndpointEnv) []component.ID {
-	var matchingReceivers []component.ID
-	for receiverID, receiverCfg := range et.config.Receivers {
-		ok, err := receiverCfg.Rule.eval(endpointEnv)
-		if err != nil {
-			et.logger.Error("failed matching rule", zap.String("rule", receiverCfg.Rule.String()), zap.Error(err))
-			continue
-		}
-		if ok {
-			matchingReceivers = append(matchingReceivers, receiverID)
-		}
+	matchingReceivers, err := et.config.MatchingReceivers(endpointEnv)
+	for _, e := range multierr.Errors(err) {
+		et.logger.Error("failed matching rule", zap.Error(e))
 	}
 	return matchingReceivers
 }
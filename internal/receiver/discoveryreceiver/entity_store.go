@@ -0,0 +1,239 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package discoveryreceiver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/observer"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/experimentalmetricmetadata"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/common/discovery"
+)
+
+// entitiesStorageKey is the storage client key of the JSON-encoded storedEntity map.
+const entitiesStorageKey = "entities"
+
+// storedEntity is the persisted content of the last entity state event emitted for an endpoint.
+type storedEntity struct {
+	// ID is the entity's identifying attributes, required for its entity delete event.
+	ID map[string]any `json:"id"`
+	// Attrs are the endpoint's correlation attributes, including its last discovery status.
+	Attrs map[string]string `json:"attributes"`
+}
+
+// receiverID returns the ID of the receiver the entity status was evaluated for.
+func (e storedEntity) receiverID() (component.ID, bool) {
+	receiverType, err := component.NewType(e.Attrs[discovery.ReceiverTypeAttr])
+	if err != nil {
+		return discovery.NoType, false
+	}
+	return component.NewIDWithName(receiverType, e.Attrs[discovery.ReceiverNameAttr]), true
+}
+
+// entityStore persists the entities of emitted entity state events with a storage extension client so
+// their status can be restored and vanished endpoints deleted after a restart.
+type entityStore struct {
+	client   storage.Client
+	logger   *zap.Logger
+	entities map[observer.EndpointID]storedEntity
+	// restored are the loaded entities whose endpoints haven't been reported by an observer since.
+	restored map[observer.EndpointID]storedEntity
+	mu       sync.Mutex
+	// version is incremented for each snapshot of the entities to persist.
+	version uint64
+	closed  bool
+
+	// writeMu serializes the storage client calls, made without holding mu.
+	writeMu sync.Mutex
+	// written is the version of the last persisted snapshot.
+	written      uint64
+	clientClosed bool
+}
+
+// newEntityStore obtains a storage client for the discovery receiver from the configured storage extension.
+func newEntityStore(ctx context.Context, host component.Host, storageID, receiverID component.ID, logger *zap.Logger) (*entityStore, error) {
+	ext, ok := host.GetExtensions()[storageID]
+	if !ok {
+		return nil, fmt.Errorf("storage extension %q not found", storageID)
+	}
+	storageExt, ok := ext.(storage.Extension)
+	if !ok {
+		return nil, fmt.Errorf("extension %q is not a storage extension", storageID)
+	}
+	client, err := storageExt.GetClient(ctx, component.KindReceiver, receiverID, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed obtaining storage client: %w", err)
+	}
+	return &entityStore{
+		client:   client,
+		logger:   logger,
+		entities: map[observer.EndpointID]storedEntity{},
+		restored: map[observer.EndpointID]storedEntity{},
+	}, nil
+}
+
+// load restores the entities persisted by the previous run.
+func (s *entityStore) load(ctx context.Context) error {
+	content, err := s.client.Get(ctx, entitiesStorageKey)
+	if err != nil {
+		return fmt.Errorf("failed reading stored entities: %w", err)
+	}
+	if len(content) == 0 {
+		return nil
+	}
+
+	// json.Numbers are used to retain integer identifying attributes like source.port
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	entities := map[observer.EndpointID]storedEntity{}
+	if err = decoder.Decode(&entities); err != nil {
+		return fmt.Errorf("failed decoding stored entities: %w", err)
+	}
+	for endpointID, entity := range entities {
+		for k, v := range entity.ID {
+			if n, ok := v.(json.Number); ok {
+				if i, e := n.Int64(); e == nil {
+					entity.ID[k] = i
+				} else if f, e := n.Float64(); e == nil {
+					entity.ID[k] = f
+				}
+			}
+		}
+		entities[endpointID] = entity
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for endpointID, entity := range entities {
+		s.entities[endpointID] = entity
+		s.restored[endpointID] = entity
+	}
+	return nil
+}
+
+// claimRestored returns the restored attributes of an endpoint that has been reported by an observer again
+// for the same receiver. Restored endpoints are only claimed once.
+func (s *entityStore) claimRestored(endpointID observer.EndpointID, receiverID component.ID) (map[string]string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entity, ok := s.restored[endpointID]
+	if !ok {
+		return nil, false
+	}
+	delete(s.restored, endpointID)
+	if rID, valid := entity.receiverID(); !valid || rID != receiverID {
+		return nil, false
+	}
+	return entity.Attrs, true
+}
+
+// takeVanished returns and forgets the restored entities whose endpoints haven't been reported again.
+func (s *entityStore) takeVanished() map[observer.EndpointID]storedEntity {
+	s.mu.Lock()
+	vanished := s.restored
+	s.restored = map[observer.EndpointID]storedEntity{}
+	for endpointID := range vanished {
+		delete(s.entities, endpointID)
+	}
+	s.mu.Unlock()
+	s.persist()
+	return vanished
+}
+
+// recordStateEvents stores the entities of the provided entity state events with their endpoint's current attributes.
+func (s *entityStore) recordStateEvents(events experimentalmetricmetadata.EntityEventsSlice, correlations *correlationStore) {
+	s.mu.Lock()
+	for i := 0; i < events.Len(); i++ {
+		event := events.At(i)
+		if event.EventType() != experimentalmetricmetadata.EventTypeState {
+			continue
+		}
+		endpointID, ok := event.EntityStateDetails().Attributes().Get(discovery.EndpointIDAttr)
+		if !ok {
+			continue
+		}
+		id := observer.EndpointID(endpointID.Str())
+		s.entities[id] = storedEntity{
+			ID:    event.ID().AsRaw(),
+			Attrs: correlations.Attrs(id),
+		}
+	}
+	s.mu.Unlock()
+	s.persist()
+}
+
+// forget removes the entities of deleted endpoints.
+func (s *entityStore) forget(endpoints []observer.Endpoint) {
+	s.mu.Lock()
+	for _, endpoint := range endpoints {
+		delete(s.entities, endpoint.ID)
+		delete(s.restored, endpoint.ID)
+	}
+	s.mu.Unlock()
+	s.persist()
+}
+
+// persist writes a snapshot of the entities taken under the lock, so the storage I/O doesn't block
+// the endpoint updates. Snapshots older than the last written one are skipped.
+func (s *entityStore) persist() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	content, err := json.Marshal(s.entities)
+	s.version++
+	version := s.version
+	s.mu.Unlock()
+	if err != nil {
+		s.logger.Warn("failed encoding entities for storage", zap.Error(err))
+		return
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	if s.clientClosed || version <= s.written {
+		return
+	}
+	if err = s.client.Set(context.Background(), entitiesStorageKey, content); err != nil {
+		s.logger.Warn("failed storing entities", zap.Error(err))
+		return
+	}
+	s.written = version
+}
+
+func (s *entityStore) close(ctx context.Context) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.mu.Unlock()
+
+	// in-flight writes are completed before closing the client.
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	s.clientClosed = true
+	return s.client.Close(ctx)
+}
@@ -0,0 +1,218 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package discoveryreceiver
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/observer"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/experimentalmetricmetadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/pdatatest/plogtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/common/discovery"
+)
+
+var _ storage.Extension = (*fakeStorage)(nil)
+
+type fakeStorage struct {
+	nopObserver
+	client *fakeStorageClient
+}
+
+func (f *fakeStorage) GetClient(context.Context, component.Kind, component.ID, string) (storage.Client, error) {
+	return f.client, nil
+}
+
+type fakeStorageClient struct {
+	content map[string][]byte
+	// setting, when set, is called before each Set.
+	setting func()
+	lock    sync.Mutex
+}
+
+func (c *fakeStorageClient) Get(_ context.Context, key string) ([]byte, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.content[key], nil
+}
+
+func (c *fakeStorageClient) Set(_ context.Context, key string, value []byte) error {
+	if c.setting != nil {
+		c.setting()
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.content[key] = value
+	return nil
+}
+
+func (c *fakeStorageClient) Delete(_ context.Context, key string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.content, key)
+	return nil
+}
+
+func (c *fakeStorageClient) Batch(ctx context.Context, ops ...*storage.Operation) error {
+	for _, op := range ops {
+		var err error
+		switch op.Type {
+		case storage.Get:
+			op.Value, err = c.Get(ctx, op.Key)
+		case storage.Set:
+			err = c.Set(ctx, op.Key, op.Value)
+		case storage.Delete:
+			err = c.Delete(ctx, op.Key)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *fakeStorageClient) Close(context.Context) error {
+	return nil
+}
+
+func TestEntityStoreAcrossRestarts(t *testing.T) {
+	logger := zap.NewNop()
+	cfg := createDefaultConfig().(*Config)
+	cfg.Receivers = map[component.ID]ReceiverEntry{
+		component.MustNewID("redis"): {
+			Rule: mustNewRule(`type == "port" && pod.name matches "(?i)redis" && port == 1`),
+		},
+		component.MustNewID("apache"): {
+			Rule: mustNewRule(`type == "hostport" && port == 1`),
+		},
+	}
+	obsID := component.MustNewIDWithName("observer_type", "observer.name")
+	storageID := component.MustNewID("file_storage")
+	host := mockHost{extensions: map[component.ID]component.Component{
+		storageID: &fakeStorage{client: &fakeStorageClient{content: map[string][]byte{}}},
+	}}
+
+	newTracker := func() *endpointTracker {
+		store, err := newEntityStore(context.Background(), host, storageID, component.MustNewID("discovery"), logger)
+		require.NoError(t, err)
+		require.NoError(t, store.load(context.Background()))
+		et := newEndpointTracker(nil, cfg, logger, make(chan plog.Logs, 10), newCorrelationStore(logger, cfg.CorrelationTTL))
+		et.store = store
+		return et
+	}
+
+	first := newTracker()
+	first.updateEndpoints([]observer.Endpoint{portEndpoint, hostportEndpoint}, obsID)
+	require.Empty(t, first.pLogs)
+	first.correlations.UpdateAttrs(portEndpoint.ID, map[string]string{
+		discovery.ReceiverTypeAttr: "redis",
+		discovery.ReceiverNameAttr: "",
+		discovery.StatusAttr:       "successful",
+	})
+	first.correlations.UpdateAttrs(hostportEndpoint.ID, map[string]string{
+		discovery.ReceiverTypeAttr: "apache",
+		discovery.ReceiverNameAttr: "",
+		discovery.StatusAttr:       "failed",
+	})
+	first.emitEntityStateEvents(obsID, []observer.Endpoint{portEndpoint, hostportEndpoint})
+	require.Len(t, first.pLogs, 1)
+	require.NoError(t, first.store.close(context.Background()))
+
+	// After a restart the status of reported endpoints is restored and emitted right away.
+	second := newTracker()
+	second.updateEndpoints([]observer.Endpoint{portEndpoint}, obsID)
+	require.Len(t, second.pLogs, 1)
+	stateLogs := <-second.pLogs
+	expectedEvents, failed, err := entityEvents(obsID, []observer.Endpoint{portEndpoint}, first.correlations, t0, experimentalmetricmetadata.EventTypeState)
+	require.NoError(t, err)
+	require.Zero(t, failed)
+	require.NoError(t, plogtest.CompareLogs(expectedEvents.ConvertAndMoveToLogs(), stateLogs, plogtest.IgnoreTimestamp()))
+
+	// Endpoints that weren't reported again are deleted.
+	second.emitVanishedEntityDeleteEvents()
+	require.Len(t, second.pLogs, 1)
+	deleteLogs := <-second.pLogs
+	expectedEvents, failed, err = entityEvents(obsID, []observer.Endpoint{hostportEndpoint}, first.correlations, t0, experimentalmetricmetadata.EventTypeDelete)
+	require.NoError(t, err)
+	require.Zero(t, failed)
+	require.NoError(t, plogtest.CompareLogs(expectedEvents.ConvertAndMoveToLogs(), deleteLogs, plogtest.IgnoreTimestamp()))
+	require.NoError(t, second.store.close(context.Background()))
+
+	third := newTracker()
+	assert.Equal(t, []string{string(portEndpoint.ID)}, func() []string {
+		var ids []string
+		for id := range third.store.restored {
+			ids = append(ids, string(id))
+		}
+		return ids
+	}())
+
+	// A restored status isn't applied to an endpoint matching another receiver.
+	attrs, ok := third.store.claimRestored(portEndpoint.ID, component.MustNewID("apache"))
+	assert.False(t, ok)
+	assert.Nil(t, attrs)
+}
+
+func TestEntityStorePersistsWithoutHoldingLock(t *testing.T) {
+	storageID := component.MustNewID("file_storage")
+	client := &fakeStorageClient{content: map[string][]byte{}}
+	host := mockHost{extensions: map[component.ID]component.Component{
+		storageID: &fakeStorage{client: client},
+	}}
+	store, err := newEntityStore(context.Background(), host, storageID, component.MustNewID("discovery"), zap.NewNop())
+	require.NoError(t, err)
+
+	setting := make(chan struct{})
+	release := make(chan struct{})
+	client.setting = func() {
+		setting <- struct{}{}
+		<-release
+	}
+	persisted := make(chan struct{})
+	go func() {
+		store.forget([]observer.Endpoint{portEndpoint})
+		close(persisted)
+	}()
+	<-setting
+
+	// The endpoints can be claimed while the entities are being written.
+	_, ok := store.claimRestored(portEndpoint.ID, component.MustNewID("redis"))
+	assert.False(t, ok)
+	close(release)
+	<-persisted
+
+	client.setting = nil
+	require.NoError(t, store.close(context.Background()))
+	store.persist()
+	assert.Equal(t, "{}", string(client.content[entitiesStorageKey]))
+}
+
+func TestNewEntityStoreInvalidExtension(t *testing.T) {
+	host := mockHost{extensions: map[component.ID]component.Component{
+		component.MustNewID("not_storage"): nopObserver{},
+	}}
+	_, err := newEntityStore(context.Background(), host, component.MustNewID("missing"), component.MustNewID("discovery"), zap.NewNop())
+	require.EqualError(t, err, `storage extension "missing" not found`)
+	_, err = newEntityStore(context.Background(), host, component.MustNewID("not_storage"), component.MustNewID("discovery"), zap.NewNop())
+	require.EqualError(t, err, `extension "not_storage" is not a storage extension`)
+}
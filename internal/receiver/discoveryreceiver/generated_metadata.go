@@ -18,6 +18,34 @@ package discoveryreceiver
 
 // receiverMetaMap contains the metadata for all receivers
 var receiverMetaMap = map[string]ReceiverMeta{
+	"smartagent/activemq": {
+		ServiceType: "activemq",
+		Status: Status{
+			Metrics: []Match{
+				{
+					Status:  "successful",
+					Strict:  "gauge.amq.TotalMessageCount",
+					Message: "activemq smartagent receiver is working!",
+				},
+			},
+			Statements: []Match{
+				{
+					Status:  "failed",
+					Regexp:  "Creating MBean server connection failed",
+					Message: "The Collector is unable to connect to activemq's JMX port. Make sure remote JMX is enabled and reachable.",
+				},
+				{
+					Status: "partial",
+					Regexp: "Authentication failed! Invalid username or password",
+					Message: "Make sure your JMX credentials are correctly specified as environment variables." +
+						"```" +
+						"SPLUNK_DISCOVERY_RECEIVERS_smartagent_x2f_activemq_CONFIG_username=\"<username>\"" +
+						"SPLUNK_DISCOVERY_RECEIVERS_smartagent_x2f_activemq_CONFIG_password=\"<password>\"" +
+						"```",
+				},
+			},
+		},
+	},
 	"apache": {
 		ServiceType: "apache",
 		Status: Status{
@@ -42,6 +70,140 @@ var receiverMetaMap = map[string]ReceiverMeta{
 			},
 		},
 	},
+	"smartagent/cassandra": {
+		ServiceType: "cassandra",
+		Status: Status{
+			Metrics: []Match{
+				{
+					Status:  "successful",
+					Strict:  "counter.cassandra.ClientRequest.Read.Latency.Count",
+					Message: "cassandra smartagent receiver is working!",
+				},
+			},
+			Statements: []Match{
+				{
+					Status:  "failed",
+					Regexp:  "Creating MBean server connection failed",
+					Message: "The Collector is unable to connect to cassandra's JMX port. Make sure remote JMX is enabled and reachable.",
+				},
+				{
+					Status: "partial",
+					Regexp: "Authentication failed! Invalid username or password",
+					Message: "Make sure your JMX credentials are correctly specified as environment variables." +
+						"```" +
+						"SPLUNK_DISCOVERY_RECEIVERS_smartagent_x2f_cassandra_CONFIG_username=\"<username>\"" +
+						"SPLUNK_DISCOVERY_RECEIVERS_smartagent_x2f_cassandra_CONFIG_password=\"<password>\"" +
+						"```",
+				},
+			},
+		},
+	},
+	"prometheus/consul": {
+		ServiceType: "consul",
+		Status: Status{
+			Metrics: []Match{
+				{
+					Status:  "successful",
+					Strict:  "consul_runtime_alloc_bytes",
+					Message: "consul prometheus receiver is working!",
+				},
+			},
+			Statements: []Match{
+				{
+					Status:  "failed",
+					Regexp:  "connection refused",
+					Message: "The container is not serving http connections.",
+				},
+				{
+					Status:  "failed",
+					Regexp:  "dial tcp: lookup",
+					Message: "Unable to resolve consul prometheus tcp endpoint",
+				},
+				{
+					Status:  "partial",
+					Regexp:  "server returned HTTP status 400 Bad Request",
+					Message: "Make sure the consul agent has prometheus metrics enabled with a positive `telemetry.prometheus_retention_time`.",
+				},
+				{
+					Status:  "partial",
+					Regexp:  "server returned HTTP status 403 Forbidden",
+					Message: "Make sure the consul ACL token used by the scrape config has `agent:read` permissions.",
+				},
+			},
+		},
+	},
+	"prometheus/couchdb": {
+		ServiceType: "couchdb",
+		Status: Status{
+			Metrics: []Match{
+				{
+					Status:  "successful",
+					Strict:  "couchdb_uptime_seconds",
+					Message: "couchdb prometheus receiver is working!",
+				},
+			},
+			Statements: []Match{
+				{
+					Status:  "failed",
+					Regexp:  "connection refused",
+					Message: "The container is not serving http connections.",
+				},
+				{
+					Status:  "failed",
+					Regexp:  "dial tcp: lookup",
+					Message: "Unable to resolve couchdb prometheus tcp endpoint",
+				},
+				{
+					Status:  "partial",
+					Regexp:  "server returned HTTP status 401 Unauthorized",
+					Message: "Make sure the couchdb admin credentials are correctly specified in the scrape config's `basic_auth` section of a config.d `prometheus/couchdb` receiver.",
+				},
+			},
+		},
+	},
+	"elasticsearch": {
+		ServiceType: "elasticsearch",
+		Status: Status{
+			Metrics: []Match{
+				{
+					Status:  "successful",
+					Strict:  "elasticsearch.cluster.health",
+					Message: "elasticsearch receiver is working!",
+				},
+			},
+			Statements: []Match{
+				{
+					Status:  "failed",
+					Regexp:  "connect: network is unreachable",
+					Message: "The container cannot be reached by the Collector. Make sure they're in the same network.",
+				},
+				{
+					Status:  "failed",
+					Regexp:  "connect: connection refused",
+					Message: "The container is refusing elasticsearch http connections.",
+				},
+				{
+					Status:  "failed",
+					Regexp:  "server gave HTTP response to HTTPS client",
+					Message: "The elasticsearch endpoint scheme doesn't match the server's http/https configuration.",
+				},
+				{
+					Status: "partial",
+					Regexp: "status 401, unauthenticated",
+					Message: "Make sure your user credentials are correctly specified as environment variables." +
+						"```" +
+						"SPLUNK_DISCOVERY_RECEIVERS_elasticsearch_CONFIG_username=\"<username>\"" +
+						"SPLUNK_DISCOVERY_RECEIVERS_elasticsearch_CONFIG_password=\"<password>\"" +
+						"```",
+				},
+				{
+					Status:  "partial",
+					Regexp:  "status 403, unauthorized",
+					Message: "Make sure the account used to access elasticsearch has the `monitor` or `manage` cluster privilege.",
+				},
+			},
+		},
+	},
 	"prometheus": {
 		ServiceType: "envoy",
 		Status: Status{
@@ -66,6 +228,67 @@ var receiverMetaMap = map[string]ReceiverMeta{
 			},
 		},
 	},
+	"prometheus/etcd": {
+		ServiceType: "etcd",
+		Status: Status{
+			Metrics: []Match{
+				{
+					Status:  "successful",
+					Strict:  "etcd_server_has_leader",
+					Message: "etcd prometheus receiver is working!",
+				},
+			},
+			Statements: []Match{
+				{
+					Status:  "failed",
+					Regexp:  "connection refused",
+					Message: "The container is not serving http connections.",
+				},
+				{
+					Status:  "failed",
+					Regexp:  "dial tcp: lookup",
+					Message: "Unable to resolve etcd prometheus tcp endpoint",
+				},
+				{
+					Status:  "failed",
+					Regexp:  "server returned HTTP status 400 Bad Request",
+					Message: "The etcd client endpoint requires TLS. Provide client certificates with a config.d `prometheus/etcd` receiver or expose plain http metrics with `--listen-metrics-urls`.",
+				},
+			},
+		},
+	},
+	"haproxy": {
+		ServiceType: "haproxy",
+		Status: Status{
+			Metrics: []Match{
+				{
+					Status:  "successful",
+					Strict:  "haproxy.sessions.count",
+					Message: "haproxy receiver is working!",
+				},
+			},
+			Statements: []Match{
+				{
+					Status:  "failed",
+					Regexp:  "connect: network is unreachable",
+					Message: "The container cannot be reached by the Collector. Make sure they're in the same network.",
+				},
+				{
+					Status:  "failed",
+					Regexp:  "connect: connection refused",
+					Message: "The container is not serving the haproxy stats page.",
+				},
+				{
+					Status: "partial",
+					Regexp: "unexpected status code",
+					Message: "Make sure haproxy serves its stats page at the configured endpoint, for example with a frontend containing `stats enable` and `stats uri /stats`." +
+						"```" +
+						"SPLUNK_DISCOVERY_RECEIVERS_haproxy_CONFIG_endpoint=\"<stats-uri>\"" +
+						"```",
+				},
+			},
+		},
+	},
 	"prometheus/istio": {
 		ServiceType: "istio",
 		Status: Status{
@@ -119,6 +342,35 @@ var receiverMetaMap = map[string]ReceiverMeta{
 			},
 		},
 	},
+	"memcached": {
+		ServiceType: "memcached",
+		Status: Status{
+			Metrics: []Match{
+				{
+					Status:  "successful",
+					Strict:  "memcached.connections.current",
+					Message: "memcached receiver is working!",
+				},
+			},
+			Statements: []Match{
+				{
+					Status:  "failed",
+					Regexp:  "connect: network is unreachable",
+					Message: "The container cannot be reached by the Collector. Make sure they're in the same network.",
+				},
+				{
+					Status:  "failed",
+					Regexp:  "connect: connection refused",
+					Message: "The container is refusing memcached connections.",
+				},
+				{
+					Status:  "failed",
+					Regexp:  "dial tcp: lookup",
+					Message: "Unable to resolve memcached tcp endpoint",
+				},
+			},
+		},
+	},
 	"mongodb": {
 		ServiceType: "mongodb",
 		Status: Status{
@@ -433,6 +685,40 @@ var receiverMetaMap = map[string]ReceiverMeta{
 			},
 		},
 	},
+	"prometheus/vault": {
+		ServiceType: "vault",
+		Status: Status{
+			Metrics: []Match{
+				{
+					Status:  "successful",
+					Strict:  "vault_core_unsealed",
+					Message: "vault prometheus receiver is working!",
+				},
+			},
+			Statements: []Match{
+				{
+					Status:  "failed",
+					Regexp:  "connection refused",
+					Message: "The container is not serving http connections.",
+				},
+				{
+					Status:  "failed",
+					Regexp:  "dial tcp: lookup",
+					Message: "Unable to resolve vault prometheus tcp endpoint",
+				},
+				{
+					Status:  "partial",
+					Regexp:  "server returned HTTP status 400 Bad Request",
+					Message: "Make sure the vault server has a `telemetry` stanza with a positive `prometheus_retention_time`.",
+				},
+				{
+					Status:  "partial",
+					Regexp:  "server returned HTTP status 403 Forbidden",
+					Message: "Make sure the vault listener sets `telemetry { unauthenticated_metrics_access = true }` or provide a token with `sys/metrics` read capabilities in a config.d `prometheus/vault` receiver.",
+				},
+			},
+		},
+	},
 	"prometheus/weaviate": {
 		ServiceType: "weaviate",
 		Status: Status{
@@ -457,4 +743,33 @@ var receiverMetaMap = map[string]ReceiverMeta{
 			},
 		},
 	},
+	"zookeeper": {
+		ServiceType: "zookeeper",
+		Status: Status{
+			Metrics: []Match{
+				{
+					Status:  "successful",
+					Strict:  "zookeeper.znode.count",
+					Message: "zookeeper receiver is working!",
+				},
+			},
+			Statements: []Match{
+				{
+					Status:  "failed",
+					Regexp:  "connect: network is unreachable",
+					Message: "The container cannot be reached by the Collector. Make sure they're in the same network.",
+				},
+				{
+					Status:  "failed",
+					Regexp:  "connect: connection refused",
+					Message: "The container is refusing zookeeper client connections.",
+				},
+				{
+					Status:  "partial",
+					Regexp:  "mntr is not executed because it is not in the whitelist",
+					Message: "Make sure the zookeeper server allows the `mntr` four letter word command, for example with `4lw.commands.whitelist=mntr,ruok` in zoo.cfg or the `ZOO_4LW_COMMANDS_WHITELIST` container environment variable.",
+				},
+			},
+		},
+	},
 }
@@ -162,6 +162,7 @@ func cleanupMetrics(md pmetric.Metrics) pmetric.Metrics {
 		attrs := md.ResourceMetrics().At(i).Resource().Attributes()
 		attrs.Remove(discovery.ReceiverTypeAttr)
 		attrs.Remove(discovery.ReceiverNameAttr)
+		attrs.Remove(discovery.ReceiverCandidateAttr)
 	}
 	return md
 }
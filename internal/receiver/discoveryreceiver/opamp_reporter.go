@@ -0,0 +1,246 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package discoveryreceiver
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/opampcustommessages"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	conventions "go.opentelemetry.io/otel/semconv/v1.22.0"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/common/discovery"
+)
+
+const (
+	// discoveryCapability is the OpAMP custom capability of the discovered endpoints summary messages.
+	discoveryCapability = "com.splunk.discovery"
+	// discoverySummaryMessageType is the custom message type of the JSON-encoded discoverySummary.
+	discoverySummaryMessageType = "summary"
+)
+
+// discoverySummary is the content of the custom messages reported to the OpAMP server.
+type discoverySummary struct {
+	// Statuses is the number of discovered endpoints by their discovery status.
+	Statuses  map[string]int    `json:"statuses"`
+	Endpoints []endpointSummary `json:"endpoints"`
+}
+
+// endpointSummary is a discovered endpoint and the status of the receiver created for it.
+type endpointSummary struct {
+	ID          string `json:"id"`
+	Observer    string `json:"observer"`
+	Type        string `json:"type"`
+	Target      string `json:"target"`
+	ServiceType string `json:"service_type,omitempty"`
+	ServiceName string `json:"service_name,omitempty"`
+	Receiver    string `json:"receiver"`
+	Status      string `json:"status"`
+	Message     string `json:"message,omitempty"`
+}
+
+// opampReporter maintains a summary of the discovered endpoints from the emitted entity events
+// and reports it to the OpAMP server through the custom capability handler of an OpAMP extension.
+type opampReporter struct {
+	handler opampcustommessages.CustomCapabilityHandler
+	logger  *zap.Logger
+	// endpoints are the summaries of the endpoints with an emitted entity state, keyed by entity ID.
+	endpoints map[string]endpointSummary
+	done      chan struct{}
+	mu        sync.Mutex
+	// pending is whether a message is waiting for a previous one to be sent.
+	pending bool
+	// changed is whether the summary has changed since it was last sent.
+	changed bool
+	closed  bool
+}
+
+// newOpAMPReporter registers the discovery custom capability with the configured OpAMP extension.
+func newOpAMPReporter(host component.Host, opampID component.ID, logger *zap.Logger) (*opampReporter, error) {
+	ext, ok := host.GetExtensions()[opampID]
+	if !ok {
+		return nil, fmt.Errorf("opamp extension %q not found", opampID)
+	}
+	registry, ok := ext.(opampcustommessages.CustomCapabilityRegistry)
+	if !ok {
+		return nil, fmt.Errorf("extension %q doesn't support OpAMP custom capabilities", opampID)
+	}
+	handler, err := registry.Register(discoveryCapability)
+	if err != nil {
+		return nil, fmt.Errorf("failed registering %q capability: %w", discoveryCapability, err)
+	}
+	return &opampReporter{
+		handler:   handler,
+		logger:    logger,
+		endpoints: map[string]endpointSummary{},
+		done:      make(chan struct{}),
+	}, nil
+}
+
+// consume updates the summary from the entity events in the provided logs and reports it if it changed.
+func (r *opampReporter) consume(ld plog.Logs) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := 0; i < ld.ResourceLogs().Len(); i++ {
+		sls := ld.ResourceLogs().At(i).ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			lrs := sls.At(j).LogRecords()
+			for k := 0; k < lrs.Len(); k++ {
+				if r.consumeEntityEvent(lrs.At(k).Attributes()) {
+					r.changed = true
+				}
+			}
+		}
+	}
+	if r.changed {
+		r.send()
+	}
+}
+
+// consumeEntityEvent applies an entity state or delete event to the summary and returns whether it changed.
+func (r *opampReporter) consumeEntityEvent(attrs pcommon.Map) bool {
+	eventType, ok := attrs.Get(discovery.OtelEntityEventTypeAttr)
+	if !ok {
+		return false
+	}
+	idVal, ok := attrs.Get(discovery.OtelEntityIDAttr)
+	if !ok || idVal.Type() != pcommon.ValueTypeMap {
+		return false
+	}
+	entityID := entityIDKey(idVal.Map())
+
+	switch eventType.Str() {
+	case discovery.OtelEntityEventTypeDelete:
+		if _, found := r.endpoints[entityID]; !found {
+			return false
+		}
+		delete(r.endpoints, entityID)
+		return true
+	case discovery.OtelEntityEventTypeState:
+		entityAttrsVal, found := attrs.Get(discovery.OtelEntityAttributesAttr)
+		if !found || entityAttrsVal.Type() != pcommon.ValueTypeMap {
+			return false
+		}
+		entityAttrs := entityAttrsVal.Map()
+		str := func(m pcommon.Map, key string) string {
+			if v, exists := m.Get(key); exists {
+				return v.AsString()
+			}
+			return ""
+		}
+		summary := endpointSummary{
+			ID:          str(entityAttrs, discovery.EndpointIDAttr),
+			Observer:    discovery.ComponentIDString(str(entityAttrs, observerTypeAttr), str(entityAttrs, observerNameAttr)),
+			Type:        str(entityAttrs, "type"),
+			Target:      str(entityAttrs, "endpoint"),
+			ServiceType: str(idVal.Map(), serviceTypeAttr),
+			ServiceName: str(idVal.Map(), string(conventions.ServiceNameKey)),
+			Receiver:    discovery.ComponentIDString(str(entityAttrs, discovery.ReceiverTypeAttr), str(entityAttrs, discovery.ReceiverNameAttr)),
+			Status:      str(entityAttrs, discovery.StatusAttr),
+			Message:     str(entityAttrs, discovery.MessageAttr),
+		}
+		if existing, found := r.endpoints[entityID]; found && existing == summary {
+			return false
+		}
+		r.endpoints[entityID] = summary
+		return true
+	}
+	return false
+}
+
+// send reports the current summary unless a previous message is still pending, in which case
+// the latest summary is reported once it has been sent.
+func (r *opampReporter) send() {
+	if r.pending || r.closed {
+		return
+	}
+	content, err := json.Marshal(r.summary())
+	if err != nil {
+		r.logger.Warn("failed marshaling discovery summary", zap.Error(err))
+		return
+	}
+	sent, err := r.handler.SendMessage(discoverySummaryMessageType, content)
+	if err != nil {
+		if sent == nil {
+			r.logger.Warn("failed sending discovery summary to the OpAMP server", zap.Error(err))
+			return
+		}
+		r.pending = true
+		go r.sendWhenReady(sent)
+		return
+	}
+	r.changed = false
+}
+
+// sendWhenReady waits until the pending message has been sent before reporting the latest summary.
+func (r *opampReporter) sendWhenReady(sent <-chan struct{}) {
+	select {
+	case <-sent:
+	case <-r.done:
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pending = false
+	if r.changed {
+		r.send()
+	}
+}
+
+// summary returns the current summary with endpoints sorted by ID and receiver.
+func (r *opampReporter) summary() discoverySummary {
+	s := discoverySummary{Statuses: map[string]int{}, Endpoints: []endpointSummary{}}
+	for _, endpoint := range r.endpoints {
+		s.Endpoints = append(s.Endpoints, endpoint)
+		s.Statuses[endpoint.Status]++
+	}
+	sort.Slice(s.Endpoints, func(i, j int) bool {
+		if s.Endpoints[i].ID != s.Endpoints[j].ID {
+			return s.Endpoints[i].ID < s.Endpoints[j].ID
+		}
+		return s.Endpoints[i].Receiver < s.Endpoints[j].Receiver
+	})
+	return s
+}
+
+// shutdown unregisters the discovery custom capability.
+func (r *opampReporter) shutdown() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return
+	}
+	r.closed = true
+	close(r.done)
+	r.handler.Unregister()
+}
+
+// entityIDKey returns a stable string form of the identifying attributes of an entity.
+func entityIDKey(id pcommon.Map) string {
+	keys := make([]string, 0, id.Len())
+	id.Range(func(k string, v pcommon.Value) bool {
+		keys = append(keys, k+"="+v.AsString())
+		return true
+	})
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
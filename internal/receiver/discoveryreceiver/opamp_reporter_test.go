@@ -0,0 +1,341 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package discoveryreceiver
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opamp-go/protobufs"
+	"github.com/open-telemetry/opamp-go/server"
+	"github.com/open-telemetry/opamp-go/server/types"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/opampcustommessages"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/opampextension"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/extension/extensiontest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/common/discovery"
+)
+
+var _ opampcustommessages.CustomCapabilityRegistry = (*fakeOpAMPServer)(nil)
+
+// fakeOpAMPServer stands in for an OpAMP extension and the server it's connected to: it registers
+// custom capabilities and records the custom messages the server receives for them.
+type fakeOpAMPServer struct {
+	nopObserver
+	// pending, when set, is returned with an error by SendMessage like for a message waiting to be sent.
+	pending      chan struct{}
+	capabilities []string
+	messages     []*protobufs.CustomMessage
+	mu           sync.Mutex
+}
+
+func (s *fakeOpAMPServer) Register(capability string, _ ...opampcustommessages.CustomCapabilityRegisterOption) (opampcustommessages.CustomCapabilityHandler, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.capabilities = append(s.capabilities, capability)
+	return &fakeCapabilityHandler{server: s, capability: capability}, nil
+}
+
+func (s *fakeOpAMPServer) received() []*protobufs.CustomMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.messages)
+}
+
+func (s *fakeOpAMPServer) registered() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.capabilities)
+}
+
+type fakeCapabilityHandler struct {
+	server     *fakeOpAMPServer
+	capability string
+}
+
+func (h *fakeCapabilityHandler) Message() <-chan *protobufs.CustomMessage {
+	return nil
+}
+
+func (h *fakeCapabilityHandler) SendMessage(messageType string, message []byte) (chan struct{}, error) {
+	h.server.mu.Lock()
+	defer h.server.mu.Unlock()
+	if h.server.pending != nil {
+		return h.server.pending, errors.New("custom message pending")
+	}
+	h.server.messages = append(h.server.messages, &protobufs.CustomMessage{
+		Capability: h.capability,
+		Type:       messageType,
+		Data:       message,
+	})
+	return nil, nil
+}
+
+func (h *fakeCapabilityHandler) Unregister() {
+	h.server.mu.Lock()
+	defer h.server.mu.Unlock()
+	h.server.capabilities = slices.DeleteFunc(h.server.capabilities, func(c string) bool {
+		return c == h.capability
+	})
+}
+
+// entityEventLogs returns the log record form of an entity event with the provided identifying and other attributes.
+func entityEventLogs(t *testing.T, eventType string, id, attrs map[string]any) plog.Logs {
+	logs := plog.NewLogs()
+	lr := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	lr.Attributes().PutStr(discovery.OtelEntityEventTypeAttr, eventType)
+	require.NoError(t, lr.Attributes().PutEmptyMap(discovery.OtelEntityIDAttr).FromRaw(id))
+	if attrs != nil {
+		require.NoError(t, lr.Attributes().PutEmptyMap(discovery.OtelEntityAttributesAttr).FromRaw(attrs))
+	}
+	return logs
+}
+
+func TestOpAMPReporter(t *testing.T) {
+	opampID := component.MustNewID("opamp")
+	server := &fakeOpAMPServer{}
+	host := mockHost{extensions: map[component.ID]component.Component{opampID: server}}
+
+	reporter, err := newOpAMPReporter(host, opampID, zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"com.splunk.discovery"}, server.registered())
+
+	redisID := map[string]any{"service.type": "redis", "service.name": "redis-cart", "k8s.pod.uid": "uid"}
+	redisState := entityEventLogs(t, discovery.OtelEntityEventTypeState, redisID, map[string]any{
+		discovery.EndpointIDAttr:        "k8s_observer/uid/redis(6379)",
+		observerTypeAttr:                "k8s_observer",
+		observerNameAttr:                "",
+		"type":                          "port",
+		"endpoint":                      "10.0.0.5:6379",
+		discovery.ReceiverTypeAttr:      "redis",
+		discovery.ReceiverNameAttr:      "",
+		discovery.StatusAttr:            "successful",
+		discovery.MessageAttr:           "redis receiver is working!",
+		"k8s.namespace.name":            "default",
+		discovery.ReceiverCandidateAttr: "0",
+	})
+	mysqlID := map[string]any{"service.type": "mysql", "service.name": "mysqld", "host.id": "host"}
+	mysqlState := entityEventLogs(t, discovery.OtelEntityEventTypeState, mysqlID, map[string]any{
+		discovery.EndpointIDAttr:   "host_observer/(3306)",
+		observerTypeAttr:           "host_observer",
+		observerNameAttr:           "local",
+		"type":                     "hostport",
+		"endpoint":                 "127.0.0.1:3306",
+		discovery.ReceiverTypeAttr: "mysql",
+		discovery.ReceiverNameAttr: "custom",
+		discovery.StatusAttr:       "partial",
+		discovery.MessageAttr:      "Make sure your user credentials are correctly specified.",
+	})
+
+	reporter.consume(redisState)
+	reporter.consume(mysqlState)
+	messages := server.received()
+	require.Len(t, messages, 2)
+	assert.Equal(t, "com.splunk.discovery", messages[1].Capability)
+	assert.Equal(t, "summary", messages[1].Type)
+	assert.JSONEq(t, `{
+  "statuses": {"successful": 1, "partial": 1},
+  "endpoints": [
+    {
+      "id": "host_observer/(3306)",
+      "observer": "host_observer/local",
+      "type": "hostport",
+      "target": "127.0.0.1:3306",
+      "service_type": "mysql",
+      "service_name": "mysqld",
+      "receiver": "mysql/custom",
+      "status": "partial",
+      "message": "Make sure your user credentials are correctly specified."
+    },
+    {
+      "id": "k8s_observer/uid/redis(6379)",
+      "observer": "k8s_observer",
+      "type": "port",
+      "target": "10.0.0.5:6379",
+      "service_type": "redis",
+      "service_name": "redis-cart",
+      "receiver": "redis",
+      "status": "successful",
+      "message": "redis receiver is working!"
+    }
+  ]
+}`, string(messages[1].Data))
+
+	// periodically emitted entity state events without changes aren't reported again
+	reporter.consume(redisState)
+	require.Len(t, server.received(), 2)
+
+	// the latest summary is reported once a pending message has been sent
+	pending := make(chan struct{})
+	server.mu.Lock()
+	server.pending = pending
+	server.mu.Unlock()
+	reporter.consume(entityEventLogs(t, discovery.OtelEntityEventTypeDelete, redisID, nil))
+	require.Len(t, server.received(), 2)
+	server.mu.Lock()
+	server.pending = nil
+	server.mu.Unlock()
+	close(pending)
+	require.Eventually(t, func() bool {
+		return len(server.received()) == 3
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.JSONEq(t, `{
+  "statuses": {"partial": 1},
+  "endpoints": [
+    {
+      "id": "host_observer/(3306)",
+      "observer": "host_observer/local",
+      "type": "hostport",
+      "target": "127.0.0.1:3306",
+      "service_type": "mysql",
+      "service_name": "mysqld",
+      "receiver": "mysql/custom",
+      "status": "partial",
+      "message": "Make sure your user credentials are correctly specified."
+    }
+  ]
+}`, string(server.received()[2].Data))
+
+	reporter.shutdown()
+	assert.Empty(t, server.registered())
+	reporter.consume(entityEventLogs(t, discovery.OtelEntityEventTypeDelete, mysqlID, nil))
+	require.Len(t, server.received(), 3)
+}
+
+func TestNewOpAMPReporterInvalidExtension(t *testing.T) {
+	host := mockHost{extensions: map[component.ID]component.Component{
+		component.MustNewID("not_opamp"): nopObserver{},
+	}}
+	_, err := newOpAMPReporter(host, component.MustNewID("missing"), zap.NewNop())
+	require.EqualError(t, err, `opamp extension "missing" not found`)
+	_, err = newOpAMPReporter(host, component.MustNewID("not_opamp"), zap.NewNop())
+	require.EqualError(t, err, `extension "not_opamp" doesn't support OpAMP custom capabilities`)
+}
+
+// startOpAMPServer starts an opamp-go server recording the discovery custom messages it receives.
+func startOpAMPServer(t *testing.T) (string, func() []*protobufs.CustomMessage) {
+	var mu sync.Mutex
+	var messages []*protobufs.CustomMessage
+	srv := server.New(nil)
+	handler, connContext, err := srv.Attach(server.Settings{
+		Callbacks: types.Callbacks{
+			OnConnecting: func(*http.Request) types.ConnectionResponse {
+				return types.ConnectionResponse{
+					Accept: true,
+					ConnectionCallbacks: types.ConnectionCallbacks{
+						OnMessage: func(_ context.Context, _ types.Connection, message *protobufs.AgentToServer) *protobufs.ServerToAgent {
+							if message.CustomMessage != nil && message.CustomMessage.Capability == discoveryCapability {
+								mu.Lock()
+								messages = append(messages, message.CustomMessage)
+								mu.Unlock()
+							}
+							return &protobufs.ServerToAgent{InstanceUid: message.InstanceUid}
+						},
+					},
+				}
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/opamp", handler)
+	httpSrv := httptest.NewUnstartedServer(mux)
+	httpSrv.Config.ConnContext = connContext
+	httpSrv.Start()
+	t.Cleanup(httpSrv.Close)
+
+	return "ws" + strings.TrimPrefix(httpSrv.URL, "http") + "/v1/opamp", func() []*protobufs.CustomMessage {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(messages)
+	}
+}
+
+func TestOpAMPReporterWithOpAMPExtension(t *testing.T) {
+	endpoint, received := startOpAMPServer(t)
+
+	factory := opampextension.NewFactory()
+	cfg := factory.CreateDefaultConfig()
+	require.NoError(t, confmap.NewFromStringMap(map[string]any{
+		"server": map[string]any{
+			"ws": map[string]any{"endpoint": endpoint},
+		},
+		"capabilities": map[string]any{"reports_available_components": false},
+	}).Unmarshal(cfg))
+	ext, err := factory.Create(t.Context(), extensiontest.NewNopSettings(factory.Type()), cfg)
+	require.NoError(t, err)
+	require.NoError(t, ext.Start(t.Context(), componenttest.NewNopHost()))
+	defer func() {
+		require.NoError(t, ext.Shutdown(context.Background()))
+	}()
+
+	opampID := component.NewID(factory.Type())
+	host := mockHost{extensions: map[component.ID]component.Component{opampID: ext}}
+	reporter, err := newOpAMPReporter(host, opampID, zap.NewNop())
+	require.NoError(t, err)
+	defer reporter.shutdown()
+
+	reporter.consume(entityEventLogs(t, discovery.OtelEntityEventTypeState,
+		map[string]any{"service.type": "redis", "service.name": "redis-cart", "k8s.pod.uid": "uid"},
+		map[string]any{
+			discovery.EndpointIDAttr:   "k8s_observer/uid/redis(6379)",
+			observerTypeAttr:           "k8s_observer",
+			observerNameAttr:           "",
+			"type":                     "port",
+			"endpoint":                 "10.0.0.5:6379",
+			discovery.ReceiverTypeAttr: "redis",
+			discovery.ReceiverNameAttr: "",
+			discovery.StatusAttr:       "successful",
+			discovery.MessageAttr:      "redis receiver is working!",
+		}))
+
+	require.Eventually(t, func() bool {
+		return len(received()) > 0
+	}, 10*time.Second, 10*time.Millisecond)
+	message := received()[0]
+	assert.Equal(t, "com.splunk.discovery", message.Capability)
+	assert.Equal(t, "summary", message.Type)
+	assert.JSONEq(t, `{
+  "statuses": {"successful": 1},
+  "endpoints": [
+    {
+      "id": "k8s_observer/uid/redis(6379)",
+      "observer": "k8s_observer",
+      "type": "port",
+      "target": "10.0.0.5:6379",
+      "service_type": "redis",
+      "service_name": "redis-cart",
+      "receiver": "redis",
+      "status": "successful",
+      "message": "redis receiver is working!"
+    }
+  ]
+}`, string(message.Data))
+}
@@ -43,6 +43,8 @@ type discoveryReceiver struct {
 	receiverCreator     receiver.Metrics
 	alreadyLogged       *sync.Map
 	endpointTracker     *endpointTracker
+	entityStore         *entityStore
+	opampReporter       *opampReporter
 	sentinel            chan struct{}
 	metricsConsumer     *metricsConsumer
 	statementEvaluator  *statementEvaluator
@@ -87,17 +89,45 @@ func (d *discoveryReceiver) Start(ctx context.Context, host component.Host) (err
 		return fmt.Errorf("failed obtaining observables from host: %w", err)
 	}
 
+	// receiver templates of candidates only match endpoints reported through the candidate host.
+	var candidates *candidateTracker
+	if d.config.hasCandidates() {
+		candidates = newCandidateTracker(d.logger, d.config)
+	}
+
+	if d.config.OpAMP != nil {
+		// The discovery mode sets the first opamp extension, which may not support the custom capabilities,
+		// so the discovered endpoints are still received without being reported.
+		reporter, reporterErr := newOpAMPReporter(host, *d.config.OpAMP, d.logger)
+		if reporterErr != nil {
+			d.logger.Warn("not reporting the discovered endpoints to the OpAMP server", zap.Error(reporterErr))
+		} else {
+			d.opampReporter = reporter
+		}
+	}
+
+	// entity events are emitted for the logs pipelines and the summary reported to the OpAMP server.
+	emitsEntityEvents := d.nextLogsConsumer != nil || d.opampReporter != nil
+
 	var correlations *correlationStore
-	if d.nextLogsConsumer != nil {
+	if emitsEntityEvents {
 		correlations = newCorrelationStore(d.logger, d.config.CorrelationTTL)
-		if d.nextLogsConsumer != nil {
-			d.endpointTracker = newEndpointTracker(d.observables, d.config, d.logger, d.pLogs, correlations)
-			d.endpointTracker.start()
+		if d.config.Storage != nil {
+			if d.entityStore, err = newEntityStore(ctx, host, *d.config.Storage, d.settings.ID, d.logger); err != nil {
+				return fmt.Errorf("failed creating entity store: %w", err)
+			}
+			if err = d.entityStore.load(ctx); err != nil {
+				d.logger.Warn("failed loading stored entities", zap.Error(err))
+			}
 		}
+		d.endpointTracker = newEndpointTracker(d.observables, d.config, d.logger, d.pLogs, correlations)
+		d.endpointTracker.store = d.entityStore
+		d.endpointTracker.start()
 
 		if d.statementEvaluator, err = newStatementEvaluator(d.logger, d.settings.ID, d.config, correlations); err != nil {
 			return fmt.Errorf("failed creating statement evaluator: %w", err)
 		}
+		d.statementEvaluator.candidates = candidates
 	}
 
 	d.metricsConsumer = newMetricsConsumer(d.logger, d.config, correlations, d.nextMetricsConsumer)
@@ -106,7 +136,7 @@ func (d *discoveryReceiver) Start(ctx context.Context, host component.Host) (err
 		return fmt.Errorf("failed creating internal receiver_creator: %w", err)
 	}
 
-	if d.nextLogsConsumer != nil {
+	if emitsEntityEvents {
 		loopStarted := &sync.WaitGroup{}
 		loopStarted.Add(1)
 		d.loopFinished.Add(1)
@@ -117,7 +147,11 @@ func (d *discoveryReceiver) Start(ctx context.Context, host component.Host) (err
 		d.logger.Debug("log consumer initializing initialized")
 	}
 
-	if err = d.receiverCreator.Start(ctx, host); err != nil {
+	receiverCreatorHost := host
+	if candidates != nil {
+		receiverCreatorHost = newCandidateHost(host, d.observables, candidates)
+	}
+	if err = d.receiverCreator.Start(ctx, receiverCreatorHost); err != nil {
 		return fmt.Errorf("failed starting internal receiver_creator: %w", err)
 	}
 	d.logger.Debug("started receiver_creator receiver")
@@ -140,6 +174,16 @@ func (d *discoveryReceiver) Shutdown(ctx context.Context) error {
 		}
 	}
 
+	if d.opampReporter != nil {
+		d.opampReporter.shutdown()
+	}
+
+	if d.entityStore != nil {
+		if err := d.entityStore.close(ctx); err != nil {
+			return fmt.Errorf("failed closing entity storage client: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -155,6 +199,12 @@ func (d *discoveryReceiver) consumerLoop(ctx context.Context, loopStarted *sync.
 			if !ok {
 				return
 			}
+			if d.opampReporter != nil {
+				d.opampReporter.consume(pLog)
+			}
+			if d.nextLogsConsumer == nil {
+				continue
+			}
 			obsCtx := d.obsreportReceiver.StartLogsOp(ctx)
 			err := d.nextLogsConsumer.ConsumeLogs(ctx, pLog)
 			if err != nil {
@@ -17,6 +17,7 @@ package discoveryreceiver
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/observer"
@@ -43,6 +44,9 @@ type statementEvaluator struct {
 	// Sampled to avoid flooding the logs with potential scraping errors.
 	sampledLogger *zap.Logger
 
+	// candidates tracks the receiver candidates of each endpoint, if any configured receivers have them.
+	candidates *candidateTracker
+
 	id component.ID
 }
 
@@ -154,7 +158,7 @@ func (se *statementEvaluator) Sync() error {
 func (se *statementEvaluator) evaluateStatement(statement *statussources.Statement) {
 	se.logger.Debug("evaluating statement", zap.Any("statement", statement))
 
-	receiverID, endpointID, shouldEvaluate := se.receiverEntryFromStatement(statement)
+	templateID, receiverID, candidate, endpointID, shouldEvaluate := se.receiverEntryFromStatement(statement)
 	if !shouldEvaluate {
 		return
 	}
@@ -188,13 +192,18 @@ func (se *statementEvaluator) evaluateStatement(statement *statussources.Stateme
 		if match.Strict != "" {
 			p = statement.Message
 		}
-		if shouldLog, err := se.evaluateMatch(match, p, match.Status, receiverID, endpointID); err != nil {
+		if shouldLog, err := se.evaluateMatch(match, p, match.Status, templateID, endpointID); err != nil {
 			se.logger.Info("Error evaluating statement match", zap.Error(err))
 			continue
 		} else if !shouldLog {
 			continue
 		}
 
+		// A partial status is usually the result of invalid credentials, so the next candidate is tried before reporting it.
+		if match.Status == discovery.Partial && se.candidates.retry(receiverID, endpointID, candidate) {
+			return
+		}
+
 		corr := se.correlations.GetOrCreate(endpointID, receiverID)
 		attrs := se.correlations.Attrs(endpointID)
 
@@ -206,6 +215,9 @@ func (se *statementEvaluator) evaluateStatement(statement *statussources.Stateme
 		se.correlateResourceAttributes(se.config, attrs, corr)
 		attrs[discovery.ReceiverTypeAttr] = receiverID.Type().String()
 		attrs[discovery.ReceiverNameAttr] = receiverID.Name()
+		if len(se.config.Receivers[receiverID].Candidates) > 0 {
+			attrs[discovery.ReceiverCandidateAttr] = strconv.Itoa(candidate)
+		}
 		attrs[discovery.MessageAttr] = match.Message
 
 		attrs[discovery.StatusAttr] = string(match.Status)
@@ -216,24 +228,32 @@ func (se *statementEvaluator) evaluateStatement(statement *statussources.Stateme
 	}
 }
 
-func (se *statementEvaluator) receiverEntryFromStatement(statement *statussources.Statement) (component.ID, observer.EndpointID, bool) {
-	receiverID, endpointID := statussources.ReceiverNameToIDs(statement)
-	if receiverID == discovery.NoType || endpointID == "" {
+// receiverEntryFromStatement returns the receiver creator template ID of the statement's receiver along with
+// its configured receiver ID, candidate index, and endpoint ID.
+func (se *statementEvaluator) receiverEntryFromStatement(statement *statussources.Statement) (component.ID, component.ID, int, observer.EndpointID, bool) {
+	templateID, endpointID := statussources.ReceiverNameToIDs(statement)
+	if templateID == discovery.NoType || endpointID == "" {
 		// statement evaluation requires both a populated receiver.ID and EndpointID
-		se.logger.Debug("unable to evaluate statement from receiver", zap.String("receiver", receiverID.String()))
-		return discovery.NoType, "", false
+		se.logger.Debug("unable to evaluate statement from receiver", zap.String("receiver", templateID.String()))
+		return discovery.NoType, discovery.NoType, 0, "", false
 	}
 
+	receiverID, candidate := se.config.receiverCandidateFromTemplateID(templateID)
 	_, ok := se.config.Receivers[receiverID]
 	if !ok {
 		se.logger.Info("No matching configured receiver for statement status evaluation", zap.String("receiver", receiverID.String()))
-		return discovery.NoType, "", false
+		return discovery.NoType, discovery.NoType, 0, "", false
 	}
 
 	_, hasMeta := receiverMetaMap[receiverID.String()]
 	if !hasMeta {
-		return discovery.NoType, "", false
+		return discovery.NoType, discovery.NoType, 0, "", false
+	}
+
+	if !se.candidates.isCurrent(receiverID, endpointID, candidate) {
+		// the statement is from the receiver of a previous candidate that is being shut down
+		return discovery.NoType, discovery.NoType, 0, "", false
 	}
 
-	return receiverID, endpointID, true
+	return templateID, receiverID, candidate, endpointID, true
 }
@@ -15,6 +15,7 @@
 package settings
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -35,6 +36,7 @@ import (
 
 	"github.com/signalfx/splunk-otel-collector/internal/configconverter"
 	"github.com/signalfx/splunk-otel-collector/internal/confmapprovider/discovery"
+	"github.com/signalfx/splunk-otel-collector/pkg/modularinput"
 )
 
 // envVarWarnings is a map of warnings to be logged when a specific environment variable is used in a user config.
@@ -107,6 +109,9 @@ type Settings struct {
 	configD                 bool
 	discoveryMode           bool
 	dryRun                  bool
+	explain                 bool
+	migrateSmartAgent       bool
+	lintWarnings            bool
 }
 
 func newSettings() *Settings {
@@ -237,6 +242,21 @@ func (s *Settings) IsDryRun() bool {
 	return s.dryRun
 }
 
+// IsExplain returns whether --explain mode was requested
+func (s *Settings) IsExplain() bool {
+	return s.explain
+}
+
+// IsMigrateSmartAgent returns whether --migrate-smartagent mode was requested
+func (s *Settings) IsMigrateSmartAgent() bool {
+	return s.migrateSmartAgent
+}
+
+// IsLintWarnings returns whether the config lint findings should be logged at startup
+func (s *Settings) IsLintWarnings() bool {
+	return s.lintWarnings
+}
+
 // parseArgs returns new Settings instance from command line arguments.
 func parseArgs(args []string) (*Settings, error) {
 	flagSet := flag.NewFlagSet("otelcol", flag.ContinueOnError)
@@ -256,6 +276,14 @@ func parseArgs(args []string) (*Settings, error) {
 		"Array config properties are overridden and maps are joined. Example --set=processors.batch.timeout=2s")
 	flagSet.BoolVar(&settings.dryRun, "dry-run", false, "Don't run the service, just show the configuration")
 	flagSet.MarkHidden("dry-run")
+	flagSet.BoolVar(&settings.explain, "explain", false,
+		"Don't run the service, just show the configuration with the origin of each value")
+	flagSet.MarkHidden("explain")
+	flagSet.BoolVar(&settings.migrateSmartAgent, "migrate-smartagent", false,
+		"Don't run the service, just show how the smartagent receivers would be migrated to native receivers")
+	flagSet.MarkHidden("migrate-smartagent")
+	flagSet.BoolVar(&settings.lintWarnings, "lint-warnings", false,
+		"Log the findings of the lint command for the configuration as warnings when starting the service.")
 	flagSet.BoolVar(&settings.noConvertConfig, "no-convert-config", false,
 		"Do not translate old configurations to the new format automatically. "+
 			"By default, old configurations are translated to the new format for backward compatibility.")
@@ -297,6 +325,13 @@ func parseArgs(args []string) (*Settings, error) {
 		return nil, err
 	}
 
+	if settings.dryRun && settings.explain {
+		return nil, errors.New("--dry-run and --explain can't be used together")
+	}
+	if settings.migrateSmartAgent && (settings.dryRun || settings.explain) {
+		return nil, errors.New("--migrate-smartagent can't be used with --dry-run or --explain")
+	}
+
 	setDefaultFeatureGates(flagSet)
 
 	if settings.discoveryPropertiesFile.value != nil {
@@ -324,7 +359,9 @@ func writeUsage(flagSet *flag.FlagSet) {
   otelcol [command]
 
 Available Commands:
+  discover     Run discovery and report the discovered endpoints
   featuregate  Display feature gates information
+  lint         Lint the config and report risky or unused settings
   validate     Validates the config without running the collector
 
 Flags:
@@ -450,6 +487,10 @@ func setDefaultEnvVars(s *Settings) error {
 
 	if _, ok := os.LookupEnv(FileStorageExtensionPathEnvVar); !ok {
 		defaultEnvVars[FileStorageExtensionPathEnvVar] = "/var/lib/otelcol/filelogs"
+		// When running as a Splunk modular input, default to the Splunk managed checkpoint directory.
+		if checkpointDir := os.Getenv(modularinput.EnvCheckpointDir); checkpointDir != "" {
+			defaultEnvVars[FileStorageExtensionPathEnvVar] = checkpointDir
+		}
 	}
 
 	for e, v := range defaultEnvVars {
@@ -94,7 +94,9 @@ func TestWriteUsage(t *testing.T) {
 
 	usage := output.String()
 	require.Contains(t, usage, `Available Commands:
+  discover     Run discovery and report the discovered endpoints
   featuregate  Display feature gates information
+  lint         Lint the config and report risky or unused settings
   validate     Validates the config without running the collector
 
 Flags:
@@ -158,6 +160,42 @@ func TestNewSettingsNoConvertConfig(t *testing.T) {
 	require.Equal(t, []string{"--feature-gates", "foo", "--feature-gates", "-bar"}, settings.ColCoreArgs())
 }
 
+func TestNewSettingsExplain(t *testing.T) {
+	t.Cleanup(clearEnv(t))
+	settings, err := New([]string{"--explain", "--config", configPath})
+	require.NoError(t, err)
+	require.True(t, settings.IsExplain())
+	require.False(t, settings.IsDryRun())
+
+	settings, err = New([]string{"--explain", "--dry-run", "--config", configPath})
+	require.EqualError(t, err, "--dry-run and --explain can't be used together")
+	require.Nil(t, settings)
+}
+
+func TestNewSettingsMigrateSmartAgent(t *testing.T) {
+	t.Cleanup(clearEnv(t))
+	settings, err := New([]string{"--migrate-smartagent", "--config", configPath})
+	require.NoError(t, err)
+	require.True(t, settings.IsMigrateSmartAgent())
+	require.False(t, settings.IsDryRun())
+
+	settings, err = New([]string{"--migrate-smartagent", "--dry-run", "--config", configPath})
+	require.EqualError(t, err, "--migrate-smartagent can't be used with --dry-run or --explain")
+	require.Nil(t, settings)
+}
+
+func TestNewSettingsLintWarnings(t *testing.T) {
+	t.Cleanup(clearEnv(t))
+	settings, err := New([]string{"--config", configPath})
+	require.NoError(t, err)
+	require.False(t, settings.IsLintWarnings())
+
+	settings, err = New([]string{"--lint-warnings", "--config", configPath})
+	require.NoError(t, err)
+	require.True(t, settings.IsLintWarnings())
+	require.NotContains(t, settings.ColCoreArgs(), "--lint-warnings")
+}
+
 func TestNewSettingsConvertConfig(t *testing.T) {
 	t.Cleanup(clearEnv(t))
 	settings, err := New([]string{
@@ -853,6 +891,15 @@ func TestSetDefaultEnvVarsFileStorageExtension(t *testing.T) {
 	require.Equal(t, "/var/lib/otelcol/filelogs", path)
 }
 
+func TestSetDefaultEnvVarsFileStorageExtensionModularInput(t *testing.T) {
+	t.Cleanup(clearEnv(t))
+	t.Setenv("SPLUNK_MODINPUT_CHECKPOINT_DIR", "/opt/splunk/var/lib/splunk/modinputs/Splunk_TA_otel")
+	require.NoError(t, setDefaultEnvVars(nil))
+	path, ok := os.LookupEnv("SPLUNK_FILE_STORAGE_EXTENSION_PATH")
+	require.True(t, ok, "Expected SPLUNK_FILE_STORAGE_EXTENSION_PATH set by default")
+	require.Equal(t, "/opt/splunk/var/lib/splunk/modinputs/Splunk_TA_otel", path)
+}
+
 func TestSetNonDefaultEnvVarsFileStorageExtension(t *testing.T) {
 	t.Cleanup(clearEnv(t))
 	nonDefaultPath := "/var/non/default/path"
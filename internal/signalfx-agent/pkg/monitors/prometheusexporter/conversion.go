@@ -2,11 +2,11 @@ package prometheusexporter
 
 import (
 	"strconv"
+	"time"
 
 	dto "github.com/prometheus/client_model/go"
+	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/pmetric"
-
-	"github.com/signalfx/signalfx-agent/pkg/utils"
 )
 
 type extractor func(m *dto.Metric) float64
@@ -23,63 +23,91 @@ func counterExtractor(m *dto.Metric) float64 {
 	return m.GetCounter().GetValue()
 }
 
-func convertMetricFamily(mf *dto.MetricFamily) []pmetric.Metric {
+// convertMetricFamily converts a metric family to metrics with a datapoint per
+// series, timestamped with the series timestamp if any or the provided scrape
+// time otherwise.
+func convertMetricFamily(mf *dto.MetricFamily, now pcommon.Timestamp) []pmetric.Metric {
 	//nolint:protogetter
-	if mf.Type == nil || mf.Name == nil {
+	if mf.Type == nil || mf.Name == nil || len(mf.GetMetric()) == 0 {
 		return nil
 	}
 	switch *mf.Type { //nolint:protogetter
 	case dto.MetricType_GAUGE:
-		return makeSimpleDatapoints(mf.GetName(), mf.GetMetric(), gaugeExtractor)
+		return makeSimpleDatapoints(mf.GetName(), mf.GetMetric(), gaugeExtractor, now)
 	case dto.MetricType_COUNTER:
-		return makeSimpleCumulativeSum(mf.GetName(), mf.GetMetric(), counterExtractor)
+		return makeSimpleCumulativeSum(mf.GetName(), mf.GetMetric(), counterExtractor, now)
 	case dto.MetricType_UNTYPED:
-		return makeSimpleDatapoints(mf.GetName(), mf.GetMetric(), untypedExtractor)
+		return makeSimpleDatapoints(mf.GetName(), mf.GetMetric(), untypedExtractor, now)
 	case dto.MetricType_SUMMARY:
-		return makeSummaryDatapoints(mf.GetName(), mf.GetMetric())
+		return makeSummaryDatapoints(mf.GetName(), mf.GetMetric(), now)
 	// TODO: figure out how to best convert histograms, in particular the
 	// upper bound value
 	case dto.MetricType_HISTOGRAM:
-		return makeHistogramDatapoints(mf.GetName(), mf.GetMetric())
+		return makeHistogramDatapoints(mf.GetName(), mf.GetMetric(), now)
 	default:
 		return nil
 	}
 }
 
-func makeSimpleCumulativeSum(name string, ms []*dto.Metric, e extractor) []pmetric.Metric {
-	dps := make([]pmetric.Metric, len(ms))
-	for i, m := range ms {
-		metric := pmetric.NewMetric()
-		metric.SetName(name)
-		s := metric.SetEmptySum()
-		s.SetIsMonotonic(true)
-		s.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
-		dp := s.DataPoints().AppendEmpty()
-		dp.SetDoubleValue(e(m))
-		_ = dp.Attributes().FromRaw(labelsToDims(m.GetLabel()))
-		dps[i] = metric
+func newGauge(name string) (pmetric.Metric, pmetric.NumberDataPointSlice) {
+	metric := pmetric.NewMetric()
+	metric.SetName(name)
+	return metric, metric.SetEmptyGauge().DataPoints()
+}
+
+func newCumulativeSum(name string) (pmetric.Metric, pmetric.NumberDataPointSlice) {
+	metric := pmetric.NewMetric()
+	metric.SetName(name)
+	s := metric.SetEmptySum()
+	s.SetIsMonotonic(true)
+	s.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	return metric, s.DataPoints()
+}
+
+// appendDatapoint appends a datapoint for the series to dps with its labels
+// and the provided extra label as attributes.
+func appendDatapoint(dps pmetric.NumberDataPointSlice, m *dto.Metric, now pcommon.Timestamp, extraLabel, extraValue string) pmetric.NumberDataPoint {
+	dp := dps.AppendEmpty()
+	if ts := m.GetTimestampMs(); ts != 0 {
+		dp.SetTimestamp(pcommon.Timestamp(ts * int64(time.Millisecond))) //nolint:gosec // disable G115
+	} else {
+		dp.SetTimestamp(now)
+	}
+	labels := m.GetLabel()
+	attrs := dp.Attributes()
+	attrs.EnsureCapacity(len(labels) + 1)
+	for i := range labels {
+		attrs.PutStr(labels[i].GetName(), labels[i].GetValue())
+	}
+	if extraLabel != "" {
+		attrs.PutStr(extraLabel, extraValue)
 	}
-	return dps
+	return dp
 }
 
-func makeSimpleDatapoints(name string, ms []*dto.Metric, e extractor) []pmetric.Metric {
-	dps := make([]pmetric.Metric, len(ms))
-	for i, m := range ms {
-		metric := pmetric.NewMetric()
-		metric.SetName(name)
-		g := metric.SetEmptyGauge()
-		dp := g.DataPoints().AppendEmpty()
-		dp.SetDoubleValue(e(m))
-		_ = dp.Attributes().FromRaw(labelsToDims(m.GetLabel()))
-		dps[i] = metric
+func makeSimpleCumulativeSum(name string, ms []*dto.Metric, e extractor, now pcommon.Timestamp) []pmetric.Metric {
+	metric, dps := newCumulativeSum(name)
+	dps.EnsureCapacity(len(ms))
+	for _, m := range ms {
+		appendDatapoint(dps, m, now, "", "").SetDoubleValue(e(m))
 	}
-	return dps
+	return []pmetric.Metric{metric}
 }
 
-func makeSummaryDatapoints(name string, ms []*dto.Metric) []pmetric.Metric {
-	var dps []pmetric.Metric
+func makeSimpleDatapoints(name string, ms []*dto.Metric, e extractor, now pcommon.Timestamp) []pmetric.Metric {
+	metric, dps := newGauge(name)
+	dps.EnsureCapacity(len(ms))
+	for _, m := range ms {
+		appendDatapoint(dps, m, now, "", "").SetDoubleValue(e(m))
+	}
+	return []pmetric.Metric{metric}
+}
+
+func makeSummaryDatapoints(name string, ms []*dto.Metric, now pcommon.Timestamp) []pmetric.Metric {
+	count, countDps := newCumulativeSum(name + "_count")
+	sum, sumDps := newCumulativeSum(name)
+	quantile, quantileDps := newGauge(name + "_quantile")
 	for _, m := range ms {
-		dims := labelsToDims(m.GetLabel())
 		s := m.GetSummary()
 		if s == nil {
 			continue
@@ -87,51 +115,28 @@ func makeSummaryDatapoints(name string, ms []*dto.Metric) []pmetric.Metric {
 
 		//nolint:protogetter
 		if s.SampleCount != nil {
-			metric := pmetric.NewMetric()
-			metric.SetName(name + "_count")
-			sum := metric.SetEmptySum()
-			sum.SetIsMonotonic(true)
-			sum.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
-			dp := sum.DataPoints().AppendEmpty()
-			dp.SetIntValue(int64(s.GetSampleCount())) //nolint:gosec // disable G115
-			_ = dp.Attributes().FromRaw(dims)
-			dps = append(dps, metric)
+			appendDatapoint(countDps, m, now, "", "").SetIntValue(int64(s.GetSampleCount())) //nolint:gosec // disable G115
 		}
 
 		//nolint:protogetter
 		if s.SampleSum != nil {
-			metric := pmetric.NewMetric()
-			metric.SetName(name)
-			sum := metric.SetEmptySum()
-			sum.SetIsMonotonic(true)
-			sum.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
-			dp := sum.DataPoints().AppendEmpty()
-			dp.SetDoubleValue(s.GetSampleSum()) //nolint:gosec // disable G115
-			_ = dp.Attributes().FromRaw(dims)
-			dps = append(dps, metric)
+			appendDatapoint(sumDps, m, now, "", "").SetDoubleValue(s.GetSampleSum())
 		}
 
 		qs := s.GetQuantile()
 		for i := range qs {
-			quantileDims := utils.MergeMaps(dims, map[string]any{
-				"quantile": strconv.FormatFloat(qs[i].GetQuantile(), 'f', 6, 64),
-			})
-			metric := pmetric.NewMetric()
-			metric.SetName(name + "_quantile")
-			g := metric.SetEmptyGauge()
-			dp := g.DataPoints().AppendEmpty()
-			dp.SetDoubleValue(qs[i].GetValue())
-			_ = dp.Attributes().FromRaw(quantileDims)
-			dps = append(dps, metric)
+			quantileValue := strconv.FormatFloat(qs[i].GetQuantile(), 'f', 6, 64)
+			appendDatapoint(quantileDps, m, now, "quantile", quantileValue).SetDoubleValue(qs[i].GetValue())
 		}
 	}
-	return dps
+	return nonEmptyMetrics(count, sum, quantile)
 }
 
-func makeHistogramDatapoints(name string, ms []*dto.Metric) []pmetric.Metric {
-	var dps []pmetric.Metric
+func makeHistogramDatapoints(name string, ms []*dto.Metric, now pcommon.Timestamp) []pmetric.Metric {
+	count, countDps := newCumulativeSum(name + "_count")
+	sum, sumDps := newCumulativeSum(name)
+	bucket, bucketDps := newCumulativeSum(name + "_bucket")
 	for _, m := range ms {
-		dims := labelsToDims(m.GetLabel())
 		h := m.GetHistogram()
 		if h == nil {
 			continue
@@ -139,53 +144,31 @@ func makeHistogramDatapoints(name string, ms []*dto.Metric) []pmetric.Metric {
 
 		//nolint:protogetter
 		if h.SampleCount != nil {
-			metric := pmetric.NewMetric()
-			metric.SetName(name + "_count")
-			sum := metric.SetEmptySum()
-			sum.SetIsMonotonic(true)
-			sum.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
-			dp := sum.DataPoints().AppendEmpty()
-			dp.SetIntValue(int64(h.GetSampleCount())) //nolint:gosec // disable G115
-			_ = dp.Attributes().FromRaw(dims)
-			dps = append(dps, metric)
+			appendDatapoint(countDps, m, now, "", "").SetIntValue(int64(h.GetSampleCount())) //nolint:gosec // disable G115
 		}
 
 		//nolint:protogetter
 		if h.SampleSum != nil {
-			metric := pmetric.NewMetric()
-			metric.SetName(name)
-			sum := metric.SetEmptySum()
-			sum.SetIsMonotonic(true)
-			sum.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
-			dp := sum.DataPoints().AppendEmpty()
-			dp.SetDoubleValue(h.GetSampleSum()) //nolint:gosec // disable G115
-			_ = dp.Attributes().FromRaw(dims)
-			dps = append(dps, metric)
+			appendDatapoint(sumDps, m, now, "", "").SetDoubleValue(h.GetSampleSum())
 		}
 
 		buckets := h.GetBucket()
 		for i := range buckets {
-			bucketDims := utils.MergeMaps(dims, map[string]any{
-				"upper_bound": strconv.FormatFloat(buckets[i].GetUpperBound(), 'f', 6, 64),
-			})
-			metric := pmetric.NewMetric()
-			metric.SetName(name + "_bucket")
-			sum := metric.SetEmptySum()
-			sum.SetIsMonotonic(true)
-			sum.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
-			dp := sum.DataPoints().AppendEmpty()
-			dp.SetIntValue(int64(buckets[i].GetCumulativeCount())) //nolint:gosec // disable G115
-			_ = dp.Attributes().FromRaw(bucketDims)
-			dps = append(dps, metric)
+			upperBound := strconv.FormatFloat(buckets[i].GetUpperBound(), 'f', 6, 64)
+			appendDatapoint(bucketDps, m, now, "upper_bound", upperBound).SetIntValue(int64(buckets[i].GetCumulativeCount())) //nolint:gosec // disable G115
 		}
 	}
-	return dps
+	return nonEmptyMetrics(count, sum, bucket)
 }
 
-func labelsToDims(labels []*dto.LabelPair) map[string]any {
-	dims := map[string]any{}
-	for i := range labels {
-		dims[labels[i].GetName()] = labels[i].GetValue()
+// nonEmptyMetrics returns the provided gauge and sum metrics that have datapoints.
+func nonEmptyMetrics(metrics ...pmetric.Metric) []pmetric.Metric {
+	out := metrics[:0]
+	for _, m := range metrics {
+		if (m.Type() == pmetric.MetricTypeGauge && m.Gauge().DataPoints().Len() > 0) ||
+			(m.Type() == pmetric.MetricTypeSum && m.Sum().DataPoints().Len() > 0) {
+			out = append(out, m)
+		}
 	}
-	return dims
+	return out
 }
@@ -0,0 +1,85 @@
+package prometheusexporter
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+const exposition = `# TYPE http_requests_total counter
+http_requests_total{method="get",code="200"} 1027 1395066363000
+http_requests_total{method="post",code="200"} 3
+# TYPE temperature gauge
+temperature{room="a"} 21.5
+temperature{room="b"} 19
+# TYPE rpc_duration_seconds summary
+rpc_duration_seconds{quantile="0.5"} 0.05
+rpc_duration_seconds{quantile="0.99"} 0.2
+rpc_duration_seconds_sum 17
+rpc_duration_seconds_count 42
+# TYPE request_size_bytes histogram
+request_size_bytes_bucket{le="100"} 5
+request_size_bytes_bucket{le="+Inf"} 8
+request_size_bytes_sum 1200
+request_size_bytes_count 8
+`
+
+func TestConvertMetricFamily(t *testing.T) {
+	parser := expfmt.NewTextParser(model.UTF8Validation)
+	families, err := parser.TextToMetricFamilies(strings.NewReader(exposition))
+	require.NoError(t, err)
+
+	now := pcommon.NewTimestampFromTime(time.Now())
+	metrics := map[string]pmetric.Metric{}
+	for _, mf := range families {
+		for _, m := range convertMetricFamily(mf, now) {
+			metrics[m.Name()] = m
+		}
+	}
+	require.Len(t, metrics, 8)
+
+	requests := metrics["http_requests_total"]
+	require.Equal(t, pmetric.MetricTypeSum, requests.Type())
+	assert.True(t, requests.Sum().IsMonotonic())
+	require.Equal(t, 2, requests.Sum().DataPoints().Len())
+	dp := requests.Sum().DataPoints().At(0)
+	assert.Equal(t, map[string]any{"method": "get", "code": "200"}, dp.Attributes().AsRaw())
+	assert.Equal(t, 1027.0, dp.DoubleValue())
+	assert.Equal(t, pcommon.NewTimestampFromTime(time.UnixMilli(1395066363000)), dp.Timestamp())
+	assert.Equal(t, now, requests.Sum().DataPoints().At(1).Timestamp())
+
+	temperature := metrics["temperature"]
+	require.Equal(t, pmetric.MetricTypeGauge, temperature.Type())
+	require.Equal(t, 2, temperature.Gauge().DataPoints().Len())
+	assert.Equal(t, 19.0, temperature.Gauge().DataPoints().At(1).DoubleValue())
+
+	assert.Equal(t, int64(42), metrics["rpc_duration_seconds_count"].Sum().DataPoints().At(0).IntValue())
+	assert.Equal(t, 17.0, metrics["rpc_duration_seconds"].Sum().DataPoints().At(0).DoubleValue())
+	quantiles := metrics["rpc_duration_seconds_quantile"].Gauge().DataPoints()
+	require.Equal(t, 2, quantiles.Len())
+	assert.Equal(t, map[string]any{"quantile": "0.990000"}, quantiles.At(1).Attributes().AsRaw())
+	assert.Equal(t, 0.2, quantiles.At(1).DoubleValue())
+
+	assert.Equal(t, int64(8), metrics["request_size_bytes_count"].Sum().DataPoints().At(0).IntValue())
+	assert.Equal(t, 1200.0, metrics["request_size_bytes"].Sum().DataPoints().At(0).DoubleValue())
+	buckets := metrics["request_size_bytes_bucket"].Sum().DataPoints()
+	require.Equal(t, 2, buckets.Len())
+	assert.Equal(t, map[string]any{"upper_bound": "100.000000"}, buckets.At(0).Attributes().AsRaw())
+	assert.Equal(t, int64(5), buckets.At(0).IntValue())
+}
+
+func TestConvertMetricFamilyWithoutSeries(t *testing.T) {
+	parser := expfmt.NewTextParser(model.UTF8Validation)
+	families, err := parser.TextToMetricFamilies(strings.NewReader("# TYPE empty summary\n"))
+	require.NoError(t, err)
+	for _, mf := range families {
+		assert.Empty(t, convertMetricFamily(mf, 0))
+	}
+}
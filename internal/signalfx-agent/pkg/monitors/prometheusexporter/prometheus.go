@@ -12,6 +12,7 @@ import (
 	dto "github.com/prometheus/client_model/go"
 	"github.com/prometheus/common/expfmt"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/pmetric"
 	"k8s.io/client-go/rest"
 
@@ -148,14 +149,14 @@ func (m *Monitor) Configure(conf *Config) error {
 	var ctx context.Context
 	ctx, m.cancel = context.WithCancel(context.Background())
 	utils.RunOnInterval(ctx, func() {
-		dps, err := fetchPrometheusMetrics(fetch)
+		metrics, err := fetchPrometheusMetrics(fetch)
 		if err != nil {
 			// The default log level is error, users can configure which level to use
 			m.logger.WithError(err).Log(conf.scrapeFailureLogrusLevel, "Could not get prometheus metrics")
 			return
 		}
 
-		m.Output.SendMetrics(dps...)
+		m.Output.SendMetrics(metrics...)
 	}, time.Duration(conf.IntervalSeconds)*time.Second)
 
 	return nil
@@ -167,11 +168,12 @@ func fetchPrometheusMetrics(fetch fetcher) ([]pmetric.Metric, error) {
 		return nil, err
 	}
 
-	var dps []pmetric.Metric
+	now := pcommon.NewTimestampFromTime(time.Now())
+	var metrics []pmetric.Metric
 	for i := range metricFamilies {
-		dps = append(dps, convertMetricFamily(metricFamilies[i])...)
+		metrics = append(metrics, convertMetricFamily(metricFamilies[i], now)...)
 	}
-	return dps, nil
+	return metrics, nil
 }
 
 func doFetch(fetch fetcher) ([]*dto.MetricFamily, error) {
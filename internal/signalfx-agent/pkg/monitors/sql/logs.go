@@ -0,0 +1,248 @@
+package sql
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/signalfx/golib/v3/event" //nolint:staticcheck // SA1019: deprecated package still in use
+)
+
+// The property holding the body of the log records
+const bodyProperty = "body"
+
+// Layouts of the timestamps in string columns, as set by the drivers or by
+// the SQLite datetime functions.
+var timestampLayouts = []string{time.RFC3339Nano, time.DateTime}
+
+// logEmitter converts the rows of a query result to log records, skipping the
+// ones already emitted according to the tracking column of the query.
+type logEmitter struct {
+	logs           []Log
+	trackingColumn string
+	// The file persisting last across restarts, if any
+	trackingPath string
+	// The greatest tracking column value of the rows emitted by the previous
+	// queries
+	last string
+	// The greatest tracking column value of the rows emitted by the current
+	// query
+	next string
+}
+
+// trackingFileName returns the name of the file persisting the tracking column
+// value of a query of the monitor.
+func trackingFileName(monitorID string, query *Query) string {
+	sum := sha256.Sum256([]byte(monitorID + "\x00" + query.Query + "\x00" + query.TrackingColumn))
+	return hex.EncodeToString(sum[:]) + ".tracking"
+}
+
+// newLogEmitter returns the log emitter of a query, starting from the tracking
+// column value persisted at trackingPath, if any.
+func newLogEmitter(query *Query, trackingPath string) (*logEmitter, error) {
+	last := query.TrackingStartValue
+	if trackingPath != "" {
+		content, err := os.ReadFile(trackingPath)
+		switch {
+		case err == nil:
+			last = string(content)
+		case !errors.Is(err, fs.ErrNotExist):
+			return nil, fmt.Errorf("could not read log tracking file %s: %v", trackingPath, err)
+		}
+	}
+	return &logEmitter{
+		logs:           query.Logs,
+		trackingColumn: query.TrackingColumn,
+		trackingPath:   trackingPath,
+		last:           last,
+		next:           last,
+	}, nil
+}
+
+// convertRow returns the log records of a row, or nil if it was already
+// emitted.
+func (e *logEmitter) convertRow(env ExprEnv, now time.Time) ([]*event.Event, error) {
+	// Column names are matched case insensitively like the metric ones.
+	values := make(map[string]interface{}, len(env))
+	for name, v := range env {
+		values[strings.ToLower(name)] = v
+	}
+	column := func(kind, name string) (interface{}, error) {
+		v, ok := values[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("log %s column '%s' does not exist", kind, name)
+		}
+		return v, nil
+	}
+
+	var tracking string
+	if e.trackingColumn != "" {
+		v, err := column("tracking", e.trackingColumn)
+		if err != nil {
+			return nil, err
+		}
+		if v == nil {
+			return nil, nil
+		}
+		tracking = trackingString(v)
+		if e.last != "" && !trackingGreater(tracking, e.last) {
+			return nil, nil
+		}
+	}
+
+	events := make([]*event.Event, 0, len(e.logs))
+	for i := range e.logs {
+		l := &e.logs[i]
+		body, err := column("body", l.BodyColumn)
+		if err != nil {
+			return nil, err
+		}
+
+		dims := make(map[string]string, len(l.DimensionColumns))
+		for _, name := range l.DimensionColumns {
+			v, err := column("dimension", name)
+			if err != nil {
+				return nil, err
+			}
+			if v != nil {
+				dims[name] = fmt.Sprint(v)
+			}
+		}
+
+		props := map[string]interface{}{bodyProperty: body}
+		for _, name := range l.PropertyColumns {
+			v, err := column("property", name)
+			if err != nil {
+				return nil, err
+			}
+			props[name] = v
+		}
+
+		timestamp := now
+		if l.TimestampColumn != "" {
+			v, err := column("timestamp", l.TimestampColumn)
+			if err != nil {
+				return nil, err
+			}
+			if ts, ok := toTimestamp(v); ok {
+				timestamp = ts
+			}
+		}
+
+		events = append(events, event.NewWithProperties(l.EventType, event.USERDEFINED, dims, props, timestamp))
+	}
+
+	// The row is only tracked once all of its log records were converted.
+	if tracking != "" && (e.next == "" || trackingGreater(tracking, e.next)) {
+		e.next = tracking
+	}
+	return events, nil
+}
+
+// checkColumns returns an error if a column of the logs isn't one of the
+// query result columns.
+func (e *logEmitter) checkColumns(columns []string) error {
+	names := make(map[string]bool, len(columns))
+	for _, name := range columns {
+		names[strings.ToLower(name)] = true
+	}
+	check := func(kind, name string) error {
+		if name != "" && !names[strings.ToLower(name)] {
+			return fmt.Errorf("log %s column '%s' does not exist", kind, name)
+		}
+		return nil
+	}
+
+	if err := check("tracking", e.trackingColumn); err != nil {
+		return err
+	}
+	for i := range e.logs {
+		l := &e.logs[i]
+		if err := check("body", l.BodyColumn); err != nil {
+			return err
+		}
+		for _, name := range l.DimensionColumns {
+			if err := check("dimension", name); err != nil {
+				return err
+			}
+		}
+		for _, name := range l.PropertyColumns {
+			if err := check("property", name); err != nil {
+				return err
+			}
+		}
+		if err := check("timestamp", l.TimestampColumn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// queryDone makes the rows emitted by the current query the ones to skip,
+// persisting them if they changed and there is a tracking file.
+func (e *logEmitter) queryDone() error {
+	if e.next == e.last {
+		return nil
+	}
+	e.last = e.next
+	if e.trackingPath == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(e.trackingPath), 0o700); err != nil {
+		return err
+	}
+	// The value is renamed in place so that a crash doesn't leave a partial one.
+	tmpPath := e.trackingPath + ".tmp"
+	if err := os.WriteFile(tmpPath, []byte(e.last), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, e.trackingPath)
+}
+
+func trackingString(v interface{}) string {
+	switch val := v.(type) {
+	case time.Time:
+		return val.Format(time.RFC3339Nano)
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// trackingGreater compares tracking column values as numbers, timestamps or
+// strings, in that order of preference.
+func trackingGreater(value, than string) bool {
+	if a, err := strconv.ParseFloat(value, 64); err == nil {
+		if b, err := strconv.ParseFloat(than, 64); err == nil {
+			return a > b
+		}
+	}
+	if a, ok := toTimestamp(value); ok {
+		if b, ok := toTimestamp(than); ok {
+			return a.After(b)
+		}
+	}
+	return value > than
+}
+
+func toTimestamp(v interface{}) (time.Time, bool) {
+	switch val := v.(type) {
+	case time.Time:
+		return val, true
+	case string:
+		for _, layout := range timestampLayouts {
+			if ts, err := time.Parse(layout, val); err == nil {
+				return ts, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,176 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/signalfx/signalfx-agent/pkg/neotest"
+)
+
+func TestQueryLogsFromSQLite(t *testing.T) {
+	database, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer database.Close()
+
+	_, err = database.Exec(`CREATE TABLE audit (id INTEGER PRIMARY KEY, message TEXT, username TEXT, created TEXT)`)
+	require.NoError(t, err)
+	_, err = database.Exec(`INSERT INTO audit (message, username, created) VALUES
+		('login', 'bob', '2024-05-01 10:00:00'),
+		('logout', 'alice', '2024-05-01 11:00:00')`)
+	require.NoError(t, err)
+
+	query := &Query{
+		Query: "SELECT id, message, username, created FROM audit ORDER BY id",
+		Logs: []Log{{
+			EventType:        "audit",
+			BodyColumn:       "message",
+			DimensionColumns: []string{"username"},
+			PropertyColumns:  []string{"id"},
+			TimestampColumn:  "created",
+		}},
+		TrackingColumn: "id",
+	}
+	q, err := newQuerier(query, false, "", logrus.New())
+	require.NoError(t, err)
+
+	output := neotest.NewTestOutput()
+	require.NoError(t, q.doQuery(context.Background(), database, output))
+	events := output.FlushEvents()
+	require.Len(t, events, 2)
+	assert.Equal(t, "audit", events[0].EventType)
+	assert.Equal(t, map[string]string{"username": "bob"}, events[0].Dimensions)
+	assert.Equal(t, map[string]interface{}{"body": "login", "id": "1"}, events[0].Properties)
+	assert.Equal(t, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), events[0].Timestamp)
+	assert.Equal(t, map[string]interface{}{"body": "logout", "id": "2"}, events[1].Properties)
+
+	// rows are only emitted once
+	require.NoError(t, q.doQuery(context.Background(), database, output))
+	require.Empty(t, output.FlushEvents())
+
+	_, err = database.Exec(`INSERT INTO audit (message, username, created) VALUES ('reboot', 'root', '2024-05-01 12:00:00')`)
+	require.NoError(t, err)
+	require.NoError(t, q.doQuery(context.Background(), database, output))
+	events = output.FlushEvents()
+	require.Len(t, events, 1)
+	assert.Equal(t, map[string]interface{}{"body": "reboot", "id": "3"}, events[0].Properties)
+}
+
+func TestQueryLogsTrackingStartValue(t *testing.T) {
+	emitter, err := newLogEmitter(&Query{
+		Logs:               []Log{{EventType: "audit", BodyColumn: "message"}},
+		TrackingColumn:     "created",
+		TrackingStartValue: "2024-05-01T10:30:00Z",
+	}, "")
+	require.NoError(t, err)
+	now := time.Now()
+
+	events, err := emitter.convertRow(ExprEnv{"message": "login", "created": time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)}, now)
+	require.NoError(t, err)
+	require.Empty(t, events)
+
+	events, err = emitter.convertRow(ExprEnv{"message": "logout", "created": "2024-05-01 11:00:00"}, now)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, now, events[0].Timestamp)
+	require.NoError(t, emitter.queryDone())
+	assert.Equal(t, "2024-05-01 11:00:00", emitter.last)
+
+	_, err = emitter.convertRow(ExprEnv{"message": "logout"}, now)
+	require.EqualError(t, err, "log tracking column 'created' does not exist")
+}
+
+func TestQueryLogsMissingColumns(t *testing.T) {
+	emitter, err := newLogEmitter(&Query{
+		Logs:           []Log{{EventType: "audit", BodyColumn: "message", PropertyColumns: []string{"user"}}},
+		TrackingColumn: "id",
+	}, "")
+	require.NoError(t, err)
+
+	// a row whose log records can't be converted isn't tracked as emitted
+	_, err = emitter.convertRow(ExprEnv{"id": int64(1), "message": "login"}, time.Now())
+	require.EqualError(t, err, "log property column 'user' does not exist")
+	require.NoError(t, emitter.queryDone())
+	assert.Empty(t, emitter.last)
+
+	require.NoError(t, emitter.checkColumns([]string{"ID", "Message", "User"}))
+	require.EqualError(t, emitter.checkColumns([]string{"id", "message"}), "log property column 'user' does not exist")
+	require.EqualError(t, emitter.checkColumns([]string{"message", "user"}), "log tracking column 'id' does not exist")
+
+	database, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer database.Close()
+	_, err = database.Exec(`CREATE TABLE audit (id INTEGER PRIMARY KEY, message TEXT)`)
+	require.NoError(t, err)
+	_, err = database.Exec(`INSERT INTO audit (message) VALUES ('login')`)
+	require.NoError(t, err)
+
+	q, err := newQuerier(&Query{
+		Query:          "SELECT id, message FROM audit",
+		Logs:           []Log{{EventType: "audit", BodyColumn: "message", PropertyColumns: []string{"user"}}},
+		TrackingColumn: "id",
+	}, false, "", logrus.New())
+	require.NoError(t, err)
+	output := neotest.NewTestOutput()
+	require.EqualError(t, q.doQuery(context.Background(), database, output), "log property column 'user' does not exist")
+	assert.Empty(t, output.FlushEvents())
+	assert.Empty(t, q.logs.last)
+}
+
+func TestQueryLogsTrackingPersistence(t *testing.T) {
+	query := &Query{
+		Query:              "SELECT id, message FROM audit ORDER BY id",
+		Logs:               []Log{{EventType: "audit", BodyColumn: "message"}},
+		TrackingColumn:     "id",
+		TrackingStartValue: "1",
+	}
+	trackingPath := filepath.Join(t.TempDir(), "sql", trackingFileName("sql", query))
+	now := time.Now()
+
+	emitter, err := newLogEmitter(query, trackingPath)
+	require.NoError(t, err)
+	events, err := emitter.convertRow(ExprEnv{"id": int64(2), "message": "login"}, now)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.NoError(t, emitter.queryDone())
+	content, err := os.ReadFile(trackingPath)
+	require.NoError(t, err)
+	assert.Equal(t, "2", string(content))
+
+	// A restarted emitter skips the rows emitted before the restart.
+	emitter, err = newLogEmitter(query, trackingPath)
+	require.NoError(t, err)
+	events, err = emitter.convertRow(ExprEnv{"id": int64(2), "message": "login"}, now)
+	require.NoError(t, err)
+	require.Empty(t, events)
+	events, err = emitter.convertRow(ExprEnv{"id": int64(3), "message": "logout"}, now)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+
+	assert.NotEqual(t, trackingFileName("sql", query), trackingFileName("sql/2", query))
+}
+
+func TestValidateLogs(t *testing.T) {
+	conf := &Config{
+		DBDriver: "sqlite",
+		Queries: []Query{{
+			Query: "SELECT message FROM audit",
+			Logs:  []Log{{EventType: "audit", BodyColumn: "message"}},
+		}},
+	}
+	require.NoError(t, conf.Validate())
+
+	conf.Queries[0].Logs = nil
+	require.EqualError(t, conf.Validate(), "each SQL query must have at least one metric, expression or log defined on it")
+
+	conf.Queries[0].DatapointExpressions = []string{`GAUGE("audit", {}, count)`}
+	conf.Queries[0].TrackingColumn = "id"
+	require.EqualError(t, conf.Validate(), "sql query tracking column is only supported with logs")
+}
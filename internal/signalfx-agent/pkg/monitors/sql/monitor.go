@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -16,6 +17,7 @@ import (
 	_ "github.com/microsoft/go-mssqldb"
 	_ "github.com/microsoft/go-mssqldb/integratedauth/krb5"
 	_ "github.com/snowflakedb/gosnowflake/v2"
+	_ "modernc.org/sqlite"
 
 	"github.com/signalfx/golib/v3/datapoint" //nolint:staticcheck // SA1019: deprecated package still in use
 	"github.com/sirupsen/logrus"
@@ -43,6 +45,39 @@ type Query struct {
 	// result set, allowing you to generate multiple datapoints per row.  Each
 	// expression should evaluate to a single datapoint or nil.
 	DatapointExpressions []string `yaml:"datapointExpressions"`
+	// Log records (events) that should be generated from the query.
+	Logs []Log `yaml:"logs"`
+	// The column whose value identifies the rows already emitted as log
+	// records, e.g. an auto-incremented ID or a timestamp.  If set, only the
+	// rows with a value greater than the greatest one emitted so far are
+	// emitted as log records, so that each row is only emitted once.  The
+	// values are compared as numbers or RFC 3339 timestamps when possible and
+	// as strings otherwise.  This doesn't apply to the generated metrics.  The
+	// greatest value is only kept in memory unless `trackingDirectory` is set,
+	// so the rows are emitted again after a restart otherwise.
+	TrackingColumn string `yaml:"trackingColumn"`
+	// The tracking column value that rows must exceed to be emitted as log
+	// records until a row is emitted.  If not set, all rows returned by the
+	// first query are emitted.
+	TrackingStartValue string `yaml:"trackingStartValue"`
+}
+
+// Log describes how to derive a log record, sent as an event, from the
+// individual rows of a query result.
+type Log struct {
+	// The event type of the log records.
+	EventType string `yaml:"eventType" validate:"required"`
+	// The column that holds the log record body, set as the `body` property.
+	BodyColumn string `yaml:"bodyColumn" validate:"required"`
+	// The names of the columns that should make up the dimensions of the log
+	// record.
+	DimensionColumns []string `yaml:"dimensionColumns"`
+	// The names of the columns that should be set as properties of the log
+	// record.
+	PropertyColumns []string `yaml:"propertyColumns"`
+	// The column that holds the log record timestamp.  If not set, or if its
+	// value isn't a timestamp, the time of the query is used.
+	TimestampColumn string `yaml:"timestampColumn"`
 }
 
 // Metric describes how to derive a metric from the individual rows of a query
@@ -85,7 +120,8 @@ type Config struct {
 	Params map[string]string `yaml:"params"`
 
 	// The database driver to use, valid values are `postgres`, `mysql`, `sqlserver`,
-	// and `snowflake`.
+	// `snowflake`, and `sqlite`.  The `sqlite` driver is meant to test queries
+	// locally against a database file (e.g. `connectionString: ./test.db`).
 	DBDriver string `yaml:"dbDriver"`
 	// A URL or simple option string used to connect to the database.
 	// For example, if using PostgreSQL, [see the list of connection string
@@ -93,15 +129,21 @@ type Config struct {
 	ConnectionString string `yaml:"connectionString"`
 
 	// A list of queries to make against the database that are used to generate
-	// datapoints.
+	// datapoints and log records.
 	Queries []Query `yaml:"queries" validate:"required"`
 	// If true, query results will be logged at the info level.
 	LogQueries bool `yaml:"logQueries"`
+	// The directory where the greatest tracking column value emitted by each
+	// query is persisted, so that its rows aren't emitted again as log records
+	// after a restart.  The values are stored in a file per query, named after
+	// the monitor ID, the query text and its tracking column.  Changing any of
+	// them starts from `trackingStartValue` again.
+	TrackingDirectory string `yaml:"trackingDirectory"`
 }
 
 // Validate that the config is right
 func (c *Config) Validate() error {
-	if c.DBDriver != "postgres" && c.DBDriver != "mysql" && c.DBDriver != "sqlserver" && c.DBDriver != "snowflake" {
+	if c.DBDriver != "postgres" && c.DBDriver != "mysql" && c.DBDriver != "sqlserver" && c.DBDriver != "snowflake" && c.DBDriver != "sqlite" {
 		return fmt.Errorf("database driver %s is not supported", c.DBDriver)
 	}
 
@@ -110,8 +152,11 @@ func (c *Config) Validate() error {
 	}
 
 	for i := range c.Queries {
-		if len(c.Queries[i].Metrics) == 0 && len(c.Queries[i].DatapointExpressions) == 0 {
-			return errors.New("each SQL query must have at least one metric or expression defined on it")
+		if len(c.Queries[i].Metrics) == 0 && len(c.Queries[i].DatapointExpressions) == 0 && len(c.Queries[i].Logs) == 0 {
+			return errors.New("each SQL query must have at least one metric, expression or log defined on it")
+		}
+		if c.Queries[i].TrackingColumn != "" && len(c.Queries[i].Logs) == 0 {
+			return errors.New("sql query tracking column is only supported with logs")
 		}
 		valueCols := map[string]bool{}
 		for _, met := range c.Queries[i].Metrics {
@@ -140,7 +185,7 @@ func (c *Config) renderedDataSource() (string, error) {
 	return strings.TrimSpace(rendered), nil
 }
 
-// Monitor for generic SQL queries -> metrics
+// Monitor for generic SQL queries -> metrics and logs
 type Monitor struct {
 	Output   types.Output
 	database *sql.DB
@@ -172,7 +217,11 @@ func (m *Monitor) Configure(conf *Config, deprecationWarning ...string) error {
 	}
 
 	for i := range conf.Queries {
-		querier, err := newQuerier(&conf.Queries[i], conf.LogQueries, m.logger)
+		var trackingPath string
+		if conf.TrackingDirectory != "" && conf.Queries[i].TrackingColumn != "" {
+			trackingPath = filepath.Join(conf.TrackingDirectory, trackingFileName(string(conf.MonitorID), &conf.Queries[i]))
+		}
+		querier, err := newQuerier(&conf.Queries[i], conf.LogQueries, trackingPath, m.logger)
 		if err != nil {
 			return err
 		}
@@ -12,6 +12,7 @@ import (
 	"github.com/antonmedv/expr/vm"
 	"github.com/davecgh/go-spew/spew"
 	"github.com/signalfx/golib/v3/datapoint" //nolint:staticcheck // SA1019: deprecated package still in use
+	"github.com/signalfx/golib/v3/event"     //nolint:staticcheck // SA1019: deprecated package still in use
 	"github.com/sirupsen/logrus"
 
 	"github.com/signalfx/signalfx-agent/pkg/monitors/types"
@@ -27,10 +28,11 @@ type querier struct {
 	dimensions                [][]*types.Dimension
 	compiledExprs             []*vm.Program
 	rowSliceCached            []interface{}
+	logs                      *logEmitter
 	logQueries                bool
 }
 
-func newQuerier(query *Query, logQueries bool, logger logrus.FieldLogger) (*querier, error) {
+func newQuerier(query *Query, logQueries bool, trackingPath string, logger logrus.FieldLogger) (*querier, error) {
 	valueColumnNamesToMetrics := map[string]*Metric{}
 	metricToIndex := map[*Metric]int{}
 
@@ -77,6 +79,14 @@ func newQuerier(query *Query, logQueries bool, logger logrus.FieldLogger) (*quer
 		compiledExprs[i] = ruleProg
 	}
 
+	var logs *logEmitter
+	if len(query.Logs) > 0 {
+		var err error
+		if logs, err = newLogEmitter(query, trackingPath); err != nil {
+			return nil, err
+		}
+	}
+
 	return &querier{
 		query:                     query,
 		valueColumnNamesToMetrics: valueColumnNamesToMetrics,
@@ -84,6 +94,7 @@ func newQuerier(query *Query, logQueries bool, logger logrus.FieldLogger) (*quer
 		dimensionColumnSets:       dimensionColumnSets,
 		dimensions:                dimensions,
 		compiledExprs:             compiledExprs,
+		logs:                      logs,
 		logger:                    logger.WithField("statement", query.Query),
 		logQueries:                logQueries,
 	}, nil
@@ -94,8 +105,25 @@ func (q *querier) doQuery(ctx context.Context, database *sql.DB, output types.Ou
 	if err != nil {
 		return fmt.Errorf("error executing statement %s: %v", q.query.Query, err)
 	}
+	if q.logs != nil {
+		columnNames, err := rows.Columns()
+		if err == nil {
+			err = q.logs.checkColumns(columnNames)
+		}
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		// rows already emitted as log records aren't emitted again if the query fails midway
+		defer func() {
+			if err := q.logs.queryDone(); err != nil {
+				q.logger.WithError(err).Error("Could not persist the log tracking column value")
+			}
+		}()
+	}
+	now := time.Now()
 	for rows.Next() {
-		dps, dims, err := q.convertCurrentRowToDatapointAndDimensions(rows)
+		dps, dims, events, err := q.convertCurrentRow(rows, now)
 		if err != nil {
 			rows.Close()
 			return err
@@ -103,6 +131,10 @@ func (q *querier) doQuery(ctx context.Context, database *sql.DB, output types.Ou
 
 		output.SendDatapoints(dps...)
 
+		for _, ev := range events {
+			output.SendEvent(ev)
+		}
+
 		for i := range dims {
 			for _, dim := range dims[i] {
 				output.SendDimensionUpdate(dim)
@@ -112,18 +144,18 @@ func (q *querier) doQuery(ctx context.Context, database *sql.DB, output types.Ou
 	return rows.Close()
 }
 
-func (q *querier) convertCurrentRowToDatapointAndDimensions(rows *sql.Rows) ([]*datapoint.Datapoint, [][]*types.Dimension, error) {
+func (q *querier) convertCurrentRow(rows *sql.Rows, now time.Time) ([]*datapoint.Datapoint, [][]*types.Dimension, []*event.Event, error) {
 	rowSlice, err := q.getRowSlice(rows)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 	columnNames, err := rows.Columns()
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	if err := rows.Scan(rowSlice...); err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 	if q.logQueries {
 		q.logger.Info("Got results %s", spew.Sdump(rowSlice))
@@ -148,7 +180,15 @@ func (q *querier) convertCurrentRowToDatapointAndDimensions(rows *sql.Rows) ([]*
 		dps = append(dps, exprDPs...)
 	}
 
-	return dps, dims, nil
+	var events []*event.Event
+	if q.logs != nil {
+		events, err = q.logs.convertRow(newExprEnv(rowSlice, columnNames), now)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	return dps, dims, events, nil
 }
 
 func (q *querier) convertCurrentRowExpressions(rowSlice []interface{}, columnNames []string) []*datapoint.Datapoint {
@@ -30,10 +30,11 @@ var _ types.FilteringOutput = (*TestOutput)(nil)
 // NewTestOutput creates a new initialized TestOutput instance
 func NewTestOutput() *TestOutput {
 	return &TestOutput{
-		dpChan:    make(chan []*datapoint.Datapoint, 1000),
-		eventChan: make(chan *event.Event, 1000),
-		spanChan:  make(chan []*trace.Span, 1000),
-		dimChan:   make(chan *types.Dimension, 1000),
+		dpChan:      make(chan []*datapoint.Datapoint, 1000),
+		metricsChan: make(chan []pmetric.Metric, 1000),
+		eventChan:   make(chan *event.Event, 1000),
+		spanChan:    make(chan []*trace.Span, 1000),
+		dimChan:     make(chan *types.Dimension, 1000),
 	}
 }
 
@@ -87,6 +88,19 @@ func (to *TestOutput) FlushDatapoints() []*datapoint.Datapoint {
 	}
 }
 
+// FlushMetrics returns all of the metrics injected into the channel so far.
+func (to *TestOutput) FlushMetrics() []pmetric.Metric {
+	var out []pmetric.Metric
+	for {
+		select {
+		case metrics := <-to.metricsChan:
+			out = append(out, metrics...)
+		default:
+			return out
+		}
+	}
+}
+
 // FlushEvents returns all of the datapoints injected into the channel so
 // far.
 func (to *TestOutput) FlushEvents() []*event.Event {
@@ -0,0 +1,118 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modularinput
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/shlex"
+)
+
+// ParamType is the data type of a modular input parameter, as declared in the scheme XML.
+type ParamType string
+
+const (
+	ParamTypeString  ParamType = "string"
+	ParamTypeNumber  ParamType = "number"
+	ParamTypeBoolean ParamType = "boolean"
+)
+
+// ParamSpec describes a modular input stanza parameter to validate.
+type ParamSpec struct {
+	Name    string
+	Type    ParamType
+	Default string
+	// Pattern is a regular expression the non-empty values must match.
+	Pattern string
+	// AllowedValues are the only non-empty values accepted, if any.
+	AllowedValues []string
+	// Required parameters can't be empty, nor missing if they have no default.
+	Required bool
+}
+
+// NewParamsValidator returns a ValidatorFunc validating the parameters of the validation
+// items against the specs. Parameters without a spec aren't validated and the args are
+// returned unchanged. It panics if the pattern of a spec isn't a valid regular expression.
+func NewParamsValidator(specs []ParamSpec) ValidatorFunc {
+	patterns := make(map[string]*regexp.Regexp)
+	for _, spec := range specs {
+		if spec.Pattern != "" {
+			patterns[spec.Name] = regexp.MustCompile(spec.Pattern)
+		}
+	}
+
+	return func(items *ValidationItems, args []string) ([]string, error) {
+		var errs []error
+		for _, item := range items.Item {
+			params := make(map[string]string, len(item.Param))
+			for _, param := range item.Param {
+				params[strings.ToLower(param.Name)] = param.Value
+			}
+			for _, spec := range specs {
+				value, ok := params[strings.ToLower(spec.Name)]
+				if err := validateParam(spec, patterns[spec.Name], value, ok); err != nil {
+					errs = append(errs, fmt.Errorf("stanza '%s': parameter '%s' %w", item.Name, spec.Name, err))
+				}
+			}
+		}
+		return args, errors.Join(errs...)
+	}
+}
+
+func validateParam(spec ParamSpec, pattern *regexp.Regexp, value string, isSet bool) error {
+	if value == "" {
+		if spec.Required && (isSet || spec.Default == "") {
+			return errors.New("is required")
+		}
+		return nil
+	}
+
+	switch spec.Type {
+	case ParamTypeNumber:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("must be a number, got %q", value)
+		}
+	case ParamTypeBoolean:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("must be a boolean, got %q", value)
+		}
+	}
+
+	if len(spec.AllowedValues) > 0 && !slices.Contains(spec.AllowedValues, value) {
+		return fmt.Errorf("must be one of %s, got %q", strings.Join(spec.AllowedValues, ", "), value)
+	}
+	if pattern != nil && !pattern.MatchString(value) {
+		return fmt.Errorf("must match %q, got %q", pattern.String(), value)
+	}
+
+	// The parameters with a special handling must be parsable by HandleLaunchAsTA.
+	paramName := strings.ToLower(spec.Name)
+	switch {
+	case strings.HasSuffix(paramName, "_env_vars"):
+		if _, err := parseEnvVarPairs(value); err != nil {
+			return fmt.Errorf("has invalid env vars: %w", err)
+		}
+	case strings.HasSuffix(paramName, "_cmd_args"):
+		if _, err := shlex.Split(value); err != nil {
+			return fmt.Errorf("has invalid cmd args: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modularinput
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testParamSpecs = []ParamSpec{
+	{Name: "splunk_realm", Type: ParamTypeString, Required: true, Pattern: `^[a-z0-9]+$`},
+	{Name: "splunk_config", Type: ParamTypeString, Required: true, Default: "/etc/otel/agent.yaml"},
+	{Name: "splunk_collector_log_level", Type: ParamTypeString, Default: "error", AllowedValues: []string{"debug", "info", "warn", "error"}},
+	{Name: "splunk_memory_limit_percent", Type: ParamTypeNumber},
+	{Name: "splunk_enabled", Type: ParamTypeBoolean},
+	{Name: "splunk_collector_env_vars", Type: ParamTypeString},
+	{Name: "splunk_collector_cmd_args", Type: ParamTypeString},
+}
+
+func validationItems(params ...Param) *ValidationItems {
+	return &ValidationItems{Item: []ValidationItem{{Name: "otel://default", Param: params}}}
+}
+
+func TestNewParamsValidator(t *testing.T) {
+	validator := NewParamsValidator(testParamSpecs)
+
+	args := []string{"program", "--validate-arguments"}
+	resultArgs, err := validator(validationItems(
+		Param{Name: "SPLUNK_REALM", Value: "us0"},
+		Param{Name: "splunk_collector_log_level", Value: "info"},
+		Param{Name: "splunk_memory_limit_percent", Value: "80.5"},
+		Param{Name: "splunk_enabled", Value: "true"},
+		Param{Name: "splunk_collector_env_vars", Value: "KEY1=VALUE1,KEY2=a%2Cb"},
+		Param{Name: "splunk_collector_cmd_args", Value: "--feature-gates=foo --set 'a=b c'"},
+		Param{Name: "unknown_param", Value: "anything"},
+	), args)
+	require.NoError(t, err)
+	assert.Equal(t, args, resultArgs)
+}
+
+func TestNewParamsValidator_Errors(t *testing.T) {
+	validator := NewParamsValidator(testParamSpecs)
+
+	tests := []struct {
+		name     string
+		params   []Param
+		expected string
+	}{
+		{
+			name:     "missing required without default",
+			params:   nil,
+			expected: "stanza 'otel://default': parameter 'splunk_realm' is required",
+		},
+		{
+			name:     "empty required with default",
+			params:   []Param{{Name: "splunk_realm", Value: "us0"}, {Name: "splunk_config", Value: ""}},
+			expected: "stanza 'otel://default': parameter 'splunk_config' is required",
+		},
+		{
+			name:     "pattern",
+			params:   []Param{{Name: "splunk_realm", Value: "US0"}},
+			expected: "stanza 'otel://default': parameter 'splunk_realm' must match \"^[a-z0-9]+$\", got \"US0\"",
+		},
+		{
+			name:     "allowed values",
+			params:   []Param{{Name: "splunk_realm", Value: "us0"}, {Name: "splunk_collector_log_level", Value: "verbose"}},
+			expected: "stanza 'otel://default': parameter 'splunk_collector_log_level' must be one of debug, info, warn, error, got \"verbose\"",
+		},
+		{
+			name:     "number",
+			params:   []Param{{Name: "splunk_realm", Value: "us0"}, {Name: "splunk_memory_limit_percent", Value: "eighty"}},
+			expected: "stanza 'otel://default': parameter 'splunk_memory_limit_percent' must be a number, got \"eighty\"",
+		},
+		{
+			name:     "boolean",
+			params:   []Param{{Name: "splunk_realm", Value: "us0"}, {Name: "splunk_enabled", Value: "maybe"}},
+			expected: "stanza 'otel://default': parameter 'splunk_enabled' must be a boolean, got \"maybe\"",
+		},
+		{
+			name:     "env vars",
+			params:   []Param{{Name: "splunk_realm", Value: "us0"}, {Name: "splunk_collector_env_vars", Value: "KEY1"}},
+			expected: "stanza 'otel://default': parameter 'splunk_collector_env_vars' has invalid env vars: invalid key=value pair \"KEY1\": missing '='",
+		},
+		{
+			name:     "cmd args",
+			params:   []Param{{Name: "splunk_realm", Value: "us0"}, {Name: "splunk_collector_cmd_args", Value: "--set 'a=b"}},
+			expected: "stanza 'otel://default': parameter 'splunk_collector_cmd_args' has invalid cmd args: EOF found when expecting closing quote",
+		},
+		{
+			name:   "multiple errors",
+			params: []Param{{Name: "splunk_collector_log_level", Value: "verbose"}},
+			expected: "stanza 'otel://default': parameter 'splunk_realm' is required\n" +
+				"stanza 'otel://default': parameter 'splunk_collector_log_level' must be one of debug, info, warn, error, got \"verbose\"",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := validator(validationItems(tt.params...), nil)
+			require.EqualError(t, err, tt.expected)
+		})
+	}
+}
+
+func TestNewParamsValidator_InvalidPattern(t *testing.T) {
+	assert.Panics(t, func() {
+		NewParamsValidator([]ParamSpec{{Name: "splunk_realm", Pattern: "["}})
+	})
+}
@@ -0,0 +1,110 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modularinput
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+const (
+	streamStartTag = "<stream>"
+	streamEndTag   = "</stream>"
+)
+
+// StreamEvent is a single <event> of the streaming XML written by a modular input on stdout.
+// Empty optional fields are omitted so that Splunk applies the defaults of the input stanza.
+type StreamEvent struct {
+	XMLName    xml.Name `xml:"event"`
+	Stanza     string   `xml:"stanza,attr,omitempty"`
+	Time       string   `xml:"time,omitempty"`
+	Data       string   `xml:"data"`
+	Source     string   `xml:"source,omitempty"`
+	SourceType string   `xml:"sourcetype,omitempty"`
+	Index      string   `xml:"index,omitempty"`
+	Host       string   `xml:"host,omitempty"`
+}
+
+// FormatStreamEventTime formats a timestamp as the epoch seconds, with millisecond
+// precision, expected in the <time> element of a StreamEvent.
+func FormatStreamEventTime(t time.Time) string {
+	ms := t.UnixMilli()
+	return fmt.Sprintf("%d.%03d", ms/1000, ms%1000)
+}
+
+// StreamWriter writes StreamEvents to the provided writer (stdout) in the modular input
+// streaming XML format. The <stream> element is opened by the first write and closed by
+// Close. It is safe for concurrent use.
+type StreamWriter struct {
+	w       io.Writer
+	lock    sync.Mutex
+	started bool
+	closed  bool
+}
+
+func NewStreamWriter(w io.Writer) *StreamWriter {
+	return &StreamWriter{w: w}
+}
+
+// WriteEvents writes the events in a single write to the underlying writer.
+func (sw *StreamWriter) WriteEvents(events ...StreamEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	var out []byte
+	for _, event := range events {
+		marshaled, err := xml.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to marshal stream event XML: %w", err)
+		}
+		out = append(out, marshaled...)
+	}
+
+	sw.lock.Lock()
+	defer sw.lock.Unlock()
+	if sw.closed {
+		return errors.New("failed to write stream events: stream is closed")
+	}
+	if !sw.started {
+		out = append([]byte(streamStartTag), out...)
+	}
+	if _, err := sw.w.Write(out); err != nil {
+		return fmt.Errorf("failed to write stream events: %w", err)
+	}
+	sw.started = true
+	return nil
+}
+
+// Close closes the <stream> element if it was opened. Events can't be written afterward.
+func (sw *StreamWriter) Close() error {
+	sw.lock.Lock()
+	defer sw.lock.Unlock()
+	if sw.closed {
+		return nil
+	}
+	sw.closed = true
+	if !sw.started {
+		return nil
+	}
+	if _, err := io.WriteString(sw.w, streamEndTag); err != nil {
+		return fmt.Errorf("failed to close stream: %w", err)
+	}
+	return nil
+}
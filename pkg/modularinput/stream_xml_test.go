@@ -0,0 +1,79 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modularinput
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamWriter(t *testing.T) {
+	var buf bytes.Buffer
+	sw := NewStreamWriter(&buf)
+
+	require.NoError(t, sw.WriteEvents())
+	assert.Empty(t, buf.String(), "the stream should only be opened by the first event")
+
+	require.NoError(t, sw.WriteEvents(
+		StreamEvent{
+			Stanza:     "Splunk_TA_otel://Splunk_TA_otel",
+			Time:       "1372274622.493",
+			Data:       "first <event> & more",
+			Source:     "otel",
+			SourceType: "otel:log",
+			Index:      "main",
+			Host:       "R91395DV",
+		},
+		StreamEvent{Data: "second event"},
+	))
+	require.NoError(t, sw.WriteEvents(StreamEvent{Data: "third event"}))
+	require.NoError(t, sw.Close())
+	require.NoError(t, sw.Close())
+
+	expected := `<stream>` +
+		`<event stanza="Splunk_TA_otel://Splunk_TA_otel"><time>1372274622.493</time><data>first &lt;event&gt; &amp; more</data>` +
+		`<source>otel</source><sourcetype>otel:log</sourcetype><index>main</index><host>R91395DV</host></event>` +
+		`<event><data>second event</data></event>` +
+		`<event><data>third event</data></event>` +
+		`</stream>`
+	assert.Equal(t, expected, buf.String())
+
+	var stream struct {
+		XMLName xml.Name      `xml:"stream"`
+		Events  []StreamEvent `xml:"event"`
+	}
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &stream))
+	require.Len(t, stream.Events, 3)
+	assert.Equal(t, "first <event> & more", stream.Events[0].Data)
+
+	require.EqualError(t, sw.WriteEvents(StreamEvent{Data: "late"}), "failed to write stream events: stream is closed")
+}
+
+func TestStreamWriter_CloseWithoutEvents(t *testing.T) {
+	var buf bytes.Buffer
+	sw := NewStreamWriter(&buf)
+	require.NoError(t, sw.Close())
+	assert.Empty(t, buf.String())
+}
+
+func TestFormatStreamEventTime(t *testing.T) {
+	assert.Equal(t, "1372274622.493", FormatStreamEventTime(time.UnixMilli(1372274622493)))
+	assert.Equal(t, "1372274622.005", FormatStreamEventTime(time.Unix(1372274622, 5_900_000)))
+}
@@ -23,6 +23,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
 
@@ -61,6 +62,9 @@ const (
 	// EnvSessionKey is the environment variable set to the splunkd session key
 	// received from Splunk on stdin.
 	EnvSessionKey = "SPLUNK_SESSION_KEY"
+	// EnvCheckpointDir is the environment variable set to the Splunk managed checkpoint
+	// directory of the modular input received from Splunk on stdin.
+	EnvCheckpointDir = "SPLUNK_MODINPUT_CHECKPOINT_DIR"
 )
 
 var (
@@ -78,14 +82,45 @@ var (
 // On failure, the error message is written as XML to stdout before exiting.
 type ValidatorFunc func(items *ValidationItems, args []string) ([]string, error)
 
+// LaunchOption customizes how HandleLaunchAsTA handles the stanza parameters.
+type LaunchOption func(*launchOptions)
+
+type launchOptions struct {
+	// lowercase parameter name to command line flag
+	stanzaArgs map[string]string
+	// lowercase parameter name to environment variable
+	stanzaEnvVars map[string]string
+}
+
+// WithStanzaArg makes the values of the given parameter be passed as the given command line flag,
+// once per distinct value in stanza order, when the matching stanzas set it to different values.
+// When a single value is set, it's set as an environment variable like the other parameters.
+// For example WithStanzaArg("splunk_config", "--config") passes one collector config per stanza.
+func WithStanzaArg(paramName, flag string) LaunchOption {
+	return func(o *launchOptions) {
+		o.stanzaArgs[strings.ToLower(paramName)] = flag
+	}
+}
+
+// WithStanzaEnvVar makes the value of the given parameter be set as the given environment variable
+// instead of the default one, which is the parameter name in uppercase.
+func WithStanzaEnvVar(paramName, envVar string) LaunchOption {
+	return func(o *launchOptions) {
+		o.stanzaEnvVars[strings.ToLower(paramName)] = envVar
+	}
+}
+
 // HandleLaunchAsTA handles the launch of the collector as a Splunk TA modular input.
 // It checks if the collector is running in modular input mode and processes the input XML
-// to set environment variables from the configuration stanza.
+// to set environment variables from the configuration stanzas whose name starts with configStanzaPrefix.
+// All the matching stanzas are honored: their "_cmd_args" parameters are appended in stanza order and
+// setting different values for any other parameter is an error, unless the parameter is passed as
+// command line arguments per WithStanzaArg.
 // The optional validator is called in --validate-arguments mode; pass nil to skip validation.
 // Returns the updated args, the TARunMode indicating how the process was invoked, and any error.
 // When not in modular input mode or when there are no "_cmd_args" parameters, the original
 // args are returned unchanged, so the caller can always use the returned args.
-func HandleLaunchAsTA(args []string, stdin io.Reader, configStanzaPrefix, scheme string, validator ValidatorFunc) ([]string, TARunMode, error) {
+func HandleLaunchAsTA(args []string, stdin io.Reader, configStanzaPrefix, scheme string, validator ValidatorFunc, opts ...LaunchOption) ([]string, TARunMode, error) {
 	mode := detectTARunMode(args)
 	if mode == NotTARunMode {
 		return args, NotTARunMode, nil
@@ -98,13 +133,18 @@ func HandleLaunchAsTA(args []string, stdin io.Reader, configStanzaPrefix, scheme
 		return nil, IntrospectionTARunMode, nil
 	}
 
+	options := launchOptions{stanzaArgs: map[string]string{}, stanzaEnvVars: map[string]string{}}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	modularInputEnvVars := make(map[string]string)
 	if baseDirName := baseDirNameFromExecutable(); baseDirName != "" {
 		modularInputEnvVars[EnvBaseDirName] = baseDirName
 	}
 
-	var configStanza Stanza
-	var params []Param
+	var stanzas []Stanza
+	var checkpointDir string
 	if mode == ValidationTARunMode {
 		if validator == nil {
 			// Caller didn't provided a validator, just return indicating that this is validation mode.
@@ -117,6 +157,7 @@ func HandleLaunchAsTA(args []string, stdin io.Reader, configStanzaPrefix, scheme
 		}
 		modularInputEnvVars[EnvManagementURI] = items.ServerURI
 		modularInputEnvVars[EnvSessionKey] = items.SessionKey
+		checkpointDir = items.CheckpointDir
 
 		args, err = validator(items, args)
 		if err != nil {
@@ -126,11 +167,9 @@ func HandleLaunchAsTA(args []string, stdin io.Reader, configStanzaPrefix, scheme
 			return nil, ValidationTARunMode, fmt.Errorf("validation mode failed: %w", err)
 		}
 
-		params = make([]Param, 0, len(items.Item))
+		stanzas = make([]Stanza, 0, len(items.Item))
 		for _, item := range items.Item {
-			for _, param := range item.Param {
-				params = append(params, Param{Name: param.Name, Value: param.Value})
-			}
+			stanzas = append(stanzas, Stanza{Name: item.Name, Param: item.Param})
 		}
 	} else {
 		input, err := ReadXML(stdin)
@@ -139,51 +178,29 @@ func HandleLaunchAsTA(args []string, stdin io.Reader, configStanzaPrefix, scheme
 		}
 		modularInputEnvVars[EnvManagementURI] = input.ServerURI
 		modularInputEnvVars[EnvSessionKey] = input.SessionKey
+		checkpointDir = input.CheckpointDir
 
 		for _, stanza := range input.Configuration.Stanza {
-			if strings.HasPrefix(stanza.Name, configStanzaPrefix) {
+			if !strings.HasPrefix(stanza.Name, configStanzaPrefix) {
+				continue
+			}
+			if len(stanzas) == 0 {
+				// The app and stanza name env vars refer to the first matching stanza.
 				modularInputEnvVars[EnvAppName] = stanza.App
 				modularInputEnvVars[EnvStanzaName] = stanza.Name
-				configStanza = stanza
-				break
 			}
+			stanzas = append(stanzas, stanza)
 		}
+	}
 
-		params = make([]Param, 0, len(configStanza.Param))
-		for _, param := range configStanza.Param {
-			params = append(params, Param{Name: param.Name, Value: param.Value})
-		}
+	if checkpointDir != "" {
+		modularInputEnvVars[EnvCheckpointDir] = checkpointDir
 	}
 
 	// First pass: build a map of parameters starting with "splunk_" and collect cmd args
-	var cmdArgs []string
-	var err error
-	for _, param := range params {
-		paramName := strings.ToLower(param.Name)
-		if !strings.HasPrefix(paramName, "splunk_") {
-			continue
-		}
-
-		// Process special parameters
-		switch {
-		// TODO: to be refactored: the caller will specify which parameters should be parsed as env var pairs instead of using a naming convention
-		case strings.HasSuffix(paramName, "_env_vars"):
-			var pairs map[string]string
-			pairs, err = parseEnvVarPairs(param.Value)
-			if err != nil {
-				return nil, mode, fmt.Errorf("launch as TA failed to parse env vars from parameter '%s': %w", param.Name, err)
-			}
-			maps.Copy(modularInputEnvVars, pairs)
-		case strings.HasSuffix(paramName, "_cmd_args"):
-			var parsed []string
-			parsed, err = shlex.Split(param.Value)
-			if err != nil {
-				return nil, mode, fmt.Errorf("launch as TA failed to parse cmd args from parameter '%s': %w", param.Name, err)
-			}
-			cmdArgs = append(cmdArgs, parsed...)
-		default:
-			modularInputEnvVars[strings.ToUpper(param.Name)] = param.Value
-		}
+	stanzaArgs, cmdArgs, err := processStanzaParams(stanzas, modularInputEnvVars, options)
+	if err != nil {
+		return nil, mode, err
 	}
 
 	// Second pass: set environment variables in dependency order
@@ -191,9 +208,96 @@ func HandleLaunchAsTA(args []string, stdin io.Reader, configStanzaPrefix, scheme
 		return nil, mode, err
 	}
 
+	// The stanza args are expanded like the env vars they replace once those are set.
+	for i := range stanzaArgs {
+		stanzaArgs[i] = expandEnvRefs(stanzaArgs[i])
+	}
+
+	args = append(args, stanzaArgs...)
 	return append(args, cmdArgs...), mode, nil
 }
 
+// processStanzaParams adds the environment variables set by the "splunk_" parameters of the stanzas
+// to envVars, and returns the arguments of the parameters passed as command line flags and the
+// parsed "_cmd_args" parameters.
+func processStanzaParams(stanzas []Stanza, envVars map[string]string, options launchOptions) (stanzaArgs, cmdArgs []string, err error) {
+	envVarName := func(paramName string) string {
+		if envVar, ok := options.stanzaEnvVars[paramName]; ok {
+			return envVar
+		}
+		return strings.ToUpper(paramName)
+	}
+
+	// The stanza each environment variable was set by, to report conflicting stanzas.
+	setBy := make(map[string]string)
+	setEnvVar := func(stanza, name, value string) error {
+		if prevStanza, ok := setBy[name]; ok && envVars[name] != value {
+			return fmt.Errorf("launch as TA failed: stanzas '%s' and '%s' set different values for '%s'", prevStanza, stanza, name)
+		}
+		setBy[name] = stanza
+		envVars[name] = value
+		return nil
+	}
+
+	// The distinct values of the parameters passed as command line flags, in stanza order.
+	var argParams []string
+	argValues := make(map[string][]string)
+	for _, stanza := range stanzas {
+		for _, param := range stanza.Param {
+			paramName := strings.ToLower(param.Name)
+			if !strings.HasPrefix(paramName, "splunk_") {
+				continue
+			}
+
+			// Process special parameters
+			switch {
+			// TODO: to be refactored: the caller will specify which parameters should be parsed as env var pairs instead of using a naming convention
+			case strings.HasSuffix(paramName, "_env_vars"):
+				pairs, parseErr := parseEnvVarPairs(param.Value)
+				if parseErr != nil {
+					return nil, nil, fmt.Errorf("launch as TA failed to parse env vars from parameter '%s': %w", param.Name, parseErr)
+				}
+				for _, name := range slices.Sorted(maps.Keys(pairs)) {
+					if err = setEnvVar(stanza.Name, name, pairs[name]); err != nil {
+						return nil, nil, err
+					}
+				}
+			case strings.HasSuffix(paramName, "_cmd_args"):
+				parsed, parseErr := shlex.Split(param.Value)
+				if parseErr != nil {
+					return nil, nil, fmt.Errorf("launch as TA failed to parse cmd args from parameter '%s': %w", param.Name, parseErr)
+				}
+				cmdArgs = append(cmdArgs, parsed...)
+			default:
+				if _, ok := options.stanzaArgs[paramName]; ok {
+					if _, seen := argValues[paramName]; !seen {
+						argParams = append(argParams, paramName)
+					}
+					if !slices.Contains(argValues[paramName], param.Value) {
+						argValues[paramName] = append(argValues[paramName], param.Value)
+					}
+					continue
+				}
+				if err = setEnvVar(stanza.Name, envVarName(paramName), param.Value); err != nil {
+					return nil, nil, err
+				}
+			}
+		}
+	}
+
+	for _, paramName := range argParams {
+		values := argValues[paramName]
+		if len(values) == 1 {
+			envVars[envVarName(paramName)] = values[0]
+			continue
+		}
+		for _, value := range values {
+			stanzaArgs = append(stanzaArgs, options.stanzaArgs[paramName], value)
+		}
+	}
+	return stanzaArgs, cmdArgs, nil
+}
+
 func detectTARunMode(args []string) TARunMode {
 	// SPLUNK_HOME must be defined if this is running as a modular input.
 	_, hasSplunkHome := os.LookupEnv("SPLUNK_HOME")
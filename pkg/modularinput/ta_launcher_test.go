@@ -359,6 +359,7 @@ func TestHandleLaunchAsTA_EmptyStanza(t *testing.T) {
 		EnvStanzaName:    "test-stanza",
 		EnvManagementURI: "https://localhost:8089",
 		EnvSessionKey:    "test_key",
+		EnvCheckpointDir: "/tmp/checkpoint",
 	}, envVars)
 }
 
@@ -1323,7 +1324,7 @@ func TestHandleLaunchAsTA_EnvVarsSuffix_InvalidValue(t *testing.T) {
 	assert.Contains(t, err.Error(), "launch as TA failed to parse env vars from parameter 'splunk_bad_env_vars'")
 }
 
-func TestHandleLaunchAsTA_StanzaPrefixFirstMatch(t *testing.T) {
+func TestHandleLaunchAsTA_MultipleStanzas(t *testing.T) {
 	// Save original functions and restore after test
 	originalIsParentFn := isParentProcessSplunkdFn
 	originalSetEnvFn := setEnvFn
@@ -1355,23 +1356,196 @@ func TestHandleLaunchAsTA_StanzaPrefixFirstMatch(t *testing.T) {
 	<configuration>
 		<stanza name="otel://instance1" app="test-app">
 			<param name="splunk_realm">us0</param>
+			<param name="splunk_collector_env_vars">FOO=bar</param>
+			<param name="splunk_collector_cmd_args">--feature-gates=foo</param>
 		</stanza>
-		<stanza name="otel://instance2" app="test-app">
+		<stanza name="other://instance" app="other-app">
 			<param name="splunk_realm">eu0</param>
 		</stanza>
+		<stanza name="otel://instance2" app="test-app">
+			<param name="splunk_realm">us0</param>
+			<param name="splunk_collector_env_vars">FOO=bar,BAZ=qux</param>
+			<param name="splunk_collector_cmd_args">--feature-gates=bar</param>
+		</stanza>
 	</configuration>
 </input>`
 
 	args := []string{"program"}
 	reader := strings.NewReader(xmlData)
 
-	// When multiple stanzas match the prefix, only the first one should be used
+	// All the stanzas matching the prefix are honored
 	resultArgs, _, err := HandleLaunchAsTA(args, reader, "otel://", "<scheme></scheme>", nil)
 	require.NoError(t, err, "Expected no error")
-	assert.Equal(t, args, resultArgs, "Expected args to be returned unchanged")
+	assert.Equal(t, []string{"program", "--feature-gates=foo", "--feature-gates=bar"}, resultArgs)
+
+	assert.Equal(t, "us0", envVars["SPLUNK_REALM"])
+	assert.Equal(t, "bar", envVars["FOO"])
+	assert.Equal(t, "qux", envVars["BAZ"])
+	assert.Equal(t, "/tmp/checkpoint", envVars[EnvCheckpointDir])
+	// The app and stanza name env vars refer to the first matching stanza
+	assert.Equal(t, "otel://instance1", envVars[EnvStanzaName])
+	assert.Equal(t, "test-app", envVars[EnvAppName])
+}
+
+func TestHandleLaunchAsTA_MultipleStanzasConflictingValues(t *testing.T) {
+	originalIsParentFn := isParentProcessSplunkdFn
+	originalSetEnvFn := setEnvFn
+	defer func() {
+		isParentProcessSplunkdFn = originalIsParentFn
+		setEnvFn = originalSetEnvFn
+	}()
+
+	isParentProcessSplunkdFn = func() bool { return true }
+	setEnvFn = func(_, _ string) error { return nil }
+
+	t.Setenv("SPLUNK_HOME", "/opt/splunk")
+
+	tests := []struct {
+		name        string
+		params1     string
+		params2     string
+		expectedErr string
+	}{
+		{
+			name:        "param",
+			params1:     `<param name="splunk_realm">us0</param>`,
+			params2:     `<param name="splunk_realm">eu0</param>`,
+			expectedErr: "launch as TA failed: stanzas 'otel://instance1' and 'otel://instance2' set different values for 'SPLUNK_REALM'",
+		},
+		{
+			name:        "env var pair",
+			params1:     `<param name="splunk_collector_env_vars">FOO=bar</param>`,
+			params2:     `<param name="splunk_collector_env_vars">FOO=baz</param>`,
+			expectedErr: "launch as TA failed: stanzas 'otel://instance1' and 'otel://instance2' set different values for 'FOO'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			xmlData := `<input>
+	<configuration>
+		<stanza name="otel://instance1" app="test-app">` + tt.params1 + `</stanza>
+		<stanza name="otel://instance2" app="test-app">` + tt.params2 + `</stanza>
+	</configuration>
+</input>`
+
+			resultArgs, mode, err := HandleLaunchAsTA([]string{"program"}, strings.NewReader(xmlData), "otel://", "<scheme></scheme>", nil)
+			require.EqualError(t, err, tt.expectedErr)
+			assert.Nil(t, resultArgs)
+			assert.Equal(t, ExecutionTARunMode, mode)
+		})
+	}
+}
+
+func TestHandleLaunchAsTA_WithStanzaArg(t *testing.T) {
+	originalIsParentFn := isParentProcessSplunkdFn
+	originalSetEnvFn := setEnvFn
+	defer func() {
+		isParentProcessSplunkdFn = originalIsParentFn
+		setEnvFn = originalSetEnvFn
+	}()
+
+	isParentProcessSplunkdFn = func() bool { return true }
+	envVars := make(map[string]string)
+	setEnvFn = func(key, value string) error {
+		envVars[key] = value
+		return nil
+	}
+
+	t.Setenv("SPLUNK_HOME", "/opt/splunk")
+
+	t.Run("different values", func(t *testing.T) {
+		clear(envVars)
+		xmlData := `<input>
+	<configuration>
+		<stanza name="otel://instance1" app="test-app">
+			<param name="splunk_config">$SPLUNK_HOME/etc/apps/test-app/configs/agent.yaml</param>
+			<param name="splunk_collector_cmd_args">--feature-gates=foo</param>
+		</stanza>
+		<stanza name="otel://instance2" app="test-app">
+			<param name="SPLUNK_CONFIG">/etc/otel/logs.yaml</param>
+		</stanza>
+		<stanza name="otel://instance3" app="test-app">
+			<param name="splunk_config">/etc/otel/logs.yaml</param>
+		</stanza>
+	</configuration>
+</input>`
+
+		resultArgs, _, err := HandleLaunchAsTA([]string{"program"}, strings.NewReader(xmlData), "otel://", "<scheme></scheme>", nil,
+			WithStanzaArg("splunk_config", "--config"))
+		require.NoError(t, err)
+		// One flag per distinct value in stanza order, before the cmd args
+		assert.Equal(t, []string{
+			"program",
+			"--config", "/opt/splunk/etc/apps/test-app/configs/agent.yaml",
+			"--config", "/etc/otel/logs.yaml",
+			"--feature-gates=foo",
+		}, resultArgs)
+		assert.NotContains(t, envVars, "SPLUNK_CONFIG")
+	})
+
+	t.Run("same value", func(t *testing.T) {
+		clear(envVars)
+		xmlData := `<input>
+	<configuration>
+		<stanza name="otel://instance1" app="test-app">
+			<param name="splunk_config">/etc/otel/agent.yaml</param>
+		</stanza>
+		<stanza name="otel://instance2" app="test-app">
+			<param name="splunk_config">/etc/otel/agent.yaml</param>
+		</stanza>
+	</configuration>
+</input>`
 
-	// Should use the first matching stanza
-	assert.Equal(t, "us0", envVars["SPLUNK_REALM"], "Expected SPLUNK_REALM from first matching stanza")
+		resultArgs, _, err := HandleLaunchAsTA([]string{"program"}, strings.NewReader(xmlData), "otel://", "<scheme></scheme>", nil,
+			WithStanzaArg("splunk_config", "--config"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"program"}, resultArgs)
+		assert.Equal(t, "/etc/otel/agent.yaml", envVars["SPLUNK_CONFIG"])
+	})
+}
+
+func TestHandleLaunchAsTA_WithStanzaEnvVar(t *testing.T) {
+	originalIsParentFn := isParentProcessSplunkdFn
+	originalSetEnvFn := setEnvFn
+	defer func() {
+		isParentProcessSplunkdFn = originalIsParentFn
+		setEnvFn = originalSetEnvFn
+	}()
+
+	isParentProcessSplunkdFn = func() bool { return true }
+	envVars := make(map[string]string)
+	setEnvFn = func(key, value string) error {
+		envVars[key] = value
+		return nil
+	}
+
+	t.Setenv("SPLUNK_HOME", "/opt/splunk")
+
+	xmlData := `<input>
+	<configuration>
+		<stanza name="otel://instance1" app="test-app">
+			<param name="splunk_realm">us0</param>
+			<param name="Splunk_Collector_Log_Level">info</param>
+		</stanza>
+		<stanza name="otel://instance2" app="test-app">
+			<param name="splunk_collector_log_level">debug</param>
+		</stanza>
+	</configuration>
+</input>`
+
+	_, _, err := HandleLaunchAsTA([]string{"program"}, strings.NewReader(xmlData), "otel://", "<scheme></scheme>", nil,
+		WithStanzaEnvVar("splunk_collector_log_level", "OTELCOL_LOG_LEVEL"))
+	require.EqualError(t, err, "launch as TA failed: stanzas 'otel://instance1' and 'otel://instance2' set different values for 'OTELCOL_LOG_LEVEL'")
+
+	clear(envVars)
+	xmlData = strings.Replace(xmlData, "debug", "info", 1)
+	_, _, err = HandleLaunchAsTA([]string{"program"}, strings.NewReader(xmlData), "otel://", "<scheme></scheme>", nil,
+		WithStanzaEnvVar("splunk_collector_log_level", "OTELCOL_LOG_LEVEL"))
+	require.NoError(t, err)
+	assert.Equal(t, "info", envVars["OTELCOL_LOG_LEVEL"])
+	assert.NotContains(t, envVars, "SPLUNK_COLLECTOR_LOG_LEVEL")
+	assert.Equal(t, "us0", envVars["SPLUNK_REALM"])
 }
 
 func TestHandleLaunchAsTA_CmdArgsSuffix(t *testing.T) {
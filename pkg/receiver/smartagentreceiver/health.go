@@ -0,0 +1,186 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentreceiver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componentstatus"
+	otelcolreceiver "go.opentelemetry.io/collector/receiver"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	meterName                    = "github.com/signalfx/splunk-otel-collector/pkg/receiver/smartagentreceiver"
+	datapointsSentMetricName     = "otelcol_receiver_smartagent_datapoints_sent"
+	datapointsFilteredMetricName = "otelcol_receiver_smartagent_datapoints_filtered"
+	lastSuccessfulCollectionName = "otelcol_receiver_smartagent_last_successful_collection"
+	monitorErrorsMetricName      = "otelcol_receiver_smartagent_errors"
+	receiverAttributeKey         = "receiver"
+	monitorTypeAttributeKey      = "monitor_type"
+	errorTypeAttributeKey        = "error_type"
+	unknownErrorType             = "unknown"
+)
+
+// monitorHealth reports the status of a receiver's monitor through the host and
+// publishes its internal metrics. Monitors don't return their collection errors,
+// so they are obtained from their logrus error entries by the logrusToZap hook.
+type monitorHealth struct {
+	host               component.Host
+	datapointsSent     metric.Int64Counter
+	datapointsFiltered metric.Int64Counter
+	errors             metric.Int64Counter
+	registration       metric.Registration
+	monitorType        string
+	attributes         attribute.Set
+	// unix nanoseconds of the last datapoints accepted by the next consumer
+	lastCollection atomic.Int64
+	status         componentstatus.Status
+	statusLock     sync.Mutex
+}
+
+func newMonitorHealth(params otelcolreceiver.Settings, monitorType string, host component.Host) (*monitorHealth, error) {
+	h := &monitorHealth{
+		host:        host,
+		monitorType: monitorType,
+		status:      componentstatus.StatusOK,
+		attributes: attribute.NewSet(
+			attribute.String(receiverAttributeKey, params.ID.String()),
+			attribute.String(monitorTypeAttributeKey, monitorType),
+		),
+	}
+
+	meter := params.MeterProvider.Meter(meterName)
+
+	var err error
+	if h.datapointsSent, err = meter.Int64Counter(
+		datapointsSentMetricName,
+		metric.WithDescription("Number of datapoints sent by the monitor and accepted by the next consumer"),
+		metric.WithUnit("{datapoint}"),
+	); err != nil {
+		return nil, err
+	}
+
+	if h.datapointsFiltered, err = meter.Int64Counter(
+		datapointsFilteredMetricName,
+		metric.WithDescription("Number of datapoints sent by the monitor and dropped by its filtering"),
+		metric.WithUnit("{datapoint}"),
+	); err != nil {
+		return nil, err
+	}
+
+	if h.errors, err = meter.Int64Counter(
+		monitorErrorsMetricName,
+		metric.WithDescription("Number of errors logged by the monitor by error type"),
+		metric.WithUnit("{error}"),
+	); err != nil {
+		return nil, err
+	}
+
+	lastCollection, err := meter.Int64ObservableGauge(
+		lastSuccessfulCollectionName,
+		metric.WithDescription("Unix time of the last datapoints sent by the monitor and accepted by the next consumer"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if h.registration, err = meter.RegisterCallback(func(_ context.Context, observer metric.Observer) error {
+		if last := h.lastCollection.Load(); last != 0 {
+			observer.ObserveInt64(lastCollection, time.Unix(0, last).Unix(), metric.WithAttributeSet(h.attributes))
+		}
+		return nil
+	}, lastCollection); err != nil {
+		return nil, err
+	}
+
+	return h, nil
+}
+
+// recordDatapoints records the outcome of sending datapoints to the next
+// consumer, recovering from a previously reported error on success.
+func (h *monitorHealth) recordDatapoints(ctx context.Context, sent, filtered int, err error) {
+	attrs := metric.WithAttributeSet(h.attributes)
+	if filtered > 0 {
+		h.datapointsFiltered.Add(ctx, int64(filtered), attrs)
+	}
+	if err != nil || sent == 0 {
+		return
+	}
+	h.datapointsSent.Add(ctx, int64(sent), attrs)
+	h.lastCollection.Store(time.Now().UnixNano())
+
+	h.statusLock.Lock()
+	defer h.statusLock.Unlock()
+	if h.status == componentstatus.StatusRecoverableError {
+		h.reportStatus(componentstatus.NewEvent(componentstatus.StatusOK))
+	}
+}
+
+// recordError records an error entry logged by the monitor, reporting a
+// permanent error for fatal and panic ones and a recoverable error otherwise.
+func (h *monitorHealth) recordError(entry *logrus.Entry) {
+	err, _ := entry.Data[logrus.ErrorKey].(error)
+	h.errors.Add(context.Background(), 1, metric.WithAttributeSet(h.attributes),
+		metric.WithAttributes(attribute.String(errorTypeAttributeKey, errorType(err))))
+
+	statusErr := fmt.Errorf("monitor %s: %s", h.monitorType, entry.Message)
+	if err != nil {
+		statusErr = fmt.Errorf("monitor %s: %s: %w", h.monitorType, entry.Message, err)
+	}
+
+	h.statusLock.Lock()
+	defer h.statusLock.Unlock()
+	switch {
+	case h.status == componentstatus.StatusPermanentError:
+	case entry.Level <= logrus.FatalLevel:
+		h.reportStatus(componentstatus.NewPermanentErrorEvent(statusErr))
+	default:
+		h.reportStatus(componentstatus.NewRecoverableErrorEvent(statusErr))
+	}
+}
+
+// reportStatus must be called with the statusLock held.
+func (h *monitorHealth) reportStatus(event *componentstatus.Event) {
+	h.status = event.Status()
+	componentstatus.ReportStatus(h.host, event)
+}
+
+func (h *monitorHealth) shutdown() error {
+	return h.registration.Unregister()
+}
+
+// errorType returns the type of the innermost wrapped error, which is the most
+// telling one for the monitors wrapping their client errors.
+func errorType(err error) string {
+	if err == nil {
+		return unknownErrorType
+	}
+	for {
+		unwrapped := errors.Unwrap(err)
+		if unwrapped == nil {
+			return fmt.Sprintf("%T", err)
+		}
+		err = unwrapped
+	}
+}
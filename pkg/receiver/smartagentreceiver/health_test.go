@@ -0,0 +1,202 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentreceiver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/signalfx/golib/v3/datapoint" //nolint:staticcheck // SA1019: deprecated package still in use
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componentstatus"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/signalfx-agent/pkg/core/dpfilters"
+)
+
+type statusHost struct {
+	component.Host
+	events []*componentstatus.Event
+	sync.Mutex
+}
+
+var _ componentstatus.Reporter = (*statusHost)(nil)
+
+func (h *statusHost) Report(event *componentstatus.Event) {
+	h.Lock()
+	defer h.Unlock()
+	h.events = append(h.events, event)
+}
+
+func (h *statusHost) statuses() []componentstatus.Status {
+	h.Lock()
+	defer h.Unlock()
+	var statuses []componentstatus.Status
+	for _, event := range h.events {
+		statuses = append(statuses, event.Status())
+	}
+	return statuses
+}
+
+func newTestMonitorHealth(t *testing.T, host component.Host) (*monitorHealth, *componenttest.Telemetry) {
+	tt := componenttest.NewTelemetry()
+	t.Cleanup(func() { require.NoError(t, tt.Shutdown(context.Background())) })
+	params := newReceiverCreateSettings("redis", t)
+	params.TelemetrySettings = tt.NewTelemetrySettings()
+	params.Logger = zap.NewNop()
+	health, err := newMonitorHealth(params, "collectd/redis", host)
+	require.NoError(t, err)
+	return health, tt
+}
+
+func int64DataPoints(t *testing.T, tt *componenttest.Telemetry, name string) []metricdata.DataPoint[int64] {
+	m, err := tt.GetMetric(name)
+	require.NoError(t, err)
+	switch data := m.Data.(type) {
+	case metricdata.Sum[int64]:
+		return data.DataPoints
+	case metricdata.Gauge[int64]:
+		return data.DataPoints
+	}
+	require.Failf(t, "unexpected metric data type", "%T", m.Data)
+	return nil
+}
+
+func TestMonitorHealthDatapoints(t *testing.T) {
+	health, tt := newTestMonitorHealth(t, componenttest.NewNopHost())
+	filtering := fakeMonitorFiltering()
+	filter, err := dpfilters.NewOverridable([]string{"redis.filtered"}, nil)
+	require.NoError(t, err)
+	filtering.AddDatapointExclusionFilter(filter)
+	o, err := newOutput(
+		Config{}, filtering, consumertest.NewNop(), consumertest.NewNop(),
+		consumertest.NewNop(), componenttest.NewNopHost(), newReceiverCreateSettings("redis", t),
+	)
+	require.NoError(t, err)
+	o.health = health
+
+	_, err = tt.GetMetric(lastSuccessfulCollectionName)
+	require.Error(t, err, "no collection time should be reported before the first collection")
+
+	before := time.Now().Unix()
+	o.SendDatapoints(
+		datapoint.New("redis.kept", nil, datapoint.NewIntValue(1), datapoint.Gauge, time.Now()),
+		datapoint.New("redis.kept", nil, datapoint.NewIntValue(2), datapoint.Gauge, time.Now()),
+		datapoint.New("redis.filtered", nil, datapoint.NewIntValue(3), datapoint.Gauge, time.Now()),
+	)
+
+	expectedAttributes := attribute.NewSet(
+		attribute.String(receiverAttributeKey, "smartagent/redis"),
+		attribute.String(monitorTypeAttributeKey, "collectd/redis"),
+	)
+	sent := int64DataPoints(t, tt, datapointsSentMetricName)
+	require.Len(t, sent, 1)
+	assert.Equal(t, int64(2), sent[0].Value)
+	assert.Equal(t, expectedAttributes, sent[0].Attributes)
+
+	filtered := int64DataPoints(t, tt, datapointsFilteredMetricName)
+	require.Len(t, filtered, 1)
+	assert.Equal(t, int64(1), filtered[0].Value)
+
+	last := int64DataPoints(t, tt, lastSuccessfulCollectionName)
+	require.Len(t, last, 1)
+	assert.GreaterOrEqual(t, last[0].Value, before)
+	assert.Equal(t, expectedAttributes, last[0].Attributes)
+
+	require.NoError(t, health.shutdown())
+}
+
+func TestMonitorHealthConsumerError(t *testing.T) {
+	health, tt := newTestMonitorHealth(t, componenttest.NewNopHost())
+	health.recordDatapoints(context.Background(), 5, 0, errors.New("consumer error"))
+
+	_, err := tt.GetMetric(datapointsSentMetricName)
+	require.Error(t, err)
+	_, err = tt.GetMetric(lastSuccessfulCollectionName)
+	require.Error(t, err)
+}
+
+func TestMonitorHealthErrors(t *testing.T) {
+	host := &statusHost{Host: componenttest.NewNopHost()}
+	health, tt := newTestMonitorHealth(t, host)
+
+	urlErr := &url.Error{Op: "Get", URL: "http://localhost:6379", Err: errors.New("connection refused")}
+	health.recordError(&logrus.Entry{
+		Level:   logrus.ErrorLevel,
+		Message: "Could not connect",
+		Data:    logrus.Fields{logrus.ErrorKey: fmt.Errorf("failed collecting: %w", urlErr)},
+	})
+	health.recordError(&logrus.Entry{Level: logrus.ErrorLevel, Message: "Bad response"})
+	assert.Equal(t, []componentstatus.Status{
+		componentstatus.StatusRecoverableError, componentstatus.StatusRecoverableError,
+	}, host.statuses())
+	assert.EqualError(t, host.events[0].Err(), "monitor collectd/redis: Could not connect: failed collecting: Get \"http://localhost:6379\": connection refused")
+
+	errorTypes := map[string]int64{}
+	for _, dp := range int64DataPoints(t, tt, monitorErrorsMetricName) {
+		v, ok := dp.Attributes.Value(errorTypeAttributeKey)
+		require.True(t, ok)
+		errorTypes[v.AsString()] = dp.Value
+	}
+	assert.Equal(t, map[string]int64{"*errors.errorString": 1, unknownErrorType: 1}, errorTypes)
+
+	health.recordDatapoints(context.Background(), 1, 0, nil)
+	health.recordDatapoints(context.Background(), 1, 0, nil)
+	assert.Equal(t, componentstatus.StatusOK, host.statuses()[2])
+	require.Len(t, host.statuses(), 3, "recovery should only be reported once")
+
+	health.recordError(&logrus.Entry{Level: logrus.FatalLevel, Message: "Invalid credentials"})
+	health.recordError(&logrus.Entry{Level: logrus.ErrorLevel, Message: "Could not connect"})
+	health.recordDatapoints(context.Background(), 1, 0, nil)
+	assert.Equal(t, []componentstatus.Status{
+		componentstatus.StatusRecoverableError, componentstatus.StatusRecoverableError,
+		componentstatus.StatusOK, componentstatus.StatusPermanentError,
+	}, host.statuses(), "a permanent error should be final")
+}
+
+func TestLogrusToZapRecordsMonitorErrors(t *testing.T) {
+	host := &statusHost{Host: componenttest.NewNopHost()}
+	health, tt := newTestMonitorHealth(t, host)
+
+	logger := logrus.New()
+	src := monitorLogrus{Logger: logger, monitorType: "collectd/redis", monitorID: "smartagentredis"}
+	shim := newLogrusToZap(zap.NewNop())
+	shim.redirect(src, zap.NewNop())
+	shim.registerHealth(src, health)
+
+	entry := logger.WithFields(logrus.Fields{"monitorType": "collectd/redis", "monitorID": "smartagentredis"})
+	entry.Info("Collected")
+	entry.WithError(errors.New("timeout")).Error("Could not connect")
+	require.Equal(t, []componentstatus.Status{componentstatus.StatusRecoverableError}, host.statuses())
+
+	shim.unregisterHealth(src)
+	entry.Error("Could not connect")
+	require.Len(t, host.statuses(), 1)
+	errs := int64DataPoints(t, tt, monitorErrorsMetricName)
+	require.Len(t, errs, 1)
+	assert.Equal(t, int64(1), errs[0].Value)
+}
@@ -64,9 +64,12 @@ var _ logrus.Hook = (*logrusToZap)(nil)
 
 // logrusToZap provides a logrus.Hook ~singleton that redirects logrus.Logger.Log() calls
 // to the desired registered zap.Logger routed by agent-set "monitorType" and "monitorID" field values.
+// Error entries are also recorded by the registered monitorHealth routed the same way.
 type logrusToZap struct {
 	// ~sync.Map(map[monitorLogrus]*zap.Logger)
-	loggerMap     *sync.Map
+	loggerMap *sync.Map
+	// ~sync.Map(map[monitorLogrus]*monitorHealth)
+	healthMap     *sync.Map
 	noopLogger    *logrus.Logger
 	defaultLogger *zap.Logger
 }
@@ -74,6 +77,7 @@ type logrusToZap struct {
 func newLogrusToZap(defaultLogger *zap.Logger) *logrusToZap {
 	return &logrusToZap{
 		loggerMap:     &sync.Map{},
+		healthMap:     &sync.Map{},
 		defaultLogger: defaultLogger,
 		noopLogger: &logrus.Logger{
 			Out:       io.Discard,
@@ -98,6 +102,24 @@ func (l *logrusToZap) redirect(src monitorLogrus, dst *zap.Logger) {
 	_, _ = l.loggerMap.LoadOrStore(src, dst)
 }
 
+// registerHealth registers the monitorHealth recording the src monitorLogrus error entries.
+func (l *logrusToZap) registerHealth(src monitorLogrus, health *monitorHealth) {
+	l.healthMap.Store(src, health)
+}
+
+func (l *logrusToZap) unregisterHealth(src monitorLogrus) {
+	l.healthMap.Delete(src)
+}
+
+func (l *logrusToZap) getHealth(src monitorLogrus) *monitorHealth {
+	if l.healthMap != nil {
+		if h, ok := l.healthMap.Load(src); ok {
+			return h.(*monitorHealth)
+		}
+	}
+	return nil
+}
+
 func (l *logrusToZap) getZapLogger(src monitorLogrus) *zap.Logger {
 	logger := l.defaultLogger
 	if l.loggerMap != nil {
@@ -131,11 +153,18 @@ func (l *logrusToZap) Fire(entry *logrus.Entry) error {
 		fields = append(fields, zap.Any(k, v))
 	}
 
-	zapLogger := l.getZapLogger(monitorLogrus{
+	src := monitorLogrus{
 		Logger:      entry.Logger,
 		monitorType: monitorType,
 		monitorID:   monitorID,
-	})
+	}
+	zapLogger := l.getZapLogger(src)
+
+	if entry.Level <= logrus.ErrorLevel {
+		if health := l.getHealth(src); health != nil {
+			health.recordError(entry)
+		}
+	}
 
 	sort.Slice(fields, func(i, j int) bool {
 		fI, fJ := fields[i], fields[j]
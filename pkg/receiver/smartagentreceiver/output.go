@@ -16,6 +16,7 @@ package smartagentreceiver
 
 import (
 	"context"
+	"time"
 
 	metadata "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/experimentalmetricmetadata"
 	"github.com/signalfx/golib/v3/datapoint" //nolint:staticcheck // SA1019: deprecated package still in use
@@ -23,6 +24,7 @@ import (
 	"github.com/signalfx/golib/v3/trace"     //nolint:staticcheck // SA1019: deprecated package still in use
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/pipeline"
 	otelcolreceiver "go.opentelemetry.io/collector/receiver"
@@ -51,6 +53,7 @@ type output struct {
 	reporter             *receiverhelper.ObsReport
 	translator           converter.Translator
 	monitorFiltering     *monitorFiltering
+	health               *monitorHealth
 	receiverID           component.ID
 	nextDimensionClients []metadata.MetadataExporter
 }
@@ -218,31 +221,46 @@ func (out *output) SendMetrics(metrics ...pmetric.Metric) {
 
 	ctx := out.reporter.StartMetricsOp(context.Background())
 
+	sentPoints := metricsDataPointCount(metrics)
 	metrics = out.filterMetrics(metrics)
 	pm := pmetric.NewMetrics()
 	rm := pm.ResourceMetrics().AppendEmpty()
 	sm := rm.ScopeMetrics().AppendEmpty()
-	for _, dp := range metrics {
-		for k, v := range out.extraDimensions {
-			switch dp.Type() {
-			case pmetric.MetricTypeGauge:
-				for i := 0; i < dp.Gauge().DataPoints().Len(); i++ {
-					dp.Gauge().DataPoints().At(i).Attributes().PutStr(k, v)
-				}
-			case pmetric.MetricTypeSum:
-				for i := 0; i < dp.Sum().DataPoints().Len(); i++ {
-					dp.Sum().DataPoints().At(i).Attributes().PutStr(k, v)
-				}
-			default:
-				out.logger.Error("Unsupported metric type", zap.Any("type", dp.Type()), zap.String("name", dp.Name()))
+	sm.Metrics().EnsureCapacity(len(metrics))
+	now := pcommon.NewTimestampFromTime(time.Now())
+	for _, m := range metrics {
+		switch m.Type() {
+		case pmetric.MetricTypeGauge:
+			out.decorateDataPoints(m.Gauge().DataPoints(), now)
+		case pmetric.MetricTypeSum:
+			out.decorateDataPoints(m.Sum().DataPoints(), now)
+		default:
+			if len(out.extraDimensions) > 0 {
+				out.logger.Error("Unsupported metric type", zap.Any("type", m.Type()), zap.String("name", m.Name()))
 			}
 		}
-		dp.MoveTo(sm.Metrics().AppendEmpty())
+		m.MoveTo(sm.Metrics().AppendEmpty())
 	}
 
-	numPoints := pm.MetricCount()
+	numPoints := pm.DataPointCount()
 	err := out.nextMetricsConsumer.ConsumeMetrics(context.Background(), pm)
 	out.reporter.EndMetricsOp(ctx, typeStr, numPoints, err)
+	out.recordDatapoints(ctx, numPoints, sentPoints-numPoints, err)
+}
+
+// decorateDataPoints sets the timestamp of the datapoints the monitor didn't
+// set one for and adds the extra dimensions to their attributes, taking
+// priority over the monitor's like for datapoints.
+func (out *output) decorateDataPoints(dps pmetric.NumberDataPointSlice, now pcommon.Timestamp) {
+	for i := 0; i < dps.Len(); i++ {
+		dp := dps.At(i)
+		if dp.Timestamp() == 0 {
+			dp.SetTimestamp(now)
+		}
+		for k, v := range out.extraDimensions {
+			dp.Attributes().PutStr(k, v)
+		}
+	}
 }
 
 func (out *output) SendDatapoints(datapoints ...*datapoint.Datapoint) {
@@ -252,6 +270,7 @@ func (out *output) SendDatapoints(datapoints ...*datapoint.Datapoint) {
 
 	ctx := out.reporter.StartMetricsOp(context.Background())
 
+	sentPoints := len(datapoints)
 	datapoints = out.filterDatapoints(datapoints)
 	for _, dp := range datapoints {
 		// out's extraDimensions take priority over datapoint's
@@ -266,6 +285,15 @@ func (out *output) SendDatapoints(datapoints ...*datapoint.Datapoint) {
 	numPoints := metrics.DataPointCount()
 	err = out.nextMetricsConsumer.ConsumeMetrics(context.Background(), metrics)
 	out.reporter.EndMetricsOp(ctx, typeStr, numPoints, err)
+	out.recordDatapoints(ctx, numPoints, sentPoints-len(datapoints), err)
+}
+
+// recordDatapoints records the sent and filtered datapoints in the monitor
+// telemetry, if any.
+func (out *output) recordDatapoints(ctx context.Context, sent, filtered int, err error) {
+	if out.health != nil {
+		out.health.recordDatapoints(ctx, sent, filtered, err)
+	}
 }
 
 func (out *output) SendEvent(event *event.Event) {
@@ -347,6 +375,25 @@ func (out *output) filterMetrics(metrics []pmetric.Metric) []pmetric.Metric {
 	return filteredMetrics
 }
 
+func metricsDataPointCount(metrics []pmetric.Metric) int {
+	var count int
+	for _, m := range metrics {
+		switch m.Type() {
+		case pmetric.MetricTypeGauge:
+			count += m.Gauge().DataPoints().Len()
+		case pmetric.MetricTypeSum:
+			count += m.Sum().DataPoints().Len()
+		case pmetric.MetricTypeHistogram:
+			count += m.Histogram().DataPoints().Len()
+		case pmetric.MetricTypeExponentialHistogram:
+			count += m.ExponentialHistogram().DataPoints().Len()
+		case pmetric.MetricTypeSummary:
+			count += m.Summary().DataPoints().Len()
+		}
+	}
+	return count
+}
+
 func (out *output) filterDatapoints(datapoints []*datapoint.Datapoint) []*datapoint.Datapoint {
 	if out.monitorFiltering.filterSet == nil {
 		return datapoints
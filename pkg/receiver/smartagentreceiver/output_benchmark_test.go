@@ -0,0 +1,167 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentreceiver
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/signalfx/golib/v3/datapoint" //nolint:staticcheck // SA1019: deprecated package still in use
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// The number of series per metric name a Prometheus based monitor scrape is
+// benchmarked with, and the labels of each series.
+var (
+	benchmarkSeriesCounts = []int{10, 100, 1000}
+	benchmarkMetricNames  = []string{"http_requests_total", "process_cpu_seconds_total", "go_goroutines", "up"}
+)
+
+const benchmarkLabelCount = 5
+
+func newBenchmarkOutput(b *testing.B) *output {
+	o, err := newOutput(
+		Config{}, fakeMonitorFiltering(), consumertest.NewNop(), consumertest.NewNop(),
+		consumertest.NewNop(), componenttest.NewNopHost(), newReceiverCreateSettings("", b),
+	)
+	require.NoError(b, err)
+	o.AddExtraDimension("kubernetes_cluster", "benchmark")
+	return o
+}
+
+// benchmarkDatapoints mirrors what the Prometheus based monitors sent through
+// SendDatapoints: a datapoint with its own dimensions map per series.
+func benchmarkDatapoints(series int, now time.Time) []*datapoint.Datapoint {
+	dps := make([]*datapoint.Datapoint, 0, series*len(benchmarkMetricNames))
+	for _, name := range benchmarkMetricNames {
+		for i := 0; i < series; i++ {
+			dims := make(map[string]string, benchmarkLabelCount)
+			for l := 0; l < benchmarkLabelCount; l++ {
+				dims[fmt.Sprintf("label_%d", l)] = fmt.Sprintf("value_%d_%d", l, i)
+			}
+			dps = append(dps, datapoint.New(name, dims, datapoint.NewFloatValue(float64(i)), datapoint.Counter, now))
+		}
+	}
+	return dps
+}
+
+// benchmarkMetrics mirrors what the Prometheus based monitors send through
+// SendMetrics: a metric per name with a datapoint per series.
+func benchmarkMetrics(series int, now time.Time) []pmetric.Metric {
+	ts := pcommon.NewTimestampFromTime(now)
+	metrics := make([]pmetric.Metric, 0, len(benchmarkMetricNames))
+	for _, name := range benchmarkMetricNames {
+		m := pmetric.NewMetric()
+		m.SetName(name)
+		sum := m.SetEmptySum()
+		sum.SetIsMonotonic(true)
+		sum.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+		dps := sum.DataPoints()
+		dps.EnsureCapacity(series)
+		for i := 0; i < series; i++ {
+			dp := dps.AppendEmpty()
+			dp.SetTimestamp(ts)
+			dp.SetDoubleValue(float64(i))
+			attrs := dp.Attributes()
+			attrs.EnsureCapacity(benchmarkLabelCount)
+			for l := 0; l < benchmarkLabelCount; l++ {
+				attrs.PutStr(fmt.Sprintf("label_%d", l), fmt.Sprintf("value_%d_%d", l, i))
+			}
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics
+}
+
+func BenchmarkSendDatapoints(b *testing.B) {
+	for _, series := range benchmarkSeriesCounts {
+		b.Run(fmt.Sprintf("series=%d", series), func(b *testing.B) {
+			o := newBenchmarkOutput(b)
+			now := time.Now()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				o.SendDatapoints(benchmarkDatapoints(series, now)...)
+			}
+		})
+	}
+}
+
+func BenchmarkSendMetrics(b *testing.B) {
+	for _, series := range benchmarkSeriesCounts {
+		b.Run(fmt.Sprintf("series=%d", series), func(b *testing.B) {
+			o := newBenchmarkOutput(b)
+			now := time.Now()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				o.SendMetrics(benchmarkMetrics(series, now)...)
+			}
+		})
+	}
+}
+
+// TestSendDatapointsAndMetricsEquivalent makes sure both benchmarked paths
+// produce the same metrics.
+func TestSendDatapointsAndMetricsEquivalent(t *testing.T) {
+	now := time.Now()
+	datapointsSink := new(consumertest.MetricsSink)
+	metricsSink := new(consumertest.MetricsSink)
+	for _, tc := range []struct {
+		sink *consumertest.MetricsSink
+		send func(o *output)
+	}{
+		{datapointsSink, func(o *output) { o.SendDatapoints(benchmarkDatapoints(3, now)...) }},
+		{metricsSink, func(o *output) { o.SendMetrics(benchmarkMetrics(3, now)...) }},
+	} {
+		o, err := newOutput(
+			Config{}, fakeMonitorFiltering(), tc.sink, consumertest.NewNop(),
+			consumertest.NewNop(), componenttest.NewNopHost(), newReceiverCreateSettings("", t),
+		)
+		require.NoError(t, err)
+		tc.send(o)
+		require.Len(t, tc.sink.AllMetrics(), 1)
+	}
+
+	fromDatapoints := datapointsSink.AllMetrics()[0]
+	fromMetrics := metricsSink.AllMetrics()[0]
+	require.Equal(t, fromDatapoints.DataPointCount(), fromMetrics.DataPointCount())
+	byName := map[string]pmetric.NumberDataPointSlice{}
+	metrics := fromMetrics.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	for i := 0; i < metrics.Len(); i++ {
+		byName[metrics.At(i).Name()] = metrics.At(i).Sum().DataPoints()
+	}
+	translated := fromDatapoints.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	seen := map[string]int{}
+	for i := 0; i < translated.Len(); i++ {
+		m := translated.At(i)
+		require.Equal(t, pmetric.MetricTypeSum, m.Type())
+		dps, ok := byName[m.Name()]
+		require.True(t, ok, m.Name())
+		for j := 0; j < m.Sum().DataPoints().Len(); j++ {
+			expected := dps.At(seen[m.Name()])
+			actual := m.Sum().DataPoints().At(j)
+			require.Equal(t, expected.Timestamp(), actual.Timestamp())
+			require.Equal(t, expected.DoubleValue(), actual.DoubleValue())
+			require.Equal(t, expected.Attributes().AsRaw(), actual.Attributes().AsRaw())
+			seen[m.Name()]++
+		}
+	}
+}
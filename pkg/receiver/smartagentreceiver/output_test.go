@@ -30,6 +30,7 @@ import (
 	"go.opentelemetry.io/collector/consumer/consumertest"
 	otelcolexporter "go.opentelemetry.io/collector/exporter"
 	"go.opentelemetry.io/collector/exporter/exportertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/plog"
 	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/pipeline"
@@ -253,6 +254,42 @@ func TestSendEvent(t *testing.T) {
 	assert.Equal(t, "property_value", val.Str())
 }
 
+func TestSendMetrics(t *testing.T) {
+	sink := new(consumertest.MetricsSink)
+	output, err := newOutput(
+		Config{}, fakeMonitorFiltering(), sink, consumertest.NewNop(),
+		consumertest.NewNop(), componenttest.NewNopHost(), newReceiverCreateSettings("", t),
+	)
+	require.NoError(t, err)
+	output.AddExtraDimension("env", "prod")
+
+	gauge := pmetric.NewMetric()
+	gauge.SetName("my.gauge")
+	dps := gauge.SetEmptyGauge().DataPoints()
+	dps.AppendEmpty().SetDoubleValue(1)
+	timestamped := dps.AppendEmpty()
+	timestamped.SetDoubleValue(2)
+	timestamped.SetTimestamp(1000)
+	timestamped.Attributes().PutStr("env", "dev")
+	sum := pmetric.NewMetric()
+	sum.SetName("my.sum")
+	sum.SetEmptySum().DataPoints().AppendEmpty().SetIntValue(3)
+
+	output.SendMetrics(gauge, sum)
+	received := sink.AllMetrics()
+	require.Len(t, received, 1)
+	require.Equal(t, 3, received[0].DataPointCount())
+	metrics := received[0].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 2, metrics.Len())
+
+	gaugeDps := metrics.At(0).Gauge().DataPoints()
+	assert.NotZero(t, gaugeDps.At(0).Timestamp())
+	assert.Equal(t, map[string]any{"env": "prod"}, gaugeDps.At(0).Attributes().AsRaw())
+	assert.Equal(t, pcommon.Timestamp(1000), gaugeDps.At(1).Timestamp())
+	assert.Equal(t, map[string]any{"env": "prod"}, gaugeDps.At(1).Attributes().AsRaw())
+	assert.Equal(t, map[string]any{"env": "prod"}, metrics.At(1).Sum().DataPoints().At(0).Attributes().AsRaw())
+}
+
 func TestDimensionClientDefaultsToSFxExporter(t *testing.T) {
 	mmc := mockMetadataClient{id: component.MustNewID("signalfx")}
 	output, err := newOutput(
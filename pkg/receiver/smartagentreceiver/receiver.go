@@ -44,6 +44,7 @@ const (
 
 type receiver struct {
 	monitor             any
+	health              *monitorHealth
 	nextMetricsConsumer consumer.Metrics
 	nextLogsConsumer    consumer.Logs
 	nextTracesConsumer  consumer.Traces
@@ -107,16 +108,18 @@ func (r *receiver) Start(_ context.Context, host component.Host) error {
 	})
 
 	// source logger set to the logrus StandardLogger because it is assumed that the monitor's is derived from it
-	logrusShim.redirect(monitorLogrus{
-		Logger:      logrus.StandardLogger(),
-		monitorType: r.config.monitorConfig.MonitorConfigCore().Type,
-		monitorID:   monitorID,
-	}, r.logger)
+	monitorLogger := r.monitorLogrus()
+	logrusShim.redirect(monitorLogger, r.logger)
+
+	var err error
+	if r.health, err = newMonitorHealth(r.params, monitorType, host); err != nil {
+		return fmt.Errorf("failed creating monitor %q telemetry: %w", monitorType, err)
+	}
+	logrusShim.registerHealth(monitorLogger, r.health)
 
 	if !r.config.acceptsEndpoints {
 		r.logger.Debug("This Smart Agent monitor does not use Host/Port config fields. If either are set, they will be ignored.", zap.String("monitor_type", monitorType))
 	}
-	var err error
 	r.monitor, err = r.createMonitor(monitorType, host)
 	if err != nil {
 		return fmt.Errorf("failed creating monitor %q: %w", monitorType, err)
@@ -128,6 +131,7 @@ func (r *receiver) Start(_ context.Context, host component.Host) error {
 }
 
 func (r *receiver) Shutdown(context.Context) error {
+	r.shutdownHealth()
 	if r.monitor == nil {
 		return nil
 	}
@@ -142,6 +146,28 @@ func (r *receiver) Shutdown(context.Context) error {
 	return nil
 }
 
+func (r *receiver) shutdownHealth() {
+	if r.health == nil {
+		return
+	}
+	logrusShim.unregisterHealth(r.monitorLogrus())
+	if err := r.health.shutdown(); err != nil {
+		r.logger.Warn("failed unregistering monitor telemetry", zap.Error(err))
+	}
+	r.health = nil
+}
+
+// monitorLogrus returns the monitorLogrus of the receiver's monitor. It must be
+// called after the monitor ID has been set by Start.
+func (r *receiver) monitorLogrus() monitorLogrus {
+	configCore := r.config.monitorConfig.MonitorConfigCore()
+	return monitorLogrus{
+		Logger:      logrus.StandardLogger(),
+		monitorType: configCore.Type,
+		monitorID:   string(configCore.MonitorID),
+	}
+}
+
 func (r *receiver) createMonitor(monitorType string, host component.Host) (monitor any, err error) {
 	// retrieve registered MonitorFactory from agent's registration store
 	monitorFactory, ok := monitors.MonitorFactories[monitorType]
@@ -170,6 +196,7 @@ func (r *receiver) createMonitor(monitorType string, host component.Host) (monit
 	if err != nil {
 		return nil, err
 	}
+	output.health = r.health
 	set, err := setStructFieldWithExplicitType(
 		monitor, "Output", output,
 		reflect.TypeOf((*types.Output)(nil)).Elem(),
@@ -55,7 +55,7 @@ func cleanUp() func() {
 	}
 }
 
-func newReceiverCreateSettings(name string, t *testing.T) otelcolreceiver.Settings {
+func newReceiverCreateSettings(name string, _ testing.TB) otelcolreceiver.Settings {
 	return otelcolreceiver.Settings{
 		ID: component.MustNewIDWithName("smartagent", name),
 		TelemetrySettings: component.TelemetrySettings{